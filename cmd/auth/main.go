@@ -92,7 +92,7 @@ func main() {
 	}()
 	logger.Info("init kafka producer success")
 
-	authApp := auth.NewApp(
+	authApp, err := auth.NewApp(
 		ctx,
 		cfg,
 		logger,
@@ -101,6 +101,10 @@ func main() {
 		kafkaProducer,
 		tracer,
 	)
+	if err != nil {
+		logger.Error("init app failed", slog.String("error", err.Error()))
+		return
+	}
 	logger.Info("init app success")
 
 	go func() {
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rozhnof/auth-service/internal/app"
+	"github.com/rozhnof/auth-service/internal/app/auth"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/postgres"
+	pgrepo "github.com/rozhnof/auth-service/internal/infrastructure/repository"
+	"github.com/rozhnof/auth-service/internal/pkg/config"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	EnvConfigPath = "CONFIG_PATH"
+)
+
+const usage = `usage: authctl <command> [flags]
+
+commands:
+  client create    register an OAuth client
+  role assign      assign a role to a user, e.g. the first admin
+`
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] + " " + os.Args[2] {
+	case "client create":
+		if err := createClient(os.Args[3:]); err != nil {
+			log.Fatal(err)
+		}
+	case "role assign":
+		if err := assignRole(os.Args[3:]); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func createClient(args []string) error {
+	var (
+		name         string
+		redirectURIs stringList
+		scopes       stringList
+		grantTypes   stringList
+		public       bool
+	)
+
+	fs := flag.NewFlagSet("client create", flag.ExitOnError)
+	fs.StringVar(&name, "name", "", "client name")
+	fs.Var(&redirectURIs, "redirect-uri", "allowed redirect uri, can be repeated, required for authorization_code")
+	fs.Var(&scopes, "scope", "allowed scope, can be repeated")
+	fs.Var(&grantTypes, "grant-type", "allowed grant type, can be repeated (default authorization_code and refresh_token)")
+	fs.BoolVar(&public, "public", false, "create a public client without a secret")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if len(grantTypes) == 0 {
+		grantTypes = stringList{services.GrantTypeAuthorizationCode, services.GrantTypeRefreshToken}
+	}
+
+	if name == "" || (slices.Contains(grantTypes, services.GrantTypeAuthorizationCode) && len(redirectURIs) == 0) {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if public && slices.Contains(grantTypes, services.GrantTypeClientCredentials) {
+		return errors.New("client credentials grant requires a confidential client")
+	}
+
+	ctx := context.Background()
+
+	postgresDatabase, err := openDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer postgresDatabase.Close()
+
+	var (
+		logger     = slog.New(slog.NewTextHandler(os.Stderr, nil))
+		tracer     = noop.NewTracerProvider().Tracer("")
+		txManager  = trm.NewTransactionManager(postgresDatabase.Pool)
+		clientRepo = pgrepo.NewOAuthClientRepository(txManager, logger, tracer)
+	)
+
+	client, secret, err := entities.NewOAuthClient(name, redirectURIs, vobjects.Scopes(scopes), grantTypes, !public)
+	if err != nil {
+		return err
+	}
+
+	if err := clientRepo.Create(ctx, client); err != nil {
+		return err
+	}
+
+	fmt.Printf("client_id: %s\n", client.ID())
+	if secret != "" {
+		fmt.Printf("client_secret: %s\n", secret)
+	}
+
+	return nil
+}
+
+func assignRole(args []string) error {
+	var (
+		email string
+		role  string
+	)
+
+	fs := flag.NewFlagSet("role assign", flag.ExitOnError)
+	fs.StringVar(&email, "email", "", "user email")
+	fs.StringVar(&role, "role", entities.RoleAdmin, "role name")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if email == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+
+	postgresDatabase, err := openDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer postgresDatabase.Close()
+
+	var (
+		logger         = slog.New(slog.NewTextHandler(os.Stderr, nil))
+		tracer         = noop.NewTracerProvider().Tracer("")
+		txManager      = trm.NewTransactionManager(postgresDatabase.Pool)
+		userRepository = pgrepo.NewUserRepository(txManager, logger, tracer)
+		roleRepository = pgrepo.NewRoleRepository(txManager, logger, tracer)
+		roleService    = services.NewRoleService(roleRepository, userRepository, txManager, logger, tracer)
+	)
+
+	user, err := userRepository.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if err := roleService.AssignRole(ctx, user.ID(), role); err != nil {
+		return err
+	}
+
+	fmt.Printf("role %s assigned to %s\n", role, email)
+
+	return nil
+}
+
+func openDatabase(ctx context.Context) (postgres.Database, error) {
+	cfg, err := config.NewConfig[auth.Config](os.Getenv(EnvConfigPath))
+	if err != nil {
+		return postgres.Database{}, err
+	}
+
+	return app.NewPostgresDatabase(ctx, cfg.Postgres)
+}
@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"github.com/rozhnof/auth-service/internal/pkg/config"
+)
+
+func NewAPIRegistry(cfg config.Tokens) *services.APIRegistry {
+	apis := make([]services.API, 0, len(cfg.APIs))
+	for _, api := range cfg.APIs {
+		apis = append(apis, services.API{
+			Audience: api.Audience,
+			Scopes:   api.Scopes,
+		})
+	}
+
+	return services.NewAPIRegistry(cfg.Issuer, apis)
+}
@@ -7,21 +7,22 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rozhnof/auth-service/internal/application/services"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/infrastructure/cache"
 	"github.com/rozhnof/auth-service/internal/infrastructure/database/postgres"
 	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
 	"github.com/rozhnof/auth-service/internal/infrastructure/kafka"
+	"github.com/rozhnof/auth-service/internal/infrastructure/policy"
 	pgrepo "github.com/rozhnof/auth-service/internal/infrastructure/repository"
 	"github.com/rozhnof/auth-service/internal/infrastructure/secrets"
 	"github.com/rozhnof/auth-service/internal/pkg/config"
 	"github.com/rozhnof/auth-service/internal/pkg/outbox"
 	"github.com/rozhnof/auth-service/internal/pkg/server"
-	"github.com/rozhnof/auth-service/internal/presentation/clients"
+	"github.com/rozhnof/auth-service/internal/pkg/webauthn"
 	"github.com/rozhnof/auth-service/internal/presentation/handlers"
 	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.opentelemetry.io/otel/trace"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
 const (
@@ -29,25 +30,51 @@ const (
 )
 
 const (
-	loginsTopic    = "logins"
-	registersTopic = "registers"
+	loginsTopic        = "logins"
+	registersTopic     = "registers"
+	statusesTopic      = "user_statuses"
+	deletionsTopic     = "user_deleted"
+	exportsTopic       = "data_exports"
+	emailChangeTopic   = "email_changes"
+	emailChangedTopic  = "email_changed"
+	recoveryCodesTopic = "mfa_recovery_codes_used"
+	magicLinksTopic    = "magic_links"
+	emailCodesTopic    = "email_codes"
+	referralsTopic     = "referral_registered"
+	invitationsTopic   = "invitations"
 )
 
 type Config struct {
-	Mode     string         `yaml:"mode"    env-required:"true"`
-	Server   config.Server  `yaml:"server"  env-required:"true"`
-	Logger   config.Logger  `yaml:"logging" env-required:"true"`
-	Tokens   config.Tokens  `yaml:"tokens"  env-required:"true"`
-	Tracing  config.Tracing `yaml:"tracing" env-required:"true"`
-	Kafka    config.Kafka   `yaml:"kafka"   env-required:"true"`
-	OAuth    config.OAuth   `yaml:"oauth"   env-required:"true"`
-	Postgres config.Postgres
-	Redis    config.Redis
+	Mode            string                 `yaml:"mode"         env-required:"true"`
+	Server          config.Server          `yaml:"server"       env-required:"true"`
+	Logger          config.Logger          `yaml:"logging"      env-required:"true"`
+	Tokens          config.Tokens          `yaml:"tokens"       env-required:"true"`
+	Tracing         config.Tracing         `yaml:"tracing"      env-required:"true"`
+	Kafka           config.Kafka           `yaml:"kafka"        env-required:"true"`
+	OAuth           config.OAuth           `yaml:"oauth"        env-required:"true"`
+	Session         config.Session         `yaml:"session"`
+	OAuthServer     config.OAuthServer     `yaml:"oauth_server"`
+	EmailPolicy     config.EmailPolicy     `yaml:"email_policy"`
+	AccountDeletion config.AccountDeletion `yaml:"account_deletion"`
+	DataExport      config.DataExport      `yaml:"data_export"`
+	EmailThrottle   config.EmailThrottle   `yaml:"email_throttle"`
+	MagicLink       config.MagicLink       `yaml:"magic_link"`
+	EmailCode       config.EmailCode       `yaml:"email_code"`
+	MFA             config.MFA             `yaml:"mfa"`
+	WebAuthn        config.WebAuthn        `yaml:"webauthn"`
+	Cache           config.Cache           `yaml:"cache"`
+	Registration    config.Registration    `yaml:"registration"`
+	Postgres        config.Postgres
+	Redis           config.Redis
 }
 
 type App struct {
-	logger     *slog.Logger
-	httpServer *server.HTTPServer
+	logger            *slog.Logger
+	httpServer        *server.HTTPServer
+	emailPolicy       *policy.EmailDomainPolicy
+	accountService    *services.AccountService
+	dataExportService *services.DataExportService
+	cfg               *Config
 }
 
 func NewApp(
@@ -58,15 +85,45 @@ func NewApp(
 	redisDatabase redis.Database,
 	kafkaProducer kafka.Producer,
 	tracer trace.Tracer,
-) *App {
+) (*App, error) {
 	var (
 		txManager     = trm.NewTransactionManager(postgresDatabase.Pool)
 		secretManager = secrets.NewEnvSecretManager()
 	)
 
 	var (
-		userRepository   = pgrepo.NewUserRepository(txManager, logger, tracer)
-		outboxRepository = pgrepo.NewOutboxRepository(txManager, logger, tracer)
+		userRepository         = pgrepo.NewUserRepository(txManager, logger, tracer)
+		userIdentityRepository = pgrepo.NewUserIdentityRepository(txManager, logger, tracer)
+		roleRepository         = pgrepo.NewRoleRepository(txManager, logger, tracer)
+		auditLogRepository     = pgrepo.NewAdminAuditLogRepository(txManager, logger, tracer)
+		dataExportRepository   = pgrepo.NewDataExportRepository(txManager, logger, tracer)
+		emailChangeRepository  = pgrepo.NewEmailChangeRepository(txManager, logger, tracer)
+		userTOTPRepository     = pgrepo.NewUserTOTPRepository(txManager, logger, tracer)
+		recoveryCodeRepository = pgrepo.NewMFARecoveryCodeRepository(txManager, logger, tracer)
+		referralRepository     = pgrepo.NewReferralRepository(txManager, logger, tracer)
+		invitationRepository   = pgrepo.NewInvitationRepository(txManager, logger, tracer)
+		outboxRepository       = pgrepo.NewOutboxRepository(txManager, logger, tracer)
+
+		webauthnCredentialRepository = pgrepo.NewWebAuthnCredentialRepository(txManager, logger, tracer)
+
+		oauthClientRepository       = pgrepo.NewOAuthClientRepository(txManager, logger, tracer)
+		oauthConsentRepository      = pgrepo.NewOAuthConsentRepository(txManager, logger, tracer)
+		oauthRefreshTokenRepository = pgrepo.NewOAuthRefreshTokenRepository(txManager, logger, tracer)
+	)
+
+	var (
+		oauthStateRepository   = cache.NewOAuthStateRepository(redisDatabase, logger, tracer)
+		loginCodeRepository    = cache.NewLoginCodeRepository(redisDatabase, logger, tracer)
+		mfaChallengeRepository = cache.NewMFAChallengeRepository(redisDatabase, logger, tracer)
+
+		webauthnSessionRepository = cache.NewWebAuthnSessionRepository(redisDatabase, logger, tracer)
+		magicLinkRepository       = cache.NewMagicLinkRepository(redisDatabase, logger, tracer)
+		emailThrottleRepository   = cache.NewEmailThrottleRepository(redisDatabase, logger, tracer)
+		emailCodeRepository       = cache.NewEmailCodeRepository(redisDatabase, logger, tracer)
+		referralCodeRepository    = cache.NewReferralCodeRepository(redisDatabase, logger, tracer)
+
+		authorizationRequestRepository = cache.NewAuthorizationRequestRepository(redisDatabase, logger, tracer)
+		authorizationCodeRepository    = cache.NewAuthorizationCodeRepository(redisDatabase, logger, tracer)
 	)
 
 	// var (
@@ -75,41 +132,378 @@ func NewApp(
 	// )
 
 	var (
-		loginsOutboxSender    = outbox.NewMessageSender(outboxRepository, loginsTopic)
-		registersOutboxSender = outbox.NewMessageSender(outboxRepository, registersTopic)
+		loginsOutboxSender        = outbox.NewMessageSender(outboxRepository, loginsTopic)
+		registersOutboxSender     = outbox.NewMessageSender(outboxRepository, registersTopic)
+		statusesOutboxSender      = outbox.NewMessageSender(outboxRepository, statusesTopic)
+		deletionsOutboxSender     = outbox.NewMessageSender(outboxRepository, deletionsTopic)
+		exportsOutboxSender       = outbox.NewMessageSender(outboxRepository, exportsTopic)
+		emailChangeOutboxSender   = outbox.NewMessageSender(outboxRepository, emailChangeTopic)
+		emailChangedOutboxSender  = outbox.NewMessageSender(outboxRepository, emailChangedTopic)
+		recoveryCodesOutboxSender = outbox.NewMessageSender(outboxRepository, recoveryCodesTopic)
+		magicLinksOutboxSender    = outbox.NewMessageSender(outboxRepository, magicLinksTopic)
+		emailCodesOutboxSender    = outbox.NewMessageSender(outboxRepository, emailCodesTopic)
+		referralsOutboxSender     = outbox.NewMessageSender(outboxRepository, referralsTopic)
+		invitationsOutboxSender   = outbox.NewMessageSender(outboxRepository, invitationsTopic)
+	)
+
+	emailPolicyConfig := policy.EmailDomainPolicyConfig{
+		AllowListPath: cfg.EmailPolicy.AllowListPath,
+		DenyListPath:  cfg.EmailPolicy.DenyListPath,
+		AllowedOnly:   cfg.EmailPolicy.AllowedOnly,
+	}
+
+	emailPolicy, err := policy.NewEmailDomainPolicy(emailPolicyConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	apiRegistry := NewAPIRegistry(cfg.Tokens)
+
+	secretCipher, err := secrets.NewAESCipher(secretManager.EncryptionKey())
+	if err != nil {
+		return nil, err
+	}
+
+	emailThrottleConfig := services.EmailThrottleConfig{
+		Limit:  cfg.EmailThrottle.Limit,
+		Window: cfg.EmailThrottle.Window,
+	}
+
+	emailThrottle := services.NewEmailThrottle(emailThrottleRepository, emailThrottleConfig)
+
+	var (
+		magicLinkServiceConfig = services.MagicLinkServiceConfig{
+			Issuer: cfg.Tokens.Issuer,
+			TTL:    cfg.MagicLink.TTL,
+		}
+
+		magicLinkService = services.NewMagicLinkService(
+			magicLinkRepository,
+			emailPolicy,
+			emailThrottle,
+			magicLinksOutboxSender,
+			logger,
+			tracer,
+			magicLinkServiceConfig,
+		)
+	)
+
+	var (
+		emailCodeServiceConfig = services.EmailCodeServiceConfig{
+			Length:      cfg.EmailCode.Length,
+			TTL:         cfg.EmailCode.TTL,
+			MaxAttempts: cfg.EmailCode.MaxAttempts,
+		}
+
+		emailCodeService = services.NewEmailCodeService(
+			emailCodeRepository,
+			emailPolicy,
+			emailThrottle,
+			emailCodesOutboxSender,
+			logger,
+			tracer,
+			emailCodeServiceConfig,
+		)
+	)
+
+	var (
+		referralServiceConfig = services.ReferralServiceConfig{
+			CodeTTL: cfg.Cache.ReferralCodeTTL,
+		}
+
+		referralService = services.NewReferralService(
+			referralCodeRepository,
+			referralRepository,
+			referralsOutboxSender,
+			logger,
+			tracer,
+			referralServiceConfig,
+		)
+	)
+
+	invitationServiceConfig, err := NewInvitationServiceConfig(cfg.Registration)
+	if err != nil {
+		return nil, err
+	}
+
+	invitationService := services.NewInvitationService(
+		invitationRepository,
+		userRepository,
+		roleRepository,
+		txManager,
+		emailPolicy,
+		emailThrottle,
+		invitationsOutboxSender,
+		logger,
+		tracer,
+		invitationServiceConfig,
+	)
+
+	var (
+		relyingPartyConfig = webauthn.Config{
+			RPID:    cfg.WebAuthn.RPID,
+			RPName:  cfg.WebAuthn.RPName,
+			Origins: cfg.WebAuthn.Origins,
+			Timeout: cfg.WebAuthn.Timeout,
+		}
+
+		webauthnServiceConfig = services.WebAuthnServiceConfig{
+			SessionTTL: cfg.WebAuthn.SessionTTL,
+		}
+
+		webauthnService = services.NewWebAuthnService(
+			userRepository,
+			webauthnCredentialRepository,
+			webauthnSessionRepository,
+			webauthn.NewRelyingParty(relyingPartyConfig),
+			domain.SystemClock{},
+			logger,
+			tracer,
+			webauthnServiceConfig,
+		)
+	)
+
+	var (
+		mfaServiceConfig = services.MFAServiceConfig{
+			Issuer:            cfg.MFA.Issuer,
+			ChallengeTTL:      cfg.MFA.ChallengeTTL,
+			MaxAttempts:       cfg.MFA.MaxAttempts,
+			RecoveryCodeCount: cfg.MFA.RecoveryCodeCount,
+		}
+
+		mfaService = services.NewMFAService(
+			userRepository,
+			userTOTPRepository,
+			recoveryCodeRepository,
+			mfaChallengeRepository,
+			webauthnService,
+			txManager,
+			secretCipher,
+			recoveryCodesOutboxSender,
+			domain.SystemClock{},
+			logger,
+			tracer,
+			mfaServiceConfig,
+		)
 	)
 
 	var (
 		authServiceConfig = services.AuthServiceConfig{
-			AccessTokenTTL:  cfg.Tokens.AccessTokenTTL,
-			RefreshTokenTTL: cfg.Tokens.RefreshTokenTTL,
+			Issuer:                cfg.Tokens.Issuer,
+			AccessTokenTTL:        cfg.Tokens.AccessTokenTTL,
+			RefreshTokenTTL:       cfg.Tokens.RefreshTokenTTL,
+			AutoLinkVerifiedEmail: cfg.OAuth.AutoLinkVerifiedEmail,
 		}
 
 		authService = services.NewAuthService(
 			userRepository,
+			userIdentityRepository,
+			roleRepository,
+			oauthRefreshTokenRepository,
 			txManager,
 			secretManager,
 			loginsOutboxSender,
-			registersOutboxSender,
+			apiRegistry,
+			mfaService,
+			webauthnService,
 			logger,
 			tracer,
 			authServiceConfig,
 		)
+		registrationService = services.NewRegistrationService(
+			userRepository,
+			userIdentityRepository,
+			txManager,
+			registersOutboxSender,
+			emailPolicy,
+			emailThrottle,
+			emailCodeService,
+			referralService,
+			invitationService,
+			logger,
+			tracer,
+		)
+		roleService  = services.NewRoleService(roleRepository, userRepository, txManager, logger, tracer)
+		adminService = services.NewAdminService(userRepository, oauthRefreshTokenRepository, auditLogRepository, txManager, statusesOutboxSender, logger, tracer)
+	)
+
+	var (
+		accountServiceConfig = services.AccountServiceConfig{
+			DeletionGracePeriod: cfg.AccountDeletion.GracePeriod,
+			PurgeBatchSize:      cfg.AccountDeletion.PurgeBatchSize,
+		}
+
+		accountService = services.NewAccountService(
+			userRepository,
+			oauthRefreshTokenRepository,
+			txManager,
+			deletionsOutboxSender,
+			logger,
+			tracer,
+			accountServiceConfig,
+		)
+	)
+
+	var (
+		dataExportServiceConfig = services.DataExportServiceConfig{
+			ExportTTL: cfg.DataExport.TTL,
+			BatchSize: cfg.DataExport.GenerateBatchSize,
+		}
+
+		dataExportService = services.NewDataExportService(
+			dataExportRepository,
+			userRepository,
+			userIdentityRepository,
+			roleRepository,
+			oauthConsentRepository,
+			oauthRefreshTokenRepository,
+			auditLogRepository,
+			txManager,
+			exportsOutboxSender,
+			logger,
+			tracer,
+			dataExportServiceConfig,
+		)
+	)
+
+	var (
+		emailChangeServiceConfig = services.EmailChangeServiceConfig{
+			Issuer: cfg.Tokens.Issuer,
+		}
+
+		emailChangeService = services.NewEmailChangeService(
+			userRepository,
+			emailChangeRepository,
+			oauthRefreshTokenRepository,
+			txManager,
+			emailPolicy,
+			emailThrottle,
+			emailChangeOutboxSender,
+			emailChangedOutboxSender,
+			logger,
+			tracer,
+			emailChangeServiceConfig,
+		)
+	)
+
+	var (
+		oauthStateServiceConfig = services.OAuthStateServiceConfig{
+			StateTTL:          cfg.OAuth.StateTTL,
+			RedirectAllowlist: cfg.OAuth.RedirectAllowlist,
+		}
+
+		oauthStateService = services.NewOAuthStateService(
+			oauthStateRepository,
+			logger,
+			tracer,
+			oauthStateServiceConfig,
+		)
+	)
+
+	var (
+		loginCodeServiceConfig = services.LoginCodeServiceConfig{
+			CodeTTL: cfg.OAuth.LoginCodeTTL,
+		}
+
+		loginCodeService = services.NewLoginCodeService(
+			loginCodeRepository,
+			logger,
+			tracer,
+			loginCodeServiceConfig,
+		)
 	)
 
-	googleAuthHandlerConfig := oauth2.Config{
-		RedirectURL:  cfg.OAuth.Google.RedirectURL,
-		ClientID:     string(secretManager.GoogleClientID().Get()),
-		ClientSecret: string(secretManager.GoogleClientSecret().Get()),
-		Scopes:       cfg.OAuth.Google.Scopes,
-		Endpoint:     google.Endpoint,
+	keySet, err := NewSigningKeySet(cfg.OAuthServer, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		oauthServerServiceConfig = services.OAuthServerServiceConfig{
+			Issuer:                  cfg.Tokens.Issuer,
+			AuthorizationRequestTTL: cfg.OAuthServer.AuthorizationRequestTTL,
+			AuthorizationCodeTTL:    cfg.OAuthServer.AuthorizationCodeTTL,
+			AccessTokenTTL:          cfg.OAuthServer.AccessTokenTTL,
+			RefreshTokenTTL:         cfg.OAuthServer.RefreshTokenTTL,
+			IDTokenTTL:              cfg.OAuthServer.IDTokenTTL,
+		}
+
+		oauthServerService = services.NewOAuthServerService(
+			oauthClientRepository,
+			oauthConsentRepository,
+			oauthRefreshTokenRepository,
+			authorizationRequestRepository,
+			authorizationCodeRepository,
+			userRepository,
+			txManager,
+			secretManager,
+			keySet,
+			apiRegistry,
+			logger,
+			tracer,
+			oauthServerServiceConfig,
+		)
+	)
+
+	oauthProviders, err := NewOAuthProviders(cfg.OAuth, secretManager)
+	if err != nil {
+		return nil, err
+	}
+
+	completionMode, err := NewOAuthCompletionMode(cfg.OAuth.CompletionMode)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionConfig, err := NewSessionConfig(cfg.Session, cfg.Tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	authHandlerConfig := handlers.AuthHandlerConfig{
+		Session: sessionConfig,
 	}
 
-	googleAuthClient := clients.NewGoogleAuthClient(googleAuthHandlerConfig)
+	oauthHandlerConfig := handlers.OAuthHandlerConfig{
+		StateTTL:       cfg.OAuth.StateTTL,
+		CompletionMode: completionMode,
+		FrontendURL:    cfg.OAuth.FrontendURL,
+		Session:        sessionConfig,
+	}
+
+	oauthServerHandlerConfig := handlers.OAuthServerHandlerConfig{
+		Issuer:     cfg.Tokens.Issuer,
+		ConsentURL: cfg.OAuthServer.ConsentURL,
+	}
 
 	var (
-		authHandler       = handlers.NewAuthHandler(authService, logger, tracer)
-		googleAuthHandler = handlers.NewGoogleAuthHandler(googleAuthClient, authService, logger, tracer)
+		authHandler = handlers.NewAuthHandler(
+			authService,
+			registrationService,
+			magicLinkService,
+			emailCodeService,
+			logger,
+			tracer,
+			authHandlerConfig,
+		)
+		oauthHandler = handlers.NewOAuthHandler(
+			oauthProviders,
+			authService,
+			registrationService,
+			oauthStateService,
+			loginCodeService,
+			logger,
+			tracer,
+			oauthHandlerConfig,
+		)
+		oauthServerHandler = handlers.NewOAuthServerHandler(oauthServerService, logger, tracer, oauthServerHandlerConfig)
+		roleHandler        = handlers.NewRoleHandler(roleService, logger, tracer)
+		adminUserHandler   = handlers.NewAdminUserHandler(adminService, logger, tracer)
+		accountHandler     = handlers.NewAccountHandler(accountService, dataExportService, emailChangeService, logger, tracer)
+		mfaHandler         = handlers.NewMFAHandler(mfaService, logger, tracer)
+		webauthnHandler    = handlers.NewWebAuthnHandler(webauthnService, logger, tracer)
+		referralHandler    = handlers.NewReferralHandler(referralService, logger, tracer)
+		invitationHandler  = handlers.NewInvitationHandler(invitationService, logger, tracer)
 	)
 
 	gin.SetMode(cfg.Mode)
@@ -129,18 +523,34 @@ func NewApp(
 	prometheus.MustRegister(responseStatus)
 	prometheus.MustRegister(httpDuration)
 
-	InitAuthRoutes(router, authHandler, googleAuthHandler)
+	var (
+		authMiddleware          = AuthMiddleware(secretManager, cfg.Tokens.Issuer)
+		delegatedAuthMiddleware = DelegatedAuthMiddleware(secretManager, cfg.Tokens.Issuer, apiRegistry.UserInfoAudience())
+		loginRedirectMiddleware = LoginRedirectMiddleware(secretManager, cfg.Tokens.Issuer, cfg.OAuthServer.LoginURL)
+	)
+
+	InitAuthRoutes(router, authMiddleware, authHandler, oauthHandler, accountHandler, mfaHandler, webauthnHandler, referralHandler, invitationHandler)
+	InitOAuthServerRoutes(router, authMiddleware, delegatedAuthMiddleware, loginRedirectMiddleware, oauthServerHandler)
+	InitAdminRoutes(router, authMiddleware, roleHandler, adminUserHandler, invitationHandler)
 	InitSwaggerRoutes(router)
 	InitPrometheusRoutes(router)
 
 	httpServer := server.NewHTTPServer(ctx, cfg.Server.Address, router, logger)
 
 	return &App{
-		logger:     logger,
-		httpServer: httpServer,
-	}
+		logger:            logger,
+		httpServer:        httpServer,
+		emailPolicy:       emailPolicy,
+		accountService:    accountService,
+		dataExportService: dataExportService,
+		cfg:               cfg,
+	}, nil
 }
 
 func (a *App) Run(ctx context.Context) error {
+	go a.emailPolicy.Run(ctx, a.cfg.EmailPolicy.ReloadInterval)
+	go a.accountService.Run(ctx, a.cfg.AccountDeletion.PurgeInterval)
+	go a.dataExportService.Run(ctx, a.cfg.DataExport.GenerateInterval)
+
 	return a.httpServer.Run(ctx)
 }
@@ -1,12 +1,29 @@
 package auth
 
 import (
+	"errors"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/presentation/handlers"
+)
+
+var (
+	errMissingAccessToken = errors.New("missing access token")
+	errInvalidAccessToken = errors.New("invalid access token")
+	errInvalidCSRFToken   = errors.New("invalid csrf token")
+	errUserTokenRequired  = errors.New("access token is not issued to a user")
+	errDelegatedToken     = errors.New("access token is issued to an oauth client")
+	errDelegatedRequired  = errors.New("access token is not issued to an oauth client")
+	errPermissionDenied   = errors.New("permission denied")
 )
 
 func LogMiddleware(log *slog.Logger) gin.HandlerFunc {
@@ -57,3 +74,160 @@ func PrometheusMiddleware(serviceName string) gin.HandlerFunc {
 		totalRequests.WithLabelValues(serviceName, path).Inc()
 	}
 }
+
+// AuthMiddleware authenticates users by a Bearer access token, or by the
+// access token cookie, in which case unsafe methods also require a valid CSRF
+// token. Only user tokens issued for the service itself are accepted, tokens
+// issued to OAuth clients on behalf of users are not.
+func AuthMiddleware(secretManager services.SecretManager, issuer string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, status, err := authenticate(c, secretManager, issuer)
+		if err != nil {
+			c.String(status, err.Error())
+			c.Abort()
+			return
+		}
+
+		handlers.SetAccessTokenClaims(c, claims)
+
+		c.Next()
+	}
+}
+
+// LoginRedirectMiddleware authenticates like AuthMiddleware, but sends a
+// browser without an access token to the login page, which is expected to
+// return to return_to after login.
+func LoginRedirectMiddleware(secretManager services.SecretManager, issuer string, loginURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, status, err := authenticate(c, secretManager, issuer)
+		if err != nil {
+			if status != http.StatusUnauthorized || loginURL == "" {
+				c.String(status, err.Error())
+				c.Abort()
+				return
+			}
+
+			target, err := url.Parse(loginURL)
+			if err != nil {
+				c.Status(http.StatusInternalServerError)
+				c.Abort()
+				return
+			}
+
+			query := target.Query()
+			query.Set("return_to", c.Request.URL.RequestURI())
+			target.RawQuery = query.Encode()
+
+			c.Redirect(http.StatusSeeOther, target.String())
+			c.Abort()
+			return
+		}
+
+		handlers.SetAccessTokenClaims(c, claims)
+
+		c.Next()
+	}
+}
+
+// DelegatedAuthMiddleware authenticates users by a Bearer access token issued
+// to an OAuth client on their behalf for the audience.
+func DelegatedAuthMiddleware(secretManager services.SecretManager, issuer string, audience string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.String(http.StatusUnauthorized, errMissingAccessToken.Error())
+			c.Abort()
+			return
+		}
+
+		requirements := vobjects.AccessTokenRequirements{
+			Issuer:   issuer,
+			Audience: audience,
+		}
+
+		claims, err := vobjects.VerifyAccessTokenFor(token, secretManager.SecretKey().Get(), requirements)
+		if err != nil {
+			c.String(http.StatusUnauthorized, errInvalidAccessToken.Error())
+			c.Abort()
+			return
+		}
+
+		if !claims.IsDelegated() {
+			c.String(http.StatusForbidden, errDelegatedRequired.Error())
+			c.Abort()
+			return
+		}
+
+		handlers.SetAccessTokenClaims(c, claims)
+
+		c.Next()
+	}
+}
+
+// RequirePermission allows requests with an access token that has all the
+// permissions. It must follow AuthMiddleware.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := handlers.GetAccessTokenClaims(c)
+		if !ok {
+			c.String(http.StatusUnauthorized, errMissingAccessToken.Error())
+			c.Abort()
+			return
+		}
+
+		for _, permission := range permissions {
+			if !claims.HasPermission(permission) {
+				c.String(http.StatusForbidden, errPermissionDenied.Error())
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func authenticate(c *gin.Context, secretManager services.SecretManager, issuer string) (vobjects.AccessTokenClaims, int, error) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		cookieToken, err := c.Cookie(handlers.AccessTokenCookie)
+		if err != nil || cookieToken == "" {
+			return vobjects.AccessTokenClaims{}, http.StatusUnauthorized, errMissingAccessToken
+		}
+
+		if !safeMethod(c.Request.Method) && !handlers.ValidCSRFToken(c) {
+			return vobjects.AccessTokenClaims{}, http.StatusForbidden, errInvalidCSRFToken
+		}
+
+		token = cookieToken
+	}
+
+	requirements := vobjects.AccessTokenRequirements{
+		Issuer:   issuer,
+		Audience: issuer,
+	}
+
+	claims, err := vobjects.VerifyAccessTokenFor(token, secretManager.SecretKey().Get(), requirements)
+	if err != nil {
+		return vobjects.AccessTokenClaims{}, http.StatusUnauthorized, errInvalidAccessToken
+	}
+
+	if !claims.IsUser() {
+		return vobjects.AccessTokenClaims{}, http.StatusForbidden, errUserTokenRequired
+	}
+
+	if claims.IsDelegated() {
+		return vobjects.AccessTokenClaims{}, http.StatusForbidden, errDelegatedToken
+	}
+
+	return claims, http.StatusOK, nil
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/presentation/handlers"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "https://auth.example.com"
+
+var testUserInfoAudience = testIssuer + "/oauth/userinfo"
+
+type testSecretManager struct{}
+
+func (testSecretManager) SecretKey() domain.Secret {
+	return domain.Secret("test-secret-key")
+}
+
+func newTestUserToken(t *testing.T, audience ...string) string {
+	payload := vobjects.AccessTokenPayload{
+		UserID: uuid.New(),
+		Email:  "test.email@gmail.com",
+	}
+
+	token, err := vobjects.NewAccessToken(testIssuer, time.Minute, testSecretManager{}.SecretKey().Get(), payload, vobjects.AccessTokenGrant{Audience: audience})
+	require.NoError(t, err)
+
+	return token.Token()
+}
+
+func newTestDelegatedToken(t *testing.T, audience ...string) string {
+	payload := vobjects.AccessTokenPayload{
+		UserID: uuid.New(),
+		Email:  "test.email@gmail.com",
+	}
+
+	grant := vobjects.AccessTokenGrant{
+		Audience: audience,
+		Scopes:   vobjects.Scopes{vobjects.ScopeOpenID},
+	}
+
+	token, err := vobjects.NewDelegatedAccessToken(testIssuer, time.Minute, testSecretManager{}.SecretKey().Get(), payload, "client-id", grant)
+	require.NoError(t, err)
+
+	return token.Token()
+}
+
+func newTestClientToken(t *testing.T, audience ...string) string {
+	token, err := vobjects.NewClientAccessToken(testIssuer, time.Minute, testSecretManager{}.SecretKey().Get(), "client-id", vobjects.AccessTokenGrant{Audience: audience})
+	require.NoError(t, err)
+
+	return token.Token()
+}
+
+func serveWithMiddleware(middleware gin.HandlerFunc, token string) int {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/", middleware, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder.Code
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{
+			name:   "user token",
+			token:  newTestUserToken(t, testIssuer),
+			status: http.StatusOK,
+		},
+		{
+			name:   "missing token",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "delegated token",
+			token:  newTestDelegatedToken(t, testUserInfoAudience),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "delegated token for the service",
+			token:  newTestDelegatedToken(t, testIssuer),
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "client token",
+			token:  newTestClientToken(t, testIssuer),
+			status: http.StatusForbidden,
+		},
+	}
+
+	middleware := AuthMiddleware(testSecretManager{}, testIssuer)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.status, serveWithMiddleware(middleware, tt.token))
+		})
+	}
+}
+
+func TestDelegatedAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{
+			name:   "delegated token",
+			token:  newTestDelegatedToken(t, testUserInfoAudience),
+			status: http.StatusOK,
+		},
+		{
+			name:   "missing token",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "user token",
+			token:  newTestUserToken(t, testIssuer),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "user token for the audience",
+			token:  newTestUserToken(t, testUserInfoAudience),
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "client token for the audience",
+			token:  newTestClientToken(t, testUserInfoAudience),
+			status: http.StatusForbidden,
+		},
+	}
+
+	middleware := DelegatedAuthMiddleware(testSecretManager{}, testIssuer, testUserInfoAudience)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.status, serveWithMiddleware(middleware, tt.token))
+		})
+	}
+}
+
+func TestAuthMiddlewareCookie(t *testing.T) {
+	const csrfToken = "csrf-token"
+
+	tests := []struct {
+		name      string
+		method    string
+		csrfToken string
+		status    int
+	}{
+		{
+			name:   "safe method without csrf token",
+			method: http.MethodGet,
+			status: http.StatusOK,
+		},
+		{
+			name:   "unsafe method without csrf token",
+			method: http.MethodPost,
+			status: http.StatusForbidden,
+		},
+		{
+			name:      "unsafe method with wrong csrf token",
+			method:    http.MethodPost,
+			csrfToken: "wrong-token",
+			status:    http.StatusForbidden,
+		},
+		{
+			name:      "unsafe method with csrf token",
+			method:    http.MethodPost,
+			csrfToken: csrfToken,
+			status:    http.StatusOK,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Any("/", AuthMiddleware(testSecretManager{}, testIssuer), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	token := newTestUserToken(t, testIssuer)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, "/", nil)
+			request.AddCookie(&http.Cookie{Name: handlers.AccessTokenCookie, Value: token})
+			request.AddCookie(&http.Cookie{Name: "csrf_token", Value: csrfToken})
+
+			if tt.csrfToken != "" {
+				request.Header.Set(handlers.CSRFTokenHeader, tt.csrfToken)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.status, recorder.Code)
+		})
+	}
+}
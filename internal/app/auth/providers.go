@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rozhnof/auth-service/internal/infrastructure/secrets"
+	"github.com/rozhnof/auth-service/internal/pkg/config"
+	"github.com/rozhnof/auth-service/internal/presentation/clients"
+	"github.com/rozhnof/auth-service/internal/presentation/handlers"
+)
+
+const (
+	oauthClientTimeout = time.Second * 10
+)
+
+const (
+	providerTypeOIDC   = "oidc"
+	providerTypeGitHub = "github"
+)
+
+func NewOAuthProviders(cfg config.OAuth, secretManager secrets.EnvSecretManager) ([]clients.OAuthProvider, error) {
+	httpClient := &http.Client{
+		Timeout: oauthClientTimeout,
+	}
+
+	providers := make([]clients.OAuthProvider, 0, len(cfg.Providers))
+
+	for _, providerCfg := range cfg.Providers {
+		var (
+			clientID     = string(secretManager.OAuthClientID(providerCfg.Name).Get())
+			clientSecret = string(secretManager.OAuthClientSecret(providerCfg.Name).Get())
+		)
+
+		switch providerCfg.Type {
+		case providerTypeOIDC:
+			oidcProviderConfig := clients.OIDCProviderConfig{
+				Name:         providerCfg.Name,
+				Issuer:       providerCfg.Issuer,
+				ClientID:     clientID,
+				ClientSecret: clientSecret,
+				RedirectURL:  providerCfg.RedirectURL,
+				Scopes:       providerCfg.Scopes,
+			}
+
+			providers = append(providers, clients.NewOIDCProvider(oidcProviderConfig, httpClient))
+		case providerTypeGitHub:
+			gitHubProviderConfig := clients.GitHubProviderConfig{
+				Name:         providerCfg.Name,
+				ClientID:     clientID,
+				ClientSecret: clientSecret,
+				RedirectURL:  providerCfg.RedirectURL,
+				Scopes:       providerCfg.Scopes,
+				AuthURL:      providerCfg.AuthURL,
+				TokenURL:     providerCfg.TokenURL,
+				APIURL:       providerCfg.APIURL,
+			}
+
+			providers = append(providers, clients.NewGitHubProvider(gitHubProviderConfig, httpClient))
+		default:
+			return nil, fmt.Errorf("invalid oauth provider type %q for provider %s", providerCfg.Type, providerCfg.Name)
+		}
+	}
+
+	return providers, nil
+}
+
+func NewOAuthCompletionMode(mode string) (handlers.OAuthCompletionMode, error) {
+	switch completionMode := handlers.OAuthCompletionMode(mode); completionMode {
+	case handlers.OAuthCompletionCode, handlers.OAuthCompletionCookie:
+		return completionMode, nil
+	default:
+		return "", fmt.Errorf("invalid oauth completion mode %q", mode)
+	}
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"github.com/rozhnof/auth-service/internal/pkg/config"
+)
+
+func NewInvitationServiceConfig(cfg config.Registration) (services.InvitationServiceConfig, error) {
+	invitationServiceConfig := services.InvitationServiceConfig{
+		Mode: services.RegistrationMode(cfg.Mode),
+		TTL:  cfg.InvitationTTL,
+	}
+
+	switch invitationServiceConfig.Mode {
+	case services.RegistrationOpen, services.RegistrationInviteOnly, services.RegistrationClosed:
+	default:
+		return services.InvitationServiceConfig{}, fmt.Errorf("invalid registration mode %q", cfg.Mode)
+	}
+
+	return invitationServiceConfig, nil
+}
@@ -3,23 +3,159 @@ package auth
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
 	"github.com/rozhnof/auth-service/internal/presentation/handlers"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func InitAuthRoutes(router gin.IRouter, authHandler *handlers.AuthHandler, googleAuthHandler *handlers.GoogleAuthHandler) {
+func InitAuthRoutes(
+	router gin.IRouter,
+	authMiddleware gin.HandlerFunc,
+	authHandler *handlers.AuthHandler,
+	oauthHandler *handlers.OAuthHandler,
+	accountHandler *handlers.AccountHandler,
+	mfaHandler *handlers.MFAHandler,
+	webauthnHandler *handlers.WebAuthnHandler,
+	referralHandler *handlers.ReferralHandler,
+	invitationHandler *handlers.InvitationHandler,
+) {
 	authGroup := router.Group("/auth")
 	{
 		authGroup.POST("/register", authHandler.Register)
 		authGroup.POST("/login", authHandler.Login)
 		authGroup.POST("/refresh", authHandler.Refresh)
 		authGroup.GET("/confirm", authHandler.Confirm)
+		authGroup.POST("/confirm/code", authHandler.ConfirmWithCode)
+		authGroup.POST("/confirm/code/send", authHandler.SendConfirmCode)
+		authGroup.POST("/login/code", authHandler.SendLoginCode)
+		authGroup.POST("/login/code/verify", authHandler.CodeLogin)
+		authGroup.POST("/password", authMiddleware, authHandler.SetPassword)
+		authGroup.POST("/token", oauthHandler.Token)
+		authGroup.POST("/magic-link", authHandler.SendMagicLink)
+		authGroup.GET("/magic-link/verify", authHandler.VerifyMagicLink)
+		authGroup.POST("/magic-link/verify", authHandler.VerifyMagicLink)
 
-		googleAuthGroup := router.Group("/google")
+		oauthGroup := authGroup.Group("/:provider")
 		{
-			googleAuthGroup.GET("/login", googleAuthHandler.Login)
-			googleAuthGroup.GET("/callback", googleAuthHandler.Callback)
+			oauthGroup.GET("/login", oauthHandler.Login)
+			oauthGroup.GET("/callback", oauthHandler.Callback)
+		}
+
+		meGroup := authGroup.Group("/me", authMiddleware)
+		{
+			meGroup.DELETE("", accountHandler.DeleteAccount)
+			meGroup.DELETE("/deletion", accountHandler.CancelDeletion)
+			meGroup.GET("/export", accountHandler.ExportData)
+			meGroup.GET("/referral-code", referralHandler.GetReferralCode)
+		}
+
+		emailGroup := authGroup.Group("/email")
+		{
+			emailGroup.POST("/change", authMiddleware, accountHandler.ChangeEmail)
+			emailGroup.GET("/confirm", accountHandler.ConfirmEmailChange)
+		}
+
+		mfaGroup := authGroup.Group("/mfa")
+		{
+			mfaGroup.POST("/totp/setup", authMiddleware, mfaHandler.SetupTOTP)
+			mfaGroup.POST("/totp/verify", authMiddleware, mfaHandler.VerifyTOTP)
+			mfaGroup.POST("/recovery-codes", authMiddleware, mfaHandler.RegenerateRecoveryCodes)
+			mfaGroup.POST("/webauthn", mfaHandler.BeginWebAuthnChallenge)
+			mfaGroup.POST("/challenge", authHandler.CompleteMFAChallenge)
+		}
+
+		webauthnGroup := authGroup.Group("/webauthn")
+		{
+			webauthnGroup.POST("/register/begin", authMiddleware, webauthnHandler.BeginRegistration)
+			webauthnGroup.POST("/register/finish", authMiddleware, webauthnHandler.FinishRegistration)
+			webauthnGroup.GET("/credentials", authMiddleware, webauthnHandler.ListCredentials)
+			webauthnGroup.DELETE("/credentials/:id", authMiddleware, webauthnHandler.DeleteCredential)
+			webauthnGroup.POST("/login/begin", webauthnHandler.BeginLogin)
+			webauthnGroup.POST("/login/finish", authHandler.FinishWebAuthnLogin)
+		}
+
+		invitationGroup := authGroup.Group("/invitations")
+		{
+			invitationGroup.POST("", authMiddleware, invitationHandler.Invite)
+			invitationGroup.GET("", authMiddleware, invitationHandler.ListInvitations)
+			invitationGroup.DELETE("/:invitation_id", authMiddleware, invitationHandler.RevokeInvitation)
+			invitationGroup.POST("/accept", invitationHandler.AcceptInvitation)
+		}
+
+		identityGroup := authGroup.Group("/identities", authMiddleware)
+		{
+			identityGroup.GET("", oauthHandler.ListIdentities)
+			identityGroup.POST("/:provider", oauthHandler.LinkIdentity)
+			identityGroup.DELETE("/:provider", oauthHandler.UnlinkIdentity)
+		}
+	}
+}
+
+func InitOAuthServerRoutes(
+	router gin.IRouter,
+	authMiddleware gin.HandlerFunc,
+	delegatedAuthMiddleware gin.HandlerFunc,
+	loginRedirectMiddleware gin.HandlerFunc,
+	oauthServerHandler *handlers.OAuthServerHandler,
+) {
+	router.GET("/.well-known/openid-configuration", oauthServerHandler.Discovery)
+
+	oauthGroup := router.Group("/oauth")
+	{
+		oauthGroup.GET("/authorize", loginRedirectMiddleware, oauthServerHandler.Authorize)
+		oauthGroup.POST("/token", oauthServerHandler.Token)
+		oauthGroup.GET("/jwks", oauthServerHandler.JWKS)
+		oauthGroup.GET("/userinfo", delegatedAuthMiddleware, oauthServerHandler.UserInfo)
+		oauthGroup.POST("/userinfo", delegatedAuthMiddleware, oauthServerHandler.UserInfo)
+
+		consentGroup := oauthGroup.Group("/consent", authMiddleware)
+		{
+			consentGroup.GET("/:request_id", oauthServerHandler.GetAuthorizationRequest)
+			consentGroup.POST("/:request_id", oauthServerHandler.Consent)
+		}
+	}
+}
+
+func InitAdminRoutes(
+	router gin.IRouter,
+	authMiddleware gin.HandlerFunc,
+	roleHandler *handlers.RoleHandler,
+	adminUserHandler *handlers.AdminUserHandler,
+	invitationHandler *handlers.InvitationHandler,
+) {
+	adminGroup := router.Group("/admin", authMiddleware)
+	{
+		rolesGroup := adminGroup.Group("/roles")
+		{
+			rolesGroup.GET("", RequirePermission(entities.PermissionRolesRead), roleHandler.ListRoles)
+			rolesGroup.POST("", RequirePermission(entities.PermissionRolesWrite), roleHandler.CreateRole)
+		}
+
+		usersGroup := adminGroup.Group("/users")
+		{
+			usersGroup.GET("", RequirePermission(entities.PermissionUsersRead), adminUserHandler.ListUsers)
+			usersGroup.GET("/:user_id", RequirePermission(entities.PermissionUsersRead), adminUserHandler.GetUser)
+			usersGroup.DELETE("/:user_id", RequirePermission(entities.PermissionUsersWrite), adminUserHandler.DeleteUser)
+			usersGroup.POST("/:user_id/confirm", RequirePermission(entities.PermissionUsersWrite), adminUserHandler.ConfirmUser)
+			usersGroup.POST("/:user_id/suspend", RequirePermission(entities.PermissionUsersWrite), adminUserHandler.SuspendUser)
+			usersGroup.POST("/:user_id/disable", RequirePermission(entities.PermissionUsersWrite), adminUserHandler.DisableUser)
+			usersGroup.POST("/:user_id/enable", RequirePermission(entities.PermissionUsersWrite), adminUserHandler.EnableUser)
+			usersGroup.POST("/:user_id/logout", RequirePermission(entities.PermissionUsersWrite), adminUserHandler.LogoutUser)
+		}
+
+		userRolesGroup := adminGroup.Group("/users/:user_id/roles")
+		{
+			userRolesGroup.GET("", RequirePermission(entities.PermissionRolesRead), roleHandler.ListUserRoles)
+			userRolesGroup.PUT("/:role", RequirePermission(entities.PermissionRolesWrite), roleHandler.AssignRole)
+			userRolesGroup.DELETE("/:role", RequirePermission(entities.PermissionRolesWrite), roleHandler.UnassignRole)
+		}
+
+		invitationsGroup := adminGroup.Group("/invitations")
+		{
+			invitationsGroup.GET("", RequirePermission(entities.PermissionUsersRead), invitationHandler.AdminListInvitations)
+			invitationsGroup.POST("", RequirePermission(entities.PermissionUsersWrite), invitationHandler.AdminInvite)
+			invitationsGroup.DELETE("/:invitation_id", RequirePermission(entities.PermissionUsersWrite), invitationHandler.AdminRevokeInvitation)
 		}
 	}
 }
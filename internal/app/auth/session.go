@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rozhnof/auth-service/internal/pkg/config"
+	"github.com/rozhnof/auth-service/internal/presentation/handlers"
+)
+
+const (
+	sessionModeBody   = "body"
+	sessionModeCookie = "cookie"
+)
+
+func NewSessionConfig(cfg config.Session, tokens config.Tokens) (handlers.SessionConfig, error) {
+	sessionConfig := handlers.SessionConfig{
+		AccessTokenTTL:  tokens.AccessTokenTTL,
+		RefreshTokenTTL: tokens.RefreshTokenTTL,
+	}
+
+	switch cfg.Mode {
+	case sessionModeBody:
+	case sessionModeCookie:
+		sessionConfig.CookieMode = true
+	default:
+		return handlers.SessionConfig{}, fmt.Errorf("invalid session mode %q", cfg.Mode)
+	}
+
+	switch cfg.SameSite {
+	case "strict":
+		sessionConfig.SameSite = http.SameSiteStrictMode
+	case "lax":
+		sessionConfig.SameSite = http.SameSiteLaxMode
+	case "none":
+		sessionConfig.SameSite = http.SameSiteNoneMode
+	default:
+		return handlers.SessionConfig{}, fmt.Errorf("invalid session same site %q", cfg.SameSite)
+	}
+
+	return sessionConfig, nil
+}
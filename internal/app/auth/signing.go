@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"log/slog"
+
+	"github.com/rozhnof/auth-service/internal/infrastructure/signing"
+	"github.com/rozhnof/auth-service/internal/pkg/config"
+)
+
+func NewSigningKeySet(cfg config.OAuthServer, logger *slog.Logger) (*signing.KeySet, error) {
+	if len(cfg.SigningKeys) == 0 {
+		logger.Warn("no oauth signing keys configured, generated key is used and tokens will not survive restart")
+
+		return signing.NewGeneratedKeySet()
+	}
+
+	return signing.NewKeySet(cfg.SigningKeys)
+}
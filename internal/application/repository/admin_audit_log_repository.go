@@ -0,0 +1,14 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type AdminAuditLogRepository interface {
+	Create(ctx context.Context, record *entities.AdminAuditRecord) error
+	ListByTargetUserID(ctx context.Context, userID uuid.UUID) ([]*entities.AdminAuditRecord, error)
+}
@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+type AuthorizationRequestRepository interface {
+	Create(ctx context.Context, request *vobjects.AuthorizationRequest, ttl time.Duration) error
+	Get(ctx context.Context, id string) (*vobjects.AuthorizationRequest, error)
+	Pop(ctx context.Context, id string) (*vobjects.AuthorizationRequest, error)
+}
+
+type AuthorizationCodeRepository interface {
+	Create(ctx context.Context, code *vobjects.AuthorizationCode, ttl time.Duration) error
+	Pop(ctx context.Context, code string) (*vobjects.AuthorizationCode, error)
+}
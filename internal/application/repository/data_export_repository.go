@@ -0,0 +1,16 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type DataExportRepository interface {
+	Create(ctx context.Context, export *entities.DataExport) error
+	Update(ctx context.Context, export *entities.DataExport) error
+
+	GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*entities.DataExport, error)
+	ListPending(ctx context.Context, limit int32) ([]*entities.DataExport, error)
+}
@@ -0,0 +1,14 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type EmailChangeRepository interface {
+	Save(ctx context.Context, change *entities.EmailChange) error
+	GetByToken(ctx context.Context, token string) (*entities.EmailChange, error)
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"context"
+
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+type EmailCodeRepository interface {
+	// Save stores the code, replacing the code sent before for the email and
+	// purpose.
+	Save(ctx context.Context, code *vobjects.EmailCode) error
+	Get(ctx context.Context, email string, purpose vobjects.EmailCodePurpose) (*vobjects.EmailCode, error)
+	// AddAttempt counts an attempt to enter the code and returns the number
+	// of attempts so far, concurrent attempts are all counted.
+	AddAttempt(ctx context.Context, email string, purpose vobjects.EmailCodePurpose) (int, error)
+	// Consume deletes the code if it is still the stored one, only one of
+	// concurrent calls consumes it, the others get ErrObjectNotFound.
+	Consume(ctx context.Context, code *vobjects.EmailCode) error
+	Delete(ctx context.Context, email string, purpose vobjects.EmailCodePurpose) error
+}
@@ -0,0 +1,12 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+type EmailThrottleRepository interface {
+	// Hit counts an email sent to the address and returns the number of
+	// emails sent to it in the current window.
+	Hit(ctx context.Context, email string, window time.Duration) (int64, error)
+}
@@ -1,9 +1,17 @@
 package repo
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type UserFilters struct {
-	UserIDs []uuid.UUID
+	UserIDs     []uuid.UUID
+	EmailPrefix *string
+	Confirmed   *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
 }
 
 type Pagination struct {
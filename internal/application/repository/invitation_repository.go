@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *entities.Invitation) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Invitation, error)
+	GetByEmail(ctx context.Context, email string) (*entities.Invitation, error)
+	GetByToken(ctx context.Context, token string) (*entities.Invitation, error)
+	List(ctx context.Context) ([]*entities.Invitation, error)
+	ListByInviterID(ctx context.Context, inviterID uuid.UUID) ([]*entities.Invitation, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,13 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+type LoginCodeRepository interface {
+	Create(ctx context.Context, code *vobjects.LoginCode, ttl time.Duration) error
+	Pop(ctx context.Context, code string) (*vobjects.LoginCode, error)
+}
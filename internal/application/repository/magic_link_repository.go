@@ -0,0 +1,12 @@
+package repo
+
+import (
+	"context"
+
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+type MagicLinkRepository interface {
+	Create(ctx context.Context, token *vobjects.MagicLinkToken) error
+	Pop(ctx context.Context, token string) (*vobjects.MagicLinkToken, error)
+}
@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+type MFAChallengeRepository interface {
+	Create(ctx context.Context, challenge *vobjects.MFAChallenge, ttl time.Duration) error
+	Get(ctx context.Context, token string) (*vobjects.MFAChallenge, error)
+	// AddAttempt counts an attempt to pass the challenge and returns the
+	// number of attempts so far, concurrent attempts are all counted.
+	AddAttempt(ctx context.Context, token string) (int, error)
+	// Pop deletes the challenge and returns it, only one of concurrent calls
+	// gets it.
+	Pop(ctx context.Context, token string) (*vobjects.MFAChallenge, error)
+	Delete(ctx context.Context, token string) error
+}
@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MFARecoveryCodeRepository interface {
+	// Replace deletes the codes of the user and stores the given hashes.
+	Replace(ctx context.Context, userID uuid.UUID, codeHashes []string, createdAt time.Time) error
+	// Use marks an unused code as used, ErrObjectNotFound is returned for an
+	// unknown or used code.
+	Use(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) error
+	CountUnused(ctx context.Context, userID uuid.UUID) (int64, error)
+}
@@ -0,0 +1,12 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client *entities.OAuthClient) error
+	GetByID(ctx context.Context, clientID string) (*entities.OAuthClient, error)
+}
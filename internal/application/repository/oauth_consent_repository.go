@@ -0,0 +1,14 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type OAuthConsentRepository interface {
+	Save(ctx context.Context, consent *entities.OAuthConsent) error
+	Get(ctx context.Context, userID uuid.UUID, clientID string) (*entities.OAuthConsent, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.OAuthConsent, error)
+}
@@ -0,0 +1,16 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+type OAuthRefreshTokenRepository interface {
+	Create(ctx context.Context, token *vobjects.OAuthRefreshToken) error
+	Pop(ctx context.Context, token string) (*vobjects.OAuthRefreshToken, error)
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*vobjects.OAuthRefreshToken, error)
+}
@@ -0,0 +1,13 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+type OAuthStateRepository interface {
+	Create(ctx context.Context, state *vobjects.OAuthState, ttl time.Duration) error
+	Pop(ctx context.Context, state string) (*vobjects.OAuthState, error)
+}
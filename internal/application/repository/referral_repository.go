@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+type ReferralRepository interface {
+	Create(ctx context.Context, referral *entities.Referral) error
+	CountByReferrerID(ctx context.Context, referrerID uuid.UUID) (int64, error)
+}
+
+type ReferralCodeRepository interface {
+	Create(ctx context.Context, code *vobjects.ReferralCode) error
+	GetByCode(ctx context.Context, code string) (*vobjects.ReferralCode, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*vobjects.ReferralCode, error)
+}
@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, role *entities.Role) error
+	GetByName(ctx context.Context, name string) (*entities.Role, error)
+	List(ctx context.Context) ([]*entities.Role, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Role, error)
+
+	Assign(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error
+	Unassign(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error
+}
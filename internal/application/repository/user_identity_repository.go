@@ -0,0 +1,16 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *entities.UserIdentity) error
+	Delete(ctx context.Context, userID uuid.UUID, provider string) error
+
+	GetByProviderSubject(ctx context.Context, provider string, subject string) (*entities.UserIdentity, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]entities.UserIdentity, error)
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rozhnof/auth-service/internal/domain/entities"
@@ -17,4 +18,7 @@ type UserRepository interface {
 	GetByRefreshToken(ctx context.Context, refreshToken string) (*entities.User, error)
 
 	List(ctx context.Context, filters *UserFilters, pagination *Pagination) ([]entities.User, error)
+
+	ListDueForDeletion(ctx context.Context, now time.Time, limit int32) ([]uuid.UUID, error)
+	Purge(ctx context.Context, userID uuid.UUID) error
 }
@@ -0,0 +1,14 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type UserTOTPRepository interface {
+	Save(ctx context.Context, totp *entities.UserTOTP) error
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.UserTOTP, error)
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,23 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+type WebAuthnCredentialRepository interface {
+	Create(ctx context.Context, credential *entities.WebAuthnCredential) error
+	GetByID(ctx context.Context, id []byte) (*entities.WebAuthnCredential, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.WebAuthnCredential, error)
+	UpdateUsage(ctx context.Context, credential *entities.WebAuthnCredential) error
+	Delete(ctx context.Context, userID uuid.UUID, id []byte) error
+}
+
+type WebAuthnSessionRepository interface {
+	Create(ctx context.Context, session *vobjects.WebAuthnSession, ttl time.Duration) error
+	Pop(ctx context.Context, challenge []byte) (*vobjects.WebAuthnSession, error)
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AccountServiceConfig struct {
+	DeletionGracePeriod time.Duration
+	PurgeBatchSize      int32
+}
+
+// AccountService handles self-service account deletion. A deleted account is
+// kept for the grace period, so the user can cancel the deletion, and is
+// purged by Run after that.
+type AccountService struct {
+	userRepository         repo.UserRepository
+	refreshTokenRepository repo.OAuthRefreshTokenRepository
+	txManager              repo.TransactionManager
+	deletedMsgSender       MessageSender
+	log                    *slog.Logger
+	tracer                 trace.Tracer
+	cfg                    AccountServiceConfig
+}
+
+func NewAccountService(
+	userRepository repo.UserRepository,
+	refreshTokenRepository repo.OAuthRefreshTokenRepository,
+	txManager repo.TransactionManager,
+	deletedMsgSender MessageSender,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg AccountServiceConfig,
+) *AccountService {
+	return &AccountService{
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		txManager:              txManager,
+		deletedMsgSender:       deletedMsgSender,
+		log:                    log,
+		tracer:                 tracer,
+		cfg:                    cfg,
+	}
+}
+
+// ScheduleDeletion returns when the account is going to be purged. Refresh
+// tokens of the user, including the ones issued to OAuth clients, are revoked,
+// so sessions don't outlive the request. Access tokens already issued stay
+// valid until they expire.
+func (s *AccountService) ScheduleDeletion(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	ctx, span := s.tracer.Start(ctx, "AccountService.ScheduleDeletion")
+	defer span.End()
+
+	var deletionScheduledAt time.Time
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		user.ScheduleDeletion(s.cfg.DeletionGracePeriod)
+		user.RevokeRefreshToken()
+
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.refreshTokenRepository.DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+
+		deletionScheduledAt = *user.DeletionScheduledAt()
+
+		return nil
+	}); err != nil {
+		return time.Time{}, err
+	}
+
+	return deletionScheduledAt, nil
+}
+
+func (s *AccountService) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "AccountService.CancelDeletion")
+	defer span.End()
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := user.CancelDeletion(); err != nil {
+			return err
+		}
+
+		return s.userRepository.Update(ctx, user)
+	})
+}
+
+// Run purges accounts whose deletion grace period is over every interval.
+func (s *AccountService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeDeleted(ctx); err != nil {
+				s.log.Warn("failed purge deleted accounts", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// PurgeDeleted purges a batch of accounts whose deletion grace period is over
+// and returns the number of purged accounts.
+func (s *AccountService) PurgeDeleted(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "AccountService.PurgeDeleted")
+	defer span.End()
+
+	userIDs, err := s.userRepository.ListDueForDeletion(ctx, time.Now(), s.cfg.PurgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+
+	for _, userID := range userIDs {
+		ok, err := s.purge(ctx, userID)
+		if err != nil {
+			return purged, err
+		}
+
+		if ok {
+			purged++
+		}
+	}
+
+	if purged > 0 {
+		s.log.Info("purged deleted accounts", slog.Int("count", purged))
+	}
+
+	return purged, nil
+}
+
+// purge reports false if the account was purged or its deletion was canceled
+// since it was listed.
+func (s *AccountService) purge(ctx context.Context, userID uuid.UUID) (bool, error) {
+	purged := false
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repo.ErrObjectNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		if !user.DeletionDue() {
+			return nil
+		}
+
+		if err := s.userRepository.Purge(ctx, userID); err != nil {
+			return err
+		}
+
+		deletedMsg := UserDeletedMessage{
+			UserID:    user.ID(),
+			Email:     user.Email(),
+			DeletedAt: time.Now(),
+		}
+
+		if err := s.deletedMsgSender.SendMessage(ctx, deletedMsg); err != nil {
+			return err
+		}
+
+		purged = true
+
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return purged, nil
+}
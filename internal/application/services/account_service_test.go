@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAccountService(tt *authServiceTest, deletedMessages *messageRecorder, gracePeriod time.Duration) *AccountService {
+	return NewAccountService(tt.users, tt.refreshTokens, fakeTxManager{}, deletedMessages, testLogger, testTracer, AccountServiceConfig{
+		DeletionGracePeriod: gracePeriod,
+		PurgeBatchSize:      10,
+	})
+}
+
+func TestAccountServiceCancelDeletion(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	deletedMessages := &messageRecorder{}
+	service := newTestAccountService(tt, deletedMessages, time.Hour)
+	user := tt.createUser(t, testUserEmail)
+
+	err := service.CancelDeletion(ctx, user.ID())
+	require.ErrorIs(t, err, domain.ErrDeletionNotScheduled)
+
+	scheduledAt, err := service.ScheduleDeletion(ctx, user.ID())
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Hour), scheduledAt, time.Minute)
+
+	// Scheduling again keeps the first date.
+	again, err := service.ScheduleDeletion(ctx, user.ID())
+	require.NoError(t, err)
+	require.Equal(t, scheduledAt, again)
+
+	purged, err := service.PurgeDeleted(ctx)
+	require.NoError(t, err)
+	require.Zero(t, purged)
+
+	require.NoError(t, service.CancelDeletion(ctx, user.ID()))
+	require.Nil(t, tt.getUser(t, testUserEmail).DeletionScheduledAt())
+	require.Empty(t, deletedMessages.Messages())
+}
+
+func TestAccountServicePurgeDeleted(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	deletedMessages := &messageRecorder{}
+	service := newTestAccountService(tt, deletedMessages, -time.Minute)
+	user := tt.createUser(t, testUserEmail)
+	kept := tt.createUser(t, "kept@example.com")
+
+	_, err := service.ScheduleDeletion(ctx, user.ID())
+	require.NoError(t, err)
+
+	purged, err := service.PurgeDeleted(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	_, err = tt.users.GetByID(ctx, user.ID())
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	_, err = tt.users.GetByID(ctx, kept.ID())
+	require.NoError(t, err)
+
+	messages := deletedMessages.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, user.ID(), messages[0].(UserDeletedMessage).UserID)
+
+	purged, err = service.PurgeDeleted(ctx)
+	require.NoError(t, err)
+	require.Zero(t, purged)
+}
+
+func TestAccountServiceScheduleDeletionRevokesSessions(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	service := newTestAccountService(tt, &messageRecorder{}, time.Hour)
+	user := tt.createUser(t, testUserEmail)
+
+	result, err := tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+
+	tt.addOAuthRefreshToken(t, user)
+
+	_, err = service.ScheduleDeletion(ctx, user.ID())
+	require.NoError(t, err)
+
+	tt.requireNoOAuthRefreshTokens(t, user)
+
+	_, _, err = tt.service.Refresh(ctx, result.RefreshToken, AccessTokenRequest{})
+	require.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AdminService manages user accounts on behalf of an admin. Every change is
+// written to the admin audit log in the same transaction.
+type AdminService struct {
+	userRepository         repo.UserRepository
+	refreshTokenRepository repo.OAuthRefreshTokenRepository
+	auditLogRepository     repo.AdminAuditLogRepository
+	txManager              repo.TransactionManager
+	statusMsgSender        MessageSender
+	log                    *slog.Logger
+	tracer                 trace.Tracer
+}
+
+func NewAdminService(
+	userRepository repo.UserRepository,
+	refreshTokenRepository repo.OAuthRefreshTokenRepository,
+	auditLogRepository repo.AdminAuditLogRepository,
+	txManager repo.TransactionManager,
+	statusMsgSender MessageSender,
+	log *slog.Logger,
+	tracer trace.Tracer,
+) *AdminService {
+	return &AdminService{
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		auditLogRepository:     auditLogRepository,
+		txManager:              txManager,
+		statusMsgSender:        statusMsgSender,
+		log:                    log,
+		tracer:                 tracer,
+	}
+}
+
+func (s *AdminService) ListUsers(ctx context.Context, filters repo.UserFilters, pagination repo.Pagination) ([]entities.User, error) {
+	ctx, span := s.tracer.Start(ctx, "AdminService.ListUsers")
+	defer span.End()
+
+	return s.userRepository.List(ctx, &filters, &pagination)
+}
+
+func (s *AdminService) GetUser(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	ctx, span := s.tracer.Start(ctx, "AdminService.GetUser")
+	defer span.End()
+
+	return s.userRepository.GetByID(ctx, userID)
+}
+
+// ConfirmUser confirms the email of the user without a register token.
+func (s *AdminService) ConfirmUser(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "AdminService.ConfirmUser")
+	defer span.End()
+
+	return s.updateUser(ctx, actorID, userID, entities.AdminActionConfirmUser, func(ctx context.Context, user *entities.User) error {
+		user.ForceConfirm()
+
+		return nil
+	})
+}
+
+// SuspendUser blocks logins of the user until the given time and revokes all
+// of its sessions.
+func (s *AdminService) SuspendUser(ctx context.Context, actorID uuid.UUID, userID uuid.UUID, reason string, until time.Time) error {
+	ctx, span := s.tracer.Start(ctx, "AdminService.SuspendUser")
+	defer span.End()
+
+	if actorID == userID {
+		return errors.Wrap(ErrAdminSelfAction, "admin can not suspend own account")
+	}
+
+	return s.updateUser(ctx, actorID, userID, entities.AdminActionSuspendUser, func(ctx context.Context, user *entities.User) error {
+		if err := user.Suspend(reason, until); err != nil {
+			return err
+		}
+
+		if err := s.refreshTokenRepository.DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+
+		return s.sendStatusMessage(ctx, user)
+	})
+}
+
+// DisableUser blocks logins of the user until it is enabled and revokes all
+// of its sessions.
+func (s *AdminService) DisableUser(ctx context.Context, actorID uuid.UUID, userID uuid.UUID, reason string) error {
+	ctx, span := s.tracer.Start(ctx, "AdminService.DisableUser")
+	defer span.End()
+
+	if actorID == userID {
+		return errors.Wrap(ErrAdminSelfAction, "admin can not disable own account")
+	}
+
+	return s.updateUser(ctx, actorID, userID, entities.AdminActionDisableUser, func(ctx context.Context, user *entities.User) error {
+		user.Disable(reason)
+
+		if err := s.refreshTokenRepository.DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+
+		return s.sendStatusMessage(ctx, user)
+	})
+}
+
+// EnableUser lifts a suspension or a disable of the user.
+func (s *AdminService) EnableUser(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "AdminService.EnableUser")
+	defer span.End()
+
+	return s.updateUser(ctx, actorID, userID, entities.AdminActionEnableUser, func(ctx context.Context, user *entities.User) error {
+		user.Enable()
+
+		return s.sendStatusMessage(ctx, user)
+	})
+}
+
+// LogoutUser revokes refresh tokens of the user, including the ones issued to
+// OAuth clients. Access tokens already issued stay valid until they expire.
+func (s *AdminService) LogoutUser(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "AdminService.LogoutUser")
+	defer span.End()
+
+	return s.updateUser(ctx, actorID, userID, entities.AdminActionLogoutUser, func(ctx context.Context, user *entities.User) error {
+		user.RevokeRefreshToken()
+
+		return s.refreshTokenRepository.DeleteByUserID(ctx, userID)
+	})
+}
+
+// DeleteUser soft-deletes the user, the account is kept for the audit log.
+func (s *AdminService) DeleteUser(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "AdminService.DeleteUser")
+	defer span.End()
+
+	if actorID == userID {
+		return errors.Wrap(ErrAdminSelfAction, "admin can not delete own account")
+	}
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepository.GetByID(ctx, userID); err != nil {
+			return err
+		}
+
+		if err := s.refreshTokenRepository.DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+
+		if err := s.userRepository.Delete(ctx, userID); err != nil {
+			return err
+		}
+
+		return s.auditLogRepository.Create(ctx, entities.NewAdminAuditRecord(actorID, entities.AdminActionDeleteUser, userID))
+	}); err != nil {
+		return err
+	}
+
+	s.log.Info("admin action", slog.String("action", entities.AdminActionDeleteUser), slog.String("actor_id", actorID.String()), slog.String("user_id", userID.String()))
+
+	return nil
+}
+
+func (s *AdminService) updateUser(
+	ctx context.Context,
+	actorID uuid.UUID,
+	userID uuid.UUID,
+	action string,
+	update func(ctx context.Context, user *entities.User) error,
+) error {
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := update(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+
+		return s.auditLogRepository.Create(ctx, entities.NewAdminAuditRecord(actorID, action, userID))
+	}); err != nil {
+		return err
+	}
+
+	s.log.Info("admin action", slog.String("action", action), slog.String("actor_id", actorID.String()), slog.String("user_id", userID.String()))
+
+	return nil
+}
+
+func (s *AdminService) sendStatusMessage(ctx context.Context, user *entities.User) error {
+	statusMsg := UserStatusMessage{
+		UserID: user.ID(),
+		Email:  user.Email(),
+		Status: string(user.Status()),
+		Reason: user.StatusReason(),
+		Until:  user.StatusUntil(),
+	}
+
+	return s.statusMsgSender.SendMessage(ctx, statusMsg)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/stretchr/testify/require"
+)
+
+type adminServiceTest struct {
+	*authServiceTest
+	admin          *AdminService
+	auditLog       *fakeAdminAuditLogRepository
+	statusMessages *messageRecorder
+	actorID        uuid.UUID
+}
+
+func newAdminServiceTest(t *testing.T) *adminServiceTest {
+	t.Helper()
+
+	test := &adminServiceTest{
+		authServiceTest: newAuthServiceTest(t, RegistrationOpen),
+		auditLog:        &fakeAdminAuditLogRepository{},
+		statusMessages:  &messageRecorder{},
+		actorID:         uuid.New(),
+	}
+
+	test.admin = NewAdminService(
+		test.users,
+		test.refreshTokens,
+		test.auditLog,
+		fakeTxManager{},
+		test.statusMessages,
+		testLogger,
+		testTracer,
+	)
+
+	return test
+}
+
+// requireAudit checks the actions recorded for the user.
+func (tt *adminServiceTest) requireAudit(t *testing.T, userID uuid.UUID, actions ...string) {
+	t.Helper()
+
+	records, err := tt.auditLog.ListByTargetUserID(context.Background(), userID)
+	require.NoError(t, err)
+
+	recorded := make([]string, 0, len(records))
+	for _, record := range records {
+		require.Equal(t, tt.actorID, record.ActorID())
+		recorded = append(recorded, record.Action())
+	}
+
+	require.Equal(t, actions, recorded)
+}
+
+func TestAdminServiceConfirmUser(t *testing.T) {
+	ctx := context.Background()
+	tt := newAdminServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+
+	require.NoError(t, tt.admin.ConfirmUser(ctx, tt.actorID, user.ID()))
+	require.True(t, tt.getUser(t, testUserEmail).Confirmed())
+	tt.requireAudit(t, user.ID(), entities.AdminActionConfirmUser)
+
+	err := tt.admin.ConfirmUser(ctx, tt.actorID, uuid.New())
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+}
+
+func TestAdminServiceLogoutUser(t *testing.T) {
+	ctx := context.Background()
+	tt := newAdminServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+
+	result, err := tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+
+	tt.addOAuthRefreshToken(t, user)
+
+	require.NoError(t, tt.admin.LogoutUser(ctx, tt.actorID, user.ID()))
+	tt.requireAudit(t, user.ID(), entities.AdminActionLogoutUser)
+	tt.requireNoOAuthRefreshTokens(t, user)
+
+	_, _, err = tt.service.Refresh(ctx, result.RefreshToken, AccessTokenRequest{})
+	require.Error(t, err)
+}
+
+func TestAdminServiceDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	tt := newAdminServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+	tt.addOAuthRefreshToken(t, user)
+
+	err := tt.admin.DeleteUser(ctx, user.ID(), user.ID())
+	require.ErrorIs(t, err, ErrAdminSelfAction)
+
+	require.NoError(t, tt.admin.DeleteUser(ctx, tt.actorID, user.ID()))
+	tt.requireAudit(t, user.ID(), entities.AdminActionDeleteUser)
+	tt.requireNoOAuthRefreshTokens(t, user)
+
+	_, err = tt.admin.GetUser(ctx, user.ID())
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	err = tt.admin.DeleteUser(ctx, tt.actorID, user.ID())
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+}
+
+func TestAdminServiceSuspendUser(t *testing.T) {
+	ctx := context.Background()
+	tt := newAdminServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+	tt.addOAuthRefreshToken(t, user)
+
+	err := tt.admin.SuspendUser(ctx, user.ID(), user.ID(), "spam", time.Now().Add(time.Hour))
+	require.ErrorIs(t, err, ErrAdminSelfAction)
+
+	err = tt.admin.SuspendUser(ctx, tt.actorID, user.ID(), "spam", time.Now().Add(-time.Hour))
+	require.ErrorIs(t, err, domain.ErrInvalidSuspension)
+
+	require.NoError(t, tt.admin.SuspendUser(ctx, tt.actorID, user.ID(), "spam", time.Now().Add(time.Hour)))
+	tt.requireAudit(t, user.ID(), entities.AdminActionSuspendUser)
+	tt.requireNoOAuthRefreshTokens(t, user)
+
+	_, err = tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.ErrorIs(t, err, domain.ErrUserSuspended)
+
+	messages := tt.statusMessages.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, string(entities.UserStatusSuspended), messages[0].(UserStatusMessage).Status)
+	require.Equal(t, "spam", messages[0].(UserStatusMessage).Reason)
+}
+
+func TestAdminServiceDisableUser(t *testing.T) {
+	ctx := context.Background()
+	tt := newAdminServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+	tt.addOAuthRefreshToken(t, user)
+
+	err := tt.admin.DisableUser(ctx, user.ID(), user.ID(), "fraud")
+	require.ErrorIs(t, err, ErrAdminSelfAction)
+
+	require.NoError(t, tt.admin.DisableUser(ctx, tt.actorID, user.ID(), "fraud"))
+	tt.requireNoOAuthRefreshTokens(t, user)
+
+	_, err = tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.ErrorIs(t, err, domain.ErrUserDisabled)
+
+	require.NoError(t, tt.emailCodeService.SendLoginCode(ctx, testUserEmail))
+
+	_, err = tt.codeLogin(ctx, testUserEmail, tt.lastCode(t, testUserEmail, vobjects.EmailCodeLogin), AccessTokenRequest{})
+	require.ErrorIs(t, err, domain.ErrUserDisabled)
+
+	require.NoError(t, tt.admin.EnableUser(ctx, tt.actorID, user.ID()))
+	tt.requireAudit(t, user.ID(), entities.AdminActionDisableUser, entities.AdminActionEnableUser)
+
+	_, err = tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+
+	messages := tt.statusMessages.Messages()
+	require.Len(t, messages, 2)
+	require.Equal(t, string(entities.UserStatusActive), messages[1].(UserStatusMessage).Status)
+}
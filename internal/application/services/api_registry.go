@@ -0,0 +1,102 @@
+package services
+
+import (
+	"slices"
+
+	"github.com/pkg/errors"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+// UserInfoPath is the path of the OpenID Connect userinfo endpoint, tokens
+// issued to OAuth clients are issued for it.
+const UserInfoPath = "/oauth/userinfo"
+
+// API is a resource server access tokens can be issued for.
+type API struct {
+	Audience string
+	Scopes   vobjects.Scopes
+}
+
+// APIRegistry validates the audience and scopes requested for an access
+// token. A token requested without an audience is issued for the service
+// itself and has no scopes.
+type APIRegistry struct {
+	issuer string
+	apis   map[string]API
+}
+
+func NewAPIRegistry(issuer string, apis []API) *APIRegistry {
+	registry := &APIRegistry{
+		issuer: issuer,
+		apis:   make(map[string]API, len(apis)),
+	}
+
+	for _, api := range apis {
+		registry.apis[api.Audience] = api
+	}
+
+	return registry
+}
+
+func (r *APIRegistry) Get(audience string) (API, bool) {
+	api, ok := r.apis[audience]
+	return api, ok
+}
+
+// DefaultGrant returns the grant of a token for the service itself.
+func (r *APIRegistry) DefaultGrant() vobjects.AccessTokenGrant {
+	return vobjects.AccessTokenGrant{
+		Audience: []string{r.issuer},
+	}
+}
+
+// UserInfoAudience returns the audience of the userinfo endpoint.
+func (r *APIRegistry) UserInfoAudience() string {
+	return r.issuer + UserInfoPath
+}
+
+// DelegatedGrant returns the grant of a token issued to an OAuth client on
+// behalf of a user. The token is issued for the userinfo endpoint and the
+// apis the scopes belong to, never for the service itself.
+func (r *APIRegistry) DelegatedGrant(scopes vobjects.Scopes) vobjects.AccessTokenGrant {
+	audience := make([]string, 0, len(r.apis))
+	for _, api := range r.apis {
+		if len(scopes.Intersect(api.Scopes)) != 0 {
+			audience = append(audience, api.Audience)
+		}
+	}
+
+	slices.Sort(audience)
+
+	return vobjects.AccessTokenGrant{
+		Audience: append([]string{r.UserInfoAudience()}, audience...),
+		Scopes:   scopes,
+	}
+}
+
+// Grant validates a requested audience and space-delimited scope.
+func (r *APIRegistry) Grant(audience string, scope string) (vobjects.AccessTokenGrant, error) {
+	scopes := vobjects.ParseScopes(scope)
+
+	if audience == "" {
+		if len(scopes) != 0 {
+			return vobjects.AccessTokenGrant{}, errors.Wrap(ErrInvalidScope, "scope requires an audience")
+		}
+
+		return r.DefaultGrant(), nil
+	}
+
+	api, ok := r.Get(audience)
+	if !ok {
+		return vobjects.AccessTokenGrant{}, errors.Wrapf(ErrInvalidAudience, "api %s is not registered", audience)
+	}
+
+	if !scopes.SubsetOf(api.Scopes) {
+		return vobjects.AccessTokenGrant{}, errors.Wrapf(ErrInvalidScope, "scope %s is not allowed for api %s", scope, audience)
+	}
+
+	return vobjects.AccessTokenGrant{
+		Audience: []string{api.Audience},
+		Scopes:   scopes,
+	}, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"testing"
+
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIRegistryDelegatedGrant(t *testing.T) {
+	registry := NewAPIRegistry("https://auth.example.com", []API{
+		{Audience: "https://orders.example.com", Scopes: vobjects.Scopes{"orders:read", "orders:write"}},
+		{Audience: "https://billing.example.com", Scopes: vobjects.Scopes{"billing:read"}},
+	})
+
+	tests := []struct {
+		name     string
+		scopes   vobjects.Scopes
+		audience []string
+	}{
+		{
+			name:     "openid only",
+			scopes:   vobjects.Scopes{vobjects.ScopeOpenID},
+			audience: []string{"https://auth.example.com/oauth/userinfo"},
+		},
+		{
+			name:     "api scope",
+			scopes:   vobjects.Scopes{vobjects.ScopeOpenID, "orders:read"},
+			audience: []string{"https://auth.example.com/oauth/userinfo", "https://orders.example.com"},
+		},
+		{
+			name:     "several apis",
+			scopes:   vobjects.Scopes{"orders:write", "billing:read"},
+			audience: []string{"https://auth.example.com/oauth/userinfo", "https://billing.example.com", "https://orders.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grant := registry.DelegatedGrant(tt.scopes)
+			require.Equal(t, tt.audience, grant.Audience)
+			require.Equal(t, tt.scopes, grant.Scopes)
+			require.NotContains(t, grant.Audience, "https://auth.example.com")
+		})
+	}
+}
+
+func TestAPIRegistryGrant(t *testing.T) {
+	registry := NewAPIRegistry("https://auth.example.com", []API{
+		{Audience: "https://orders.example.com", Scopes: vobjects.Scopes{"orders:read", "orders:write"}},
+	})
+
+	tests := []struct {
+		name     string
+		audience string
+		scope    string
+		grant    vobjects.AccessTokenGrant
+		err      error
+	}{
+		{
+			name:  "default",
+			grant: vobjects.AccessTokenGrant{Audience: []string{"https://auth.example.com"}},
+		},
+		{
+			name:     "api",
+			audience: "https://orders.example.com",
+			scope:    "orders:read",
+			grant: vobjects.AccessTokenGrant{
+				Audience: []string{"https://orders.example.com"},
+				Scopes:   vobjects.Scopes{"orders:read"},
+			},
+		},
+		{
+			name:  "scope without audience",
+			scope: "orders:read",
+			err:   ErrInvalidScope,
+		},
+		{
+			name:     "unknown audience",
+			audience: "https://billing.example.com",
+			err:      ErrInvalidAudience,
+		},
+		{
+			name:     "scope of another api",
+			audience: "https://orders.example.com",
+			scope:    "orders:read billing:read",
+			err:      ErrInvalidScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grant, err := registry.Grant(tt.audience, tt.scope)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.grant, grant)
+		})
+	}
+}
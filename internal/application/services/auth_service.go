@@ -2,110 +2,207 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	repo "github.com/rozhnof/auth-service/internal/application/repository"
-	"github.com/rozhnof/auth-service/internal/domain"
 	"github.com/rozhnof/auth-service/internal/domain/entities"
 	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/pkg/webauthn"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type AuthServiceConfig struct {
-	AccessTokenTTL  time.Duration
-	RefreshTokenTTL time.Duration
+	Issuer                string
+	AccessTokenTTL        time.Duration
+	RefreshTokenTTL       time.Duration
+	AutoLinkVerifiedEmail bool
+}
+
+// AccessTokenRequest is the audience and the space-delimited scope requested
+// for an access token at login or refresh, both are optional.
+type AccessTokenRequest struct {
+	Audience string
+	Scope    string
+}
+
+// LoginResult holds the tokens of a completed login, or the MFA challenge
+// token when the user has to pass a second factor first.
+type LoginResult struct {
+	AccessToken  string
+	RefreshToken string
+	MFAToken     string
+}
+
+func (r LoginResult) MFARequired() bool {
+	return r.MFAToken != ""
 }
 
 type AuthService struct {
-	repository        repo.UserRepository
-	txManager         repo.TransactionManager
-	secretManager     SecretManager
-	loginMsgSender    MessageSender
-	registerMsgSender MessageSender
-	log               *slog.Logger
-	tracer            trace.Tracer
-	cfg               AuthServiceConfig
+	repository             repo.UserRepository
+	identityRepository     repo.UserIdentityRepository
+	roleRepository         repo.RoleRepository
+	refreshTokenRepository repo.OAuthRefreshTokenRepository
+	txManager              repo.TransactionManager
+	secretManager          SecretManager
+	loginMsgSender         MessageSender
+	apiRegistry            *APIRegistry
+	mfaService             *MFAService
+	webauthnService        *WebAuthnService
+	log                    *slog.Logger
+	tracer                 trace.Tracer
+	cfg                    AuthServiceConfig
 }
 
 func NewAuthService(
 	repository repo.UserRepository,
+	identityRepository repo.UserIdentityRepository,
+	roleRepository repo.RoleRepository,
+	refreshTokenRepository repo.OAuthRefreshTokenRepository,
 	txManager repo.TransactionManager,
 	secretManager SecretManager,
 	loginMsgSender MessageSender,
-	registerMsgSender MessageSender,
+	apiRegistry *APIRegistry,
+	mfaService *MFAService,
+	webauthnService *WebAuthnService,
 	log *slog.Logger,
 	tracer trace.Tracer,
 	cfg AuthServiceConfig,
 ) *AuthService {
 	return &AuthService{
-		repository:        repository,
-		txManager:         txManager,
-		secretManager:     secretManager,
-		loginMsgSender:    loginMsgSender,
-		registerMsgSender: registerMsgSender,
-		log:               log,
-		tracer:            tracer,
-		cfg:               cfg,
+		repository:             repository,
+		identityRepository:     identityRepository,
+		roleRepository:         roleRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		txManager:              txManager,
+		secretManager:          secretManager,
+		loginMsgSender:         loginMsgSender,
+		apiRegistry:            apiRegistry,
+		mfaService:             mfaService,
+		webauthnService:        webauthnService,
+		log:                    log,
+		tracer:                 tracer,
+		cfg:                    cfg,
 	}
 }
 
-func (s *AuthService) OAuthLogin(ctx context.Context, email string) (*entities.User, error) {
+// OAuthLogin logs in the user linked to the provider identity. An identity is
+// linked to an existing account with the same email only when the provider
+// asserts the email is verified and auto-linking is enabled, otherwise the
+// user has to link it explicitly after logging in, an unconfirmed account is
+// reclaimed as by a passwordless login. Without an account ErrUserNotSignedUp
+// is returned, the user is signed up with RegistrationService.SignUpOAuth. As
+// with a password, a user with MFA enabled gets an MFA challenge token.
+func (s *AuthService) OAuthLogin(ctx context.Context, oauthUser OAuthUser) (*entities.User, *LoginResult, error) {
 	ctx, span := s.tracer.Start(ctx, "AuthService.OAuthLogin")
 	defer span.End()
 
-	if user, err := s.repository.GetByEmail(ctx, email); err == nil {
-		if err := user.RefreshTokens(s.cfg.AccessTokenTTL, s.cfg.RefreshTokenTTL, s.secretManager.SecretKey().Get()); err != nil {
-			return nil, err
+	identity, err := s.identityRepository.GetByProviderSubject(ctx, oauthUser.Provider, oauthUser.Subject)
+	if err == nil {
+		user, err := s.repository.GetByID(ctx, identity.UserID())
+		if err != nil {
+			return nil, nil, err
 		}
 
-		if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
-			if err := s.repository.Update(ctx, user); err != nil {
-				return err
-			}
+		result, err := s.oauthLoginExisting(ctx, user, nil)
+		if err != nil {
+			return nil, nil, err
+		}
 
-			loginMsg := LoginMessage{
-				Email: email,
-			}
+		return user, result, nil
+	}
 
-			if err := s.loginMsgSender.SendMessage(ctx, loginMsg); err != nil {
-				return err
-			}
+	if !errors.Is(err, repo.ErrObjectNotFound) {
+		return nil, nil, err
+	}
 
-			return nil
-		}); err != nil {
-			return nil, err
+	user, err := s.repository.GetByEmail(ctx, oauthUser.Email)
+	if err == nil {
+		if !s.cfg.AutoLinkVerifiedEmail || !oauthUser.EmailVerified {
+			return nil, nil, errors.Wrapf(ErrIdentityNotLinked, "user with email = %s exists, %s identity must be linked explicitly", oauthUser.Email, oauthUser.Provider)
+		}
+
+		identity := entities.NewUserIdentity(user.ID(), oauthUser.Provider, oauthUser.Subject, oauthUser.Email)
+
+		result, err := s.oauthLoginExisting(ctx, user, identity)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		return user, nil
+		return user, result, nil
 	}
 
-	const randomPasswordLen = 72
+	if !errors.Is(err, repo.ErrObjectNotFound) {
+		return nil, nil, err
+	}
 
-	password, err := vobjects.NewPassword(domain.GenerateRandomString(randomPasswordLen))
-	if err != nil {
+	return nil, nil, errors.Wrapf(ErrUserNotSignedUp, "user with %s identity or email = %s not exists", oauthUser.Provider, oauthUser.Email)
+}
+
+func (s *AuthService) oauthLoginExisting(ctx context.Context, user *entities.User, newIdentity *entities.UserIdentity) (*LoginResult, error) {
+	if err := user.CheckStatus(); err != nil {
 		return nil, err
 	}
 
-	user := entities.NewUser(email, password)
+	var result *LoginResult
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if newIdentity != nil {
+			if !user.Confirmed() {
+				if err := s.reclaim(ctx, user); err != nil {
+					return err
+				}
+			}
+
+			if err := s.identityRepository.Create(ctx, newIdentity); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		result, err = s.completeLogin(ctx, user, s.apiRegistry.DefaultGrant(), AccessTokenRequest{})
 
-	if err := user.RefreshTokens(s.cfg.AccessTokenTTL, s.cfg.RefreshTokenTTL, s.secretManager.SecretKey().Get()); err != nil {
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
+	return result, nil
+}
+
+// LinkIdentity links a provider identity to an authenticated user. Linking an
+// identity that is already linked to the same user is a no-op.
+func (s *AuthService) LinkIdentity(ctx context.Context, userID uuid.UUID, oauthUser OAuthUser) (*entities.UserIdentity, error) {
+	ctx, span := s.tracer.Start(ctx, "AuthService.LinkIdentity")
+	defer span.End()
+
+	var identity *entities.UserIdentity
+
 	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
-		if err := s.repository.Create(ctx, user); err != nil {
+		existing, err := s.identityRepository.GetByProviderSubject(ctx, oauthUser.Provider, oauthUser.Subject)
+		if err == nil {
+			if existing.UserID() != userID {
+				return errors.Wrapf(ErrIdentityLinkedToOtherUser, "%s identity is linked to another user", oauthUser.Provider)
+			}
+
+			identity = existing
+
+			return nil
+		}
+
+		if !errors.Is(err, repo.ErrObjectNotFound) {
 			return err
 		}
 
-		registerMsg := RegisterMessage{
-			Email:       user.Email(),
-			ConfirmLink: createConfirmLink(user.Email(), user.RegisterToken().Token()),
+		if _, err := s.repository.GetByID(ctx, userID); err != nil {
+			return err
 		}
 
-		if err := s.registerMsgSender.SendMessage(ctx, registerMsg); err != nil {
+		identity = entities.NewUserIdentity(userID, oauthUser.Provider, oauthUser.Subject, oauthUser.Email)
+
+		if err := s.identityRepository.Create(ctx, identity); err != nil {
 			return err
 		}
 
@@ -114,97 +211,303 @@ func (s *AuthService) OAuthLogin(ctx context.Context, email string) (*entities.U
 		return nil, err
 	}
 
-	return user, nil
+	return identity, nil
+}
+
+// UnlinkIdentity removes a provider identity from the user. The last identity
+// of a passwordless user can't be removed, since the user would be locked out.
+func (s *AuthService) UnlinkIdentity(ctx context.Context, userID uuid.UUID, provider string) error {
+	ctx, span := s.tracer.Start(ctx, "AuthService.UnlinkIdentity")
+	defer span.End()
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.repository.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if !user.HasPassword() {
+			identities, err := s.identityRepository.ListByUserID(ctx, userID)
+			if err != nil {
+				return err
+			}
+
+			if len(identities) <= 1 {
+				return errors.Wrapf(ErrLastLoginMethod, "%s identity is the only login method of user", provider)
+			}
+		}
+
+		return s.identityRepository.Delete(ctx, userID, provider)
+	})
+}
+
+// SetPassword lets a passwordless user opt into password login.
+func (s *AuthService) SetPassword(ctx context.Context, userID uuid.UUID, passwordStr string) error {
+	ctx, span := s.tracer.Start(ctx, "AuthService.SetPassword")
+	defer span.End()
+
+	password, err := vobjects.NewPassword(passwordStr)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.repository.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := user.SetPassword(password); err != nil {
+			return err
+		}
+
+		return s.repository.Update(ctx, user)
+	})
 }
 
-func (s *AuthService) Confirm(ctx context.Context, email string, token string) error {
-	ctx, span := s.tracer.Start(ctx, "AuthService.Confirm")
+func (s *AuthService) ListIdentities(ctx context.Context, userID uuid.UUID) ([]entities.UserIdentity, error) {
+	ctx, span := s.tracer.Start(ctx, "AuthService.ListIdentities")
+	defer span.End()
+
+	return s.identityRepository.ListByUserID(ctx, userID)
+}
+
+// Login checks the password of the user. A user with MFA enabled gets an MFA
+// challenge token instead of tokens, the login is completed with
+// CompleteMFAChallenge.
+func (s *AuthService) Login(ctx context.Context, email string, password string, tokenRequest AccessTokenRequest) (*LoginResult, error) {
+	ctx, span := s.tracer.Start(ctx, "AuthService.Login")
 	defer span.End()
 
+	grant, err := s.apiRegistry.Grant(tokenRequest.Audience, tokenRequest.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *LoginResult
+
 	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
 		user, err := s.repository.GetByEmail(ctx, email)
 		if err != nil {
 			return err
 		}
 
-		if err := user.Confirm(); err != nil {
-			return err
+		if !user.CheckPassword(password) {
+			return ErrInvalidPassword
 		}
 
-		if err := s.repository.Update(ctx, user); err != nil {
+		if err := user.CheckStatus(); err != nil {
 			return err
 		}
 
-		return nil
-	}); err != nil {
+		result, err = s.completeLogin(ctx, user, grant, tokenRequest)
+
 		return err
+	}); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return result, nil
 }
 
-func (s *AuthService) Register(ctx context.Context, email string, passwordStr string) (*entities.User, error) {
-	ctx, span := s.tracer.Start(ctx, "AuthService.Register")
+// CheckAccessTokenRequest checks the audience and scope requested for an
+// access token, so a single-use proof of a login isn't spent on a request
+// that fails anyway.
+func (s *AuthService) CheckAccessTokenRequest(tokenRequest AccessTokenRequest) error {
+	_, err := s.apiRegistry.Grant(tokenRequest.Audience, tokenRequest.Scope)
+
+	return err
+}
+
+// PasswordlessLogin logs in a user who proved to own the email with a magic
+// link or a login code and confirms the email, an unconfirmed account is
+// reclaimed first. Without an account ErrUserNotSignedUp is returned, the user
+// is signed up with RegistrationService.SignUpPasswordless. As with a
+// password, a user with MFA enabled gets an MFA challenge token.
+func (s *AuthService) PasswordlessLogin(ctx context.Context, email string, tokenRequest AccessTokenRequest) (*LoginResult, error) {
+	ctx, span := s.tracer.Start(ctx, "AuthService.PasswordlessLogin")
 	defer span.End()
 
-	var user *entities.User
+	grant, err := s.apiRegistry.Grant(tokenRequest.Audience, tokenRequest.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *LoginResult
 
 	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
-		password, err := vobjects.NewPassword(passwordStr)
+		user, err := s.repository.GetByEmail(ctx, email)
 		if err != nil {
+			if errors.Is(err, repo.ErrObjectNotFound) {
+				return errors.Wrapf(ErrUserNotSignedUp, "user with email = %s not exists", email)
+			}
+
 			return err
 		}
 
-		user = entities.NewUser(email, password)
-
-		if err := s.repository.Create(ctx, user); err != nil {
+		if err := user.CheckStatus(); err != nil {
 			return err
 		}
 
-		registerMsg := RegisterMessage{
-			Email:       user.Email(),
-			ConfirmLink: createConfirmLink(user.Email(), user.RegisterToken().Token()),
+		if !user.Confirmed() {
+			if err := s.reclaim(ctx, user); err != nil {
+				return err
+			}
 		}
 
-		if err := s.registerMsgSender.SendMessage(ctx, registerMsg); err != nil {
+		result, err = s.completeLogin(ctx, user, grant, tokenRequest)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// reclaim hands an unconfirmed account over to the owner of the email. Anyone
+// could sign up with the email before it was confirmed and add credentials to
+// the account, so all of them are dropped: the password, linked identities,
+// passkeys, the TOTP authenticator and sessions, including the ones of OAuth
+// clients.
+func (s *AuthService) reclaim(ctx context.Context, user *entities.User) error {
+	identities, err := s.identityRepository.ListByUserID(ctx, user.ID())
+	if err != nil {
+		return err
+	}
+
+	for _, identity := range identities {
+		if err := s.identityRepository.Delete(ctx, user.ID(), identity.Provider()); err != nil {
 			return err
 		}
+	}
 
-		return nil
-	}); err != nil {
+	if err := s.webauthnService.DeleteCredentials(ctx, user.ID()); err != nil {
+		return err
+	}
+
+	if err := s.mfaService.ResetTOTP(ctx, user.ID()); err != nil {
+		return err
+	}
+
+	if err := s.refreshTokenRepository.DeleteByUserID(ctx, user.ID()); err != nil {
+		return err
+	}
+
+	user.Reclaim()
+
+	if err := s.repository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.log.Info("unconfirmed account reclaimed", slog.String("email", user.Email()))
+
+	return nil
+}
+
+// completeLogin issues tokens to a user who passed the first factor, or
+// creates an MFA challenge when the user has MFA enabled.
+func (s *AuthService) completeLogin(ctx context.Context, user *entities.User, grant vobjects.AccessTokenGrant, tokenRequest AccessTokenRequest) (*LoginResult, error) {
+	mfaEnabled, err := s.mfaService.Enabled(ctx, user.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	if mfaEnabled {
+		challenge, err := s.mfaService.CreateChallenge(ctx, user.ID(), tokenRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		return &LoginResult{MFAToken: challenge.Token()}, nil
+	}
+
+	if err := s.issueTokens(ctx, user, grant); err != nil {
 		return nil, err
 	}
 
-	return user, nil
+	result := LoginResult{
+		AccessToken:  user.AccessToken().Token(),
+		RefreshToken: user.RefreshToken().Token(),
+	}
+
+	return &result, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, email string, password string) (at string, rt string, err error) {
-	ctx, span := s.tracer.Start(ctx, "AuthService.Login")
+// CompleteMFAChallenge completes a login with the second factor, the tokens
+// are issued for the audience and scope requested at login.
+func (s *AuthService) CompleteMFAChallenge(ctx context.Context, mfaToken string, proof MFAProof) (at string, rt string, err error) {
+	ctx, span := s.tracer.Start(ctx, "AuthService.CompleteMFAChallenge")
 	defer span.End()
 
+	challenge, err := s.mfaService.PassChallenge(ctx, mfaToken, proof)
+	if err != nil {
+		return "", "", err
+	}
+
+	grant, err := s.apiRegistry.Grant(challenge.Audience(), challenge.Scope())
+	if err != nil {
+		return "", "", err
+	}
+
 	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
-		user, err := s.repository.GetByEmail(ctx, email)
+		user, err := s.repository.GetByID(ctx, challenge.UserID())
 		if err != nil {
 			return err
 		}
 
-		if !user.Password().Compare(password) {
-			return ErrInvalidPassword
+		if err := user.CheckStatus(); err != nil {
+			return err
 		}
 
-		if err := user.RefreshTokens(s.cfg.AccessTokenTTL, s.cfg.RefreshTokenTTL, s.secretManager.SecretKey().Get()); err != nil {
+		if err := s.issueTokens(ctx, user, grant); err != nil {
 			return err
 		}
 
-		if err := s.repository.Update(ctx, user); err != nil {
+		at = user.AccessToken().Token()
+		rt = user.RefreshToken().Token()
+
+		return nil
+	}); err != nil {
+		return "", "", err
+	}
+
+	return at, rt, nil
+}
+
+// WebAuthnLogin logs in the user with a passkey. The passkey verifies the
+// user, so no MFA challenge follows. The assertion is verified before the
+// transaction, since it uses up the challenge, which a retried transaction
+// would not find again.
+func (s *AuthService) WebAuthnLogin(ctx context.Context, response webauthn.AssertionResponse, tokenRequest AccessTokenRequest) (at string, rt string, err error) {
+	ctx, span := s.tracer.Start(ctx, "AuthService.WebAuthnLogin")
+	defer span.End()
+
+	grant, err := s.apiRegistry.Grant(tokenRequest.Audience, tokenRequest.Scope)
+	if err != nil {
+		return "", "", err
+	}
+
+	assertion, err := s.webauthnService.FinishLogin(ctx, response)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.webauthnService.UseCredential(ctx, assertion); err != nil {
 			return err
 		}
 
-		loginMsg := LoginMessage{
-			Email: email,
+		user, err := s.repository.GetByID(ctx, assertion.UserID)
+		if err != nil {
+			return err
 		}
 
-		if err := s.loginMsgSender.SendMessage(ctx, loginMsg); err != nil {
+		if err := user.CheckStatus(); err != nil {
+			return err
+		}
+
+		if err := s.issueTokens(ctx, user, grant); err != nil {
 			return err
 		}
 
@@ -219,10 +522,36 @@ func (s *AuthService) Login(ctx context.Context, email string, password string)
 	return at, rt, nil
 }
 
-func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (at string, rt string, err error) {
+// issueTokens issues a new token pair to the user and records the login.
+func (s *AuthService) issueTokens(ctx context.Context, user *entities.User, grant vobjects.AccessTokenGrant) error {
+	if err := s.loadRoles(ctx, user); err != nil {
+		return err
+	}
+
+	if err := user.RefreshTokens(s.cfg.Issuer, s.cfg.AccessTokenTTL, s.cfg.RefreshTokenTTL, s.secretManager.SecretKey().Get(), grant); err != nil {
+		return err
+	}
+
+	if err := s.repository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	loginMsg := LoginMessage{
+		Email: user.Email(),
+	}
+
+	return s.loginMsgSender.SendMessage(ctx, loginMsg)
+}
+
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string, tokenRequest AccessTokenRequest) (at string, rt string, err error) {
 	ctx, span := s.tracer.Start(ctx, "AuthService.Refresh")
 	defer span.End()
 
+	grant, err := s.apiRegistry.Grant(tokenRequest.Audience, tokenRequest.Scope)
+	if err != nil {
+		return "", "", err
+	}
+
 	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
 		user, err := s.repository.GetByRefreshToken(ctx, refreshToken)
 		if err != nil {
@@ -233,7 +562,15 @@ func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (at stri
 			return errors.Wrap(ErrUnauthorizedRefresh, "invalid refresh token")
 		}
 
-		if err := user.RefreshTokens(s.cfg.AccessTokenTTL, s.cfg.RefreshTokenTTL, s.secretManager.SecretKey().Get()); err != nil {
+		if err := user.CheckStatus(); err != nil {
+			return err
+		}
+
+		if err := s.loadRoles(ctx, user); err != nil {
+			return err
+		}
+
+		if err := user.RefreshTokens(s.cfg.Issuer, s.cfg.AccessTokenTTL, s.cfg.RefreshTokenTTL, s.secretManager.SecretKey().Get(), grant); err != nil {
 			return err
 		}
 
@@ -252,6 +589,14 @@ func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (at stri
 	return at, rt, nil
 }
 
-func createConfirmLink(email string, token string) string {
-	return fmt.Sprintf("http://localhost:8080/auth/confirm?email=%s&register_token=%s", email, token)
+// loadRoles sets roles of the user, so they get into the access token.
+func (s *AuthService) loadRoles(ctx context.Context, user *entities.User) error {
+	roles, err := s.roleRepository.ListByUserID(ctx, user.ID())
+	if err != nil {
+		return err
+	}
+
+	user.SetRoles(roles)
+
+	return nil
 }
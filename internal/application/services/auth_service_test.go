@@ -0,0 +1,646 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer             = "https://auth.example.com"
+	testEmailCodeAttempts  = 3
+	testUserPassword       = "test-password"
+	testUserEmail          = "user@example.com"
+	testAccessTokenTTL     = 15 * time.Minute
+	testRefreshTokenTTL    = 24 * time.Hour
+	testEmailThrottleLimit = 100
+)
+
+type authServiceTest struct {
+	service             *AuthService
+	registrationService *RegistrationService
+	emailCodeService    *EmailCodeService
+	users               *fakeUserRepository
+	identities          *fakeUserIdentityRepository
+	roles               *fakeRoleRepository
+	refreshTokens       *fakeOAuthRefreshTokenRepository
+	totps               *fakeUserTOTPRepository
+	credentials         *fakeWebAuthnCredentialRepository
+	magicLinks          *fakeMagicLinkRepository
+	emailCodes          *fakeEmailCodeRepository
+	invitations         *fakeInvitationRepository
+	invitationService   *InvitationService
+	magicLinkService    *MagicLinkService
+	referralCodes       *fakeReferralCodeRepository
+	referralService     *ReferralService
+	referralMessages    *messageRecorder
+	loginMessages       *messageRecorder
+	codeMessages        *messageRecorder
+	clock               domain.Clock
+}
+
+func newAuthServiceTest(t *testing.T, mode RegistrationMode) *authServiceTest {
+	t.Helper()
+
+	test := &authServiceTest{
+		users:            newFakeUserRepository(),
+		identities:       &fakeUserIdentityRepository{},
+		roles:            newFakeRoleRepository(),
+		refreshTokens:    newFakeOAuthRefreshTokenRepository(),
+		totps:            newFakeUserTOTPRepository(),
+		credentials:      &fakeWebAuthnCredentialRepository{},
+		magicLinks:       newFakeMagicLinkRepository(),
+		emailCodes:       newFakeEmailCodeRepository(),
+		invitations:      newFakeInvitationRepository(),
+		referralCodes:    &fakeReferralCodeRepository{},
+		referralMessages: &messageRecorder{},
+		loginMessages:    &messageRecorder{},
+		codeMessages:     &messageRecorder{},
+		clock:            domain.SystemClock{},
+	}
+
+	emailThrottle := NewEmailThrottle(newFakeEmailThrottleRepository(), EmailThrottleConfig{
+		Limit:  testEmailThrottleLimit,
+		Window: time.Hour,
+	})
+
+	webauthnService := NewWebAuthnService(
+		test.users,
+		test.credentials,
+		nil,
+		nil,
+		test.clock,
+		testLogger,
+		testTracer,
+		WebAuthnServiceConfig{},
+	)
+
+	mfaService := NewMFAService(
+		test.users,
+		test.totps,
+		newFakeMFARecoveryCodeRepository(),
+		newFakeMFAChallengeRepository(),
+		webauthnService,
+		fakeTxManager{},
+		plainCipher{},
+		&messageRecorder{},
+		test.clock,
+		testLogger,
+		testTracer,
+		MFAServiceConfig{
+			ChallengeTTL: time.Minute,
+			MaxAttempts:  testMFAMaxAttempts,
+		},
+	)
+
+	test.magicLinkService = NewMagicLinkService(
+		test.magicLinks,
+		allowAllEmailPolicy{},
+		emailThrottle,
+		&messageRecorder{},
+		testLogger,
+		testTracer,
+		MagicLinkServiceConfig{
+			Issuer: testIssuer,
+			TTL:    time.Minute,
+		},
+	)
+
+	test.emailCodeService = NewEmailCodeService(
+		test.emailCodes,
+		allowAllEmailPolicy{},
+		emailThrottle,
+		test.codeMessages,
+		testLogger,
+		testTracer,
+		EmailCodeServiceConfig{
+			Length:      6,
+			TTL:         time.Minute,
+			MaxAttempts: testEmailCodeAttempts,
+		},
+	)
+
+	test.referralService = NewReferralService(
+		test.referralCodes,
+		&fakeReferralRepository{},
+		test.referralMessages,
+		testLogger,
+		testTracer,
+		ReferralServiceConfig{
+			CodeTTL: time.Hour,
+		},
+	)
+
+	test.invitationService = NewInvitationService(
+		test.invitations,
+		test.users,
+		test.roles,
+		fakeTxManager{},
+		allowAllEmailPolicy{},
+		emailThrottle,
+		&messageRecorder{},
+		testLogger,
+		testTracer,
+		InvitationServiceConfig{
+			Mode: mode,
+			TTL:  time.Hour,
+		},
+	)
+
+	test.service = NewAuthService(
+		test.users,
+		test.identities,
+		test.roles,
+		test.refreshTokens,
+		fakeTxManager{},
+		testSecretManager{},
+		test.loginMessages,
+		NewAPIRegistry(testIssuer, nil),
+		mfaService,
+		webauthnService,
+		testLogger,
+		testTracer,
+		AuthServiceConfig{
+			Issuer:                testIssuer,
+			AccessTokenTTL:        testAccessTokenTTL,
+			RefreshTokenTTL:       testRefreshTokenTTL,
+			AutoLinkVerifiedEmail: true,
+		},
+	)
+
+	test.registrationService = NewRegistrationService(
+		test.users,
+		test.identities,
+		fakeTxManager{},
+		&messageRecorder{},
+		allowAllEmailPolicy{},
+		emailThrottle,
+		test.emailCodeService,
+		test.referralService,
+		test.invitationService,
+		testLogger,
+		testTracer,
+	)
+
+	return test
+}
+
+// codeLogin logs in with a login code, like AuthHandler.CodeLogin does.
+func (tt *authServiceTest) codeLogin(ctx context.Context, email string, code string, tokenRequest AccessTokenRequest) (*LoginResult, error) {
+	if err := tt.service.CheckAccessTokenRequest(tokenRequest); err != nil {
+		return nil, err
+	}
+
+	if err := tt.emailCodeService.VerifyLoginCode(ctx, email, code); err != nil {
+		return nil, err
+	}
+
+	return tt.passwordlessLogin(ctx, email, tokenRequest)
+}
+
+// magicLinkLogin logs in with a magic link, like AuthHandler.VerifyMagicLink
+// does.
+func (tt *authServiceTest) magicLinkLogin(ctx context.Context, token string, tokenRequest AccessTokenRequest) (*LoginResult, error) {
+	if err := tt.service.CheckAccessTokenRequest(tokenRequest); err != nil {
+		return nil, err
+	}
+
+	magicLink, err := tt.magicLinkService.Consume(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return tt.passwordlessLogin(ctx, magicLink.Email(), tokenRequest)
+}
+
+func (tt *authServiceTest) passwordlessLogin(ctx context.Context, email string, tokenRequest AccessTokenRequest) (*LoginResult, error) {
+	result, err := tt.service.PasswordlessLogin(ctx, email, tokenRequest)
+	if !errors.Is(err, ErrUserNotSignedUp) {
+		return result, err
+	}
+
+	if _, err := tt.registrationService.SignUpPasswordless(ctx, email); err != nil {
+		return nil, err
+	}
+
+	return tt.service.PasswordlessLogin(ctx, email, tokenRequest)
+}
+
+// oauthLogin logs in with a provider identity and signs up a user without an
+// account, like OAuthHandler.Callback does.
+func (tt *authServiceTest) oauthLogin(ctx context.Context, oauthUser OAuthUser) (*entities.User, *LoginResult, error) {
+	user, result, err := tt.service.OAuthLogin(ctx, oauthUser)
+	if !errors.Is(err, ErrUserNotSignedUp) {
+		return user, result, err
+	}
+
+	if _, err := tt.registrationService.SignUpOAuth(ctx, oauthUser); err != nil {
+		return nil, nil, err
+	}
+
+	return tt.service.OAuthLogin(ctx, oauthUser)
+}
+
+// createUser stores an unconfirmed user with a password.
+func (tt *authServiceTest) createUser(t *testing.T, email string) *entities.User {
+	t.Helper()
+
+	password, err := vobjects.NewPassword(testUserPassword)
+	require.NoError(t, err)
+
+	user := entities.NewUser(email, password)
+	require.NoError(t, tt.users.Create(context.Background(), user))
+
+	return user
+}
+
+// enableTOTP turns on MFA for the user and returns the TOTP key.
+func (tt *authServiceTest) enableTOTP(t *testing.T, user *entities.User) vobjects.TOTPKey {
+	t.Helper()
+
+	key, err := vobjects.NewTOTPKey()
+	require.NoError(t, err)
+
+	totp := entities.NewUserTOTP(user.ID(), key.Secret(), tt.clock.Now())
+	require.NoError(t, totp.Activate(tt.clock.Now()))
+	require.NoError(t, tt.totps.Save(context.Background(), totp))
+
+	return key
+}
+
+// lastCode returns the last email code sent to the email.
+func (tt *authServiceTest) lastCode(t *testing.T, email string, purpose vobjects.EmailCodePurpose) string {
+	t.Helper()
+
+	code, err := tt.emailCodes.Get(context.Background(), email, purpose)
+	require.NoError(t, err)
+
+	return code.Code()
+}
+
+func (tt *authServiceTest) getUser(t *testing.T, email string) *entities.User {
+	t.Helper()
+
+	user, err := tt.users.GetByEmail(context.Background(), email)
+	require.NoError(t, err)
+
+	return user
+}
+
+// addOAuthRefreshToken stores a refresh token issued to an OAuth client on
+// behalf of the user.
+func (tt *authServiceTest) addOAuthRefreshToken(t *testing.T, user *entities.User) {
+	t.Helper()
+
+	refreshToken := vobjects.NewOAuthRefreshToken("client", user.ID(), nil, time.Hour)
+	require.NoError(t, tt.refreshTokens.Create(context.Background(), refreshToken))
+}
+
+func (tt *authServiceTest) requireNoOAuthRefreshTokens(t *testing.T, user *entities.User) {
+	t.Helper()
+
+	refreshTokens, err := tt.refreshTokens.ListByUserID(context.Background(), user.ID())
+	require.NoError(t, err)
+	require.Empty(t, refreshTokens)
+}
+
+// wrongCode returns a code of the same length that differs from code.
+func wrongCode(code string) string {
+	if code[0] == '0' {
+		return "1" + code[1:]
+	}
+
+	return "0" + code[1:]
+}
+
+func TestAuthServiceCodeLogin(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	require.NoError(t, tt.emailCodeService.SendLoginCode(ctx, testUserEmail))
+	code := tt.lastCode(t, testUserEmail, vobjects.EmailCodeLogin)
+
+	_, err := tt.codeLogin(ctx, testUserEmail, wrongCode(code), AccessTokenRequest{})
+	require.ErrorIs(t, err, ErrInvalidEmailCode)
+
+	result, err := tt.codeLogin(ctx, testUserEmail, code, AccessTokenRequest{})
+	require.NoError(t, err)
+	require.False(t, result.MFARequired())
+	require.NotEmpty(t, result.AccessToken)
+	require.NotEmpty(t, result.RefreshToken)
+	require.True(t, tt.getUser(t, testUserEmail).Confirmed())
+
+	_, err = tt.codeLogin(ctx, testUserEmail, code, AccessTokenRequest{})
+	require.ErrorIs(t, err, ErrInvalidEmailCode)
+}
+
+func TestAuthServiceCodeLoginMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	require.NoError(t, tt.emailCodeService.SendLoginCode(ctx, testUserEmail))
+	code := tt.lastCode(t, testUserEmail, vobjects.EmailCodeLogin)
+
+	for range testEmailCodeAttempts {
+		_, err := tt.codeLogin(ctx, testUserEmail, wrongCode(code), AccessTokenRequest{})
+		require.ErrorIs(t, err, ErrInvalidEmailCode)
+	}
+
+	_, err := tt.codeLogin(ctx, testUserEmail, code, AccessTokenRequest{})
+	require.ErrorIs(t, err, ErrInvalidEmailCode)
+}
+
+func TestAuthServiceCodeLoginConcurrent(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	tt.createUser(t, testUserEmail)
+
+	require.NoError(t, tt.emailCodeService.SendLoginCode(ctx, testUserEmail))
+	code := tt.lastCode(t, testUserEmail, vobjects.EmailCodeLogin)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		logins int
+	)
+
+	for range testEmailCodeAttempts {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := tt.codeLogin(ctx, testUserEmail, code, AccessTokenRequest{}); err == nil {
+				mu.Lock()
+				logins++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, 1, logins)
+}
+
+func TestAuthServiceMagicLinkLogin(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	require.NoError(t, tt.magicLinkService.Send(ctx, testUserEmail))
+	token := tt.magicLinks.LastToken(testUserEmail)
+
+	result, err := tt.magicLinkLogin(ctx, token, AccessTokenRequest{})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.AccessToken)
+
+	user := tt.getUser(t, testUserEmail)
+	require.True(t, user.Confirmed())
+	require.False(t, user.HasPassword())
+
+	_, err = tt.magicLinkLogin(ctx, token, AccessTokenRequest{})
+	require.ErrorIs(t, err, ErrInvalidMagicLink)
+}
+
+func TestAuthServiceMagicLinkLoginReclaimsUnconfirmedAccount(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	// Someone registers the victim's email without confirming it and
+	// attaches their own credentials to the account.
+	user := tt.createUser(t, testUserEmail)
+	tt.enableTOTP(t, user)
+	require.NoError(t, tt.identities.Create(ctx, entities.NewUserIdentity(user.ID(), "github", "attacker", testUserEmail)))
+	require.NoError(t, tt.credentials.Create(ctx, entities.NewWebAuthnCredential([]byte("key"), user.ID(), []byte("public"), 0, nil, "key", tt.clock.Now())))
+	require.NoError(t, tt.refreshTokens.Create(ctx, vobjects.NewOAuthRefreshToken("client", user.ID(), nil, time.Hour)))
+
+	require.NoError(t, tt.magicLinkService.Send(ctx, testUserEmail))
+
+	result, err := tt.magicLinkLogin(ctx, tt.magicLinks.LastToken(testUserEmail), AccessTokenRequest{})
+	require.NoError(t, err)
+	require.False(t, result.MFARequired())
+	require.NotEmpty(t, result.AccessToken)
+
+	reclaimed := tt.getUser(t, testUserEmail)
+	require.True(t, reclaimed.Confirmed())
+	require.False(t, reclaimed.HasPassword())
+
+	_, err = tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.Error(t, err)
+
+	identities, err := tt.identities.ListByUserID(ctx, user.ID())
+	require.NoError(t, err)
+	require.Empty(t, identities)
+
+	credentials, err := tt.credentials.ListByUserID(ctx, user.ID())
+	require.NoError(t, err)
+	require.Empty(t, credentials)
+
+	refreshTokens, err := tt.refreshTokens.ListByUserID(ctx, user.ID())
+	require.NoError(t, err)
+	require.Empty(t, refreshTokens)
+
+	_, err = tt.totps.GetByUserID(ctx, user.ID())
+	require.Error(t, err)
+}
+
+func TestAuthServiceCodeLoginKeepsConfirmedAccount(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	user := tt.createUser(t, testUserEmail)
+	user.ForceConfirm()
+	require.NoError(t, tt.users.Update(ctx, user))
+	require.NoError(t, tt.identities.Create(ctx, entities.NewUserIdentity(user.ID(), "github", "owner", testUserEmail)))
+
+	require.NoError(t, tt.emailCodeService.SendLoginCode(ctx, testUserEmail))
+
+	_, err := tt.codeLogin(ctx, testUserEmail, tt.lastCode(t, testUserEmail, vobjects.EmailCodeLogin), AccessTokenRequest{})
+	require.NoError(t, err)
+	require.True(t, tt.getUser(t, testUserEmail).HasPassword())
+
+	identities, err := tt.identities.ListByUserID(ctx, user.ID())
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+
+	_, err = tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+}
+
+func testOAuthUser(subject string, email string, verified bool) OAuthUser {
+	return OAuthUser{
+		Provider:      "github",
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: verified,
+	}
+}
+
+func TestAuthServiceOAuthLoginRegisters(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	_, _, err := tt.oauthLogin(ctx, testOAuthUser("1", testUserEmail, false))
+	require.ErrorIs(t, err, ErrEmailNotVerified)
+
+	user, result, err := tt.oauthLogin(ctx, testOAuthUser("1", testUserEmail, true))
+	require.NoError(t, err)
+	require.False(t, result.MFARequired())
+	require.NotEmpty(t, result.AccessToken)
+	require.True(t, user.Confirmed())
+	require.False(t, user.HasPassword())
+
+	// The identity is found by the subject, not by the email.
+	again, _, err := tt.oauthLogin(ctx, testOAuthUser("1", "changed@example.com", false))
+	require.NoError(t, err)
+	require.Equal(t, user.ID(), again.ID())
+}
+
+func TestAuthServiceLoginWithoutAccount(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	// The login services don't sign up, the handlers do it after a login
+	// without an account.
+	_, _, err := tt.service.OAuthLogin(ctx, testOAuthUser("1", testUserEmail, true))
+	require.ErrorIs(t, err, ErrUserNotSignedUp)
+
+	_, err = tt.service.PasswordlessLogin(ctx, testUserEmail, AccessTokenRequest{})
+	require.ErrorIs(t, err, ErrUserNotSignedUp)
+
+	_, err = tt.users.GetByEmail(ctx, testUserEmail)
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+}
+
+func TestAuthServiceOAuthLoginExistingEmail(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	user := tt.createUser(t, testUserEmail)
+	user.ForceConfirm()
+	require.NoError(t, tt.users.Update(ctx, user))
+
+	_, _, err := tt.oauthLogin(ctx, testOAuthUser("1", testUserEmail, false))
+	require.ErrorIs(t, err, ErrIdentityNotLinked)
+
+	linked, _, err := tt.oauthLogin(ctx, testOAuthUser("1", testUserEmail, true))
+	require.NoError(t, err)
+	require.Equal(t, user.ID(), linked.ID())
+	require.True(t, tt.getUser(t, testUserEmail).HasPassword())
+
+	identities, err := tt.service.ListIdentities(ctx, user.ID())
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+}
+
+func TestAuthServiceOAuthLoginReclaimsUnconfirmedAccount(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	user := tt.createUser(t, testUserEmail)
+
+	linked, _, err := tt.oauthLogin(ctx, testOAuthUser("1", testUserEmail, true))
+	require.NoError(t, err)
+	require.Equal(t, user.ID(), linked.ID())
+
+	reclaimed := tt.getUser(t, testUserEmail)
+	require.True(t, reclaimed.Confirmed())
+	require.False(t, reclaimed.HasPassword())
+
+	identities, err := tt.service.ListIdentities(ctx, user.ID())
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+}
+
+func TestAuthServiceLinkIdentity(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	user := tt.createUser(t, testUserEmail)
+	other := tt.createUser(t, "other@example.com")
+
+	identity, err := tt.service.LinkIdentity(ctx, user.ID(), testOAuthUser("1", "any@example.com", false))
+	require.NoError(t, err)
+	require.Equal(t, user.ID(), identity.UserID())
+
+	_, err = tt.service.LinkIdentity(ctx, user.ID(), testOAuthUser("1", "any@example.com", false))
+	require.NoError(t, err)
+
+	_, err = tt.service.LinkIdentity(ctx, other.ID(), testOAuthUser("1", "any@example.com", false))
+	require.ErrorIs(t, err, ErrIdentityLinkedToOtherUser)
+
+	identities, err := tt.service.ListIdentities(ctx, user.ID())
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+
+	require.NoError(t, tt.service.UnlinkIdentity(ctx, user.ID(), "github"))
+
+	identities, err = tt.service.ListIdentities(ctx, user.ID())
+	require.NoError(t, err)
+	require.Empty(t, identities)
+}
+
+func TestAuthServiceUnlinkLastIdentity(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	user, _, err := tt.oauthLogin(ctx, testOAuthUser("1", testUserEmail, true))
+	require.NoError(t, err)
+
+	err = tt.service.UnlinkIdentity(ctx, user.ID(), "github")
+	require.ErrorIs(t, err, ErrLastLoginMethod)
+
+	_, err = tt.service.LinkIdentity(ctx, user.ID(), OAuthUser{Provider: "google", Subject: "2", Email: testUserEmail})
+	require.NoError(t, err)
+	require.NoError(t, tt.service.UnlinkIdentity(ctx, user.ID(), "github"))
+}
+
+func TestAuthServiceSetPassword(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	user, _, err := tt.oauthLogin(ctx, testOAuthUser("1", testUserEmail, true))
+	require.NoError(t, err)
+
+	// A passwordless user can't log in with any password.
+	_, err = tt.service.Login(ctx, testUserEmail, "", AccessTokenRequest{})
+	require.ErrorIs(t, err, ErrInvalidPassword)
+
+	require.NoError(t, tt.service.SetPassword(ctx, user.ID(), testUserPassword))
+
+	_, err = tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+
+	err = tt.service.SetPassword(ctx, user.ID(), "another-password")
+	require.ErrorIs(t, err, domain.ErrPasswordAlreadySet)
+
+	_, err = tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+}
+
+func TestAuthServiceOAuthLoginMFA(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+
+	user, _, err := tt.oauthLogin(ctx, testOAuthUser("1", testUserEmail, true))
+	require.NoError(t, err)
+
+	key := tt.enableTOTP(t, user)
+
+	_, result, err := tt.oauthLogin(ctx, testOAuthUser("1", testUserEmail, true))
+	require.NoError(t, err)
+	require.True(t, result.MFARequired())
+	require.Empty(t, result.AccessToken)
+	require.Empty(t, result.RefreshToken)
+
+	at, rt, err := tt.service.CompleteMFAChallenge(ctx, result.MFAToken, MFAProof{Code: key.Code(tt.clock.Now())})
+	require.NoError(t, err)
+	require.NotEmpty(t, at)
+	require.NotEmpty(t, rt)
+}
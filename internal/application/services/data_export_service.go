@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type DataExportServiceConfig struct {
+	ExportTTL time.Duration
+	BatchSize int32
+}
+
+// UserDataArchive is the personal data of a user handed out in a data
+// export. Token values are secrets and are not included.
+type UserDataArchive struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	User        UserDataRecord       `json:"user"`
+	Sessions    []UserDataSession    `json:"sessions"`
+	Identities  []UserDataIdentity   `json:"identities"`
+	Consents    []UserDataConsent    `json:"consents"`
+	AuditEvents []UserDataAuditEvent `json:"audit_events"`
+}
+
+type UserDataRecord struct {
+	ID                  uuid.UUID  `json:"id"`
+	Email               string     `json:"email"`
+	Confirmed           bool       `json:"confirmed"`
+	HasPassword         bool       `json:"has_password"`
+	Status              string     `json:"status"`
+	StatusReason        string     `json:"status_reason,omitempty"`
+	StatusUntil         *time.Time `json:"status_until,omitempty"`
+	Roles               []string   `json:"roles"`
+	CreatedAt           time.Time  `json:"created_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+type UserDataSession struct {
+	ClientID  string    `json:"client_id,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type UserDataIdentity struct {
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"`
+	Email    string    `json:"email"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+type UserDataConsent struct {
+	ClientID  string    `json:"client_id"`
+	Scopes    []string  `json:"scopes"`
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+type UserDataAuditEvent struct {
+	Action    string    `json:"action"`
+	ActorID   uuid.UUID `json:"actor_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DataExportService generates archives of personal data requested by users.
+// Archives are generated by Run in the background and a message is sent
+// when one is ready to download.
+type DataExportService struct {
+	exportRepository       repo.DataExportRepository
+	userRepository         repo.UserRepository
+	identityRepository     repo.UserIdentityRepository
+	roleRepository         repo.RoleRepository
+	consentRepository      repo.OAuthConsentRepository
+	refreshTokenRepository repo.OAuthRefreshTokenRepository
+	auditLogRepository     repo.AdminAuditLogRepository
+	txManager              repo.TransactionManager
+	readyMsgSender         MessageSender
+	log                    *slog.Logger
+	tracer                 trace.Tracer
+	cfg                    DataExportServiceConfig
+}
+
+func NewDataExportService(
+	exportRepository repo.DataExportRepository,
+	userRepository repo.UserRepository,
+	identityRepository repo.UserIdentityRepository,
+	roleRepository repo.RoleRepository,
+	consentRepository repo.OAuthConsentRepository,
+	refreshTokenRepository repo.OAuthRefreshTokenRepository,
+	auditLogRepository repo.AdminAuditLogRepository,
+	txManager repo.TransactionManager,
+	readyMsgSender MessageSender,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg DataExportServiceConfig,
+) *DataExportService {
+	return &DataExportService{
+		exportRepository:       exportRepository,
+		userRepository:         userRepository,
+		identityRepository:     identityRepository,
+		roleRepository:         roleRepository,
+		consentRepository:      consentRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		auditLogRepository:     auditLogRepository,
+		txManager:              txManager,
+		readyMsgSender:         readyMsgSender,
+		log:                    log,
+		tracer:                 tracer,
+		cfg:                    cfg,
+	}
+}
+
+// RequestExport returns the latest export of the user if it is pending or
+// still can be downloaded, otherwise it requests a new one.
+func (s *DataExportService) RequestExport(ctx context.Context, userID uuid.UUID) (*entities.DataExport, error) {
+	ctx, span := s.tracer.Start(ctx, "DataExportService.RequestExport")
+	defer span.End()
+
+	var export *entities.DataExport
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepository.GetByID(ctx, userID); err != nil {
+			return err
+		}
+
+		latest, err := s.exportRepository.GetLatestByUserID(ctx, userID)
+		if err != nil && !errors.Is(err, repo.ErrObjectNotFound) {
+			return err
+		}
+
+		if latest != nil && (latest.Pending() || latest.Ready()) {
+			export = latest
+			return nil
+		}
+
+		export = entities.NewDataExport(userID)
+
+		return s.exportRepository.Create(ctx, export)
+	}); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// Run generates pending exports every interval.
+func (s *DataExportService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.GeneratePending(ctx); err != nil {
+				s.log.Warn("failed generate data exports", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// GeneratePending generates a batch of pending exports and returns the
+// number of generated exports.
+func (s *DataExportService) GeneratePending(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "DataExportService.GeneratePending")
+	defer span.End()
+
+	generated := 0
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		exports, err := s.exportRepository.ListPending(ctx, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, export := range exports {
+			if err := s.generate(ctx, export); err != nil {
+				return err
+			}
+		}
+
+		generated = len(exports)
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return generated, nil
+}
+
+func (s *DataExportService) generate(ctx context.Context, export *entities.DataExport) error {
+	user, err := s.userRepository.GetByID(ctx, export.UserID())
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			export.Cancel()
+
+			return s.exportRepository.Update(ctx, export)
+		}
+
+		return err
+	}
+
+	archive, err := s.buildArchive(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(archive)
+	if err != nil {
+		return err
+	}
+
+	export.Complete(data, s.cfg.ExportTTL)
+
+	if err := s.exportRepository.Update(ctx, export); err != nil {
+		return err
+	}
+
+	readyMsg := DataExportReadyMessage{
+		UserID:    user.ID(),
+		Email:     user.Email(),
+		ExportID:  export.ID(),
+		ExpiresAt: *export.ExpiresAt(),
+	}
+
+	return s.readyMsgSender.SendMessage(ctx, readyMsg)
+}
+
+func (s *DataExportService) buildArchive(ctx context.Context, user *entities.User) (*UserDataArchive, error) {
+	roles, err := s.roleRepository.ListByUserID(ctx, user.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := s.identityRepository.ListByUserID(ctx, user.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	consents, err := s.consentRepository.ListByUserID(ctx, user.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTokens, err := s.refreshTokenRepository.ListByUserID(ctx, user.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	auditRecords, err := s.auditLogRepository.ListByTargetUserID(ctx, user.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &UserDataArchive{
+		GeneratedAt: time.Now(),
+		User: UserDataRecord{
+			ID:                  user.ID(),
+			Email:               user.Email(),
+			Confirmed:           user.Confirmed(),
+			HasPassword:         user.HasPassword(),
+			Status:              string(user.Status()),
+			StatusReason:        user.StatusReason(),
+			StatusUntil:         user.StatusUntil(),
+			Roles:               entities.RoleNames(roles),
+			CreatedAt:           user.CreatedAt(),
+			DeletionScheduledAt: user.DeletionScheduledAt(),
+		},
+		Sessions:    make([]UserDataSession, 0, len(refreshTokens)+1),
+		Identities:  make([]UserDataIdentity, 0, len(identities)),
+		Consents:    make([]UserDataConsent, 0, len(consents)),
+		AuditEvents: make([]UserDataAuditEvent, 0, len(auditRecords)),
+	}
+
+	if refreshToken := user.RefreshToken(); refreshToken != nil && refreshToken.Valid() {
+		archive.Sessions = append(archive.Sessions, UserDataSession{
+			ExpiresAt: refreshToken.ExpiredAt(),
+		})
+	}
+
+	for _, refreshToken := range refreshTokens {
+		archive.Sessions = append(archive.Sessions, UserDataSession{
+			ClientID:  refreshToken.ClientID(),
+			Scopes:    refreshToken.Scopes(),
+			ExpiresAt: refreshToken.ExpiredAt(),
+		})
+	}
+
+	for _, identity := range identities {
+		archive.Identities = append(archive.Identities, UserDataIdentity{
+			Provider: identity.Provider(),
+			Subject:  identity.Subject(),
+			Email:    identity.Email(),
+			LinkedAt: identity.LinkedAt(),
+		})
+	}
+
+	for _, consent := range consents {
+		archive.Consents = append(archive.Consents, UserDataConsent{
+			ClientID:  consent.ClientID(),
+			Scopes:    consent.Scopes(),
+			GrantedAt: consent.GrantedAt(),
+		})
+	}
+
+	for _, record := range auditRecords {
+		archive.AuditEvents = append(archive.AuditEvents, UserDataAuditEvent{
+			Action:    record.Action(),
+			ActorID:   record.ActorID(),
+			CreatedAt: record.CreatedAt(),
+		})
+	}
+
+	return archive, nil
+}
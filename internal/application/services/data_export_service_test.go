@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/stretchr/testify/require"
+)
+
+type dataExportServiceTest struct {
+	*authServiceTest
+	export        *DataExportService
+	exports       *fakeDataExportRepository
+	consents      *fakeOAuthConsentRepository
+	auditLog      *fakeAdminAuditLogRepository
+	readyMessages *messageRecorder
+}
+
+func newDataExportServiceTest(t *testing.T) *dataExportServiceTest {
+	t.Helper()
+
+	test := &dataExportServiceTest{
+		authServiceTest: newAuthServiceTest(t, RegistrationOpen),
+		exports:         &fakeDataExportRepository{},
+		consents:        newFakeOAuthConsentRepository(),
+		auditLog:        &fakeAdminAuditLogRepository{},
+		readyMessages:   &messageRecorder{},
+	}
+
+	test.export = NewDataExportService(
+		test.exports,
+		test.users,
+		test.identities,
+		test.roles,
+		test.consents,
+		test.refreshTokens,
+		test.auditLog,
+		fakeTxManager{},
+		test.readyMessages,
+		testLogger,
+		testTracer,
+		DataExportServiceConfig{
+			ExportTTL: time.Hour,
+			BatchSize: 10,
+		},
+	)
+
+	return test
+}
+
+func TestDataExportServiceGenerate(t *testing.T) {
+	ctx := context.Background()
+	tt := newDataExportServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+	actorID := uuid.New()
+
+	refreshToken := vobjects.NewOAuthRefreshToken("client", user.ID(), vobjects.Scopes{vobjects.ScopeOpenID}, time.Hour)
+	require.NoError(t, tt.refreshTokens.Create(ctx, refreshToken))
+	require.NoError(t, tt.identities.Create(ctx, entities.NewUserIdentity(user.ID(), "github", "1", testUserEmail)))
+	require.NoError(t, tt.consents.Save(ctx, entities.NewOAuthConsent(user.ID(), "client", vobjects.Scopes{vobjects.ScopeOpenID})))
+	require.NoError(t, tt.auditLog.Create(ctx, entities.NewAdminAuditRecord(actorID, entities.AdminActionConfirmUser, user.ID())))
+
+	_, err := tt.export.RequestExport(ctx, uuid.New())
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	export, err := tt.export.RequestExport(ctx, user.ID())
+	require.NoError(t, err)
+	require.True(t, export.Pending())
+
+	// A pending export is not requested twice.
+	again, err := tt.export.RequestExport(ctx, user.ID())
+	require.NoError(t, err)
+	require.Equal(t, export.ID(), again.ID())
+
+	generated, err := tt.export.GeneratePending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, generated)
+
+	ready, err := tt.export.RequestExport(ctx, user.ID())
+	require.NoError(t, err)
+	require.Equal(t, export.ID(), ready.ID())
+	require.True(t, ready.Ready())
+	require.NotContains(t, string(ready.Data()), refreshToken.Token())
+
+	var archive UserDataArchive
+	require.NoError(t, json.Unmarshal(ready.Data(), &archive))
+	require.Equal(t, user.ID(), archive.User.ID)
+	require.Equal(t, testUserEmail, archive.User.Email)
+	require.True(t, archive.User.HasPassword)
+	require.Len(t, archive.Sessions, 1)
+	require.Equal(t, "client", archive.Sessions[0].ClientID)
+	require.Len(t, archive.Identities, 1)
+	require.Equal(t, "github", archive.Identities[0].Provider)
+	require.Len(t, archive.Consents, 1)
+	require.Len(t, archive.AuditEvents, 1)
+	require.Equal(t, actorID, archive.AuditEvents[0].ActorID)
+
+	messages := tt.readyMessages.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, export.ID(), messages[0].(DataExportReadyMessage).ExportID)
+
+	generated, err = tt.export.GeneratePending(ctx)
+	require.NoError(t, err)
+	require.Zero(t, generated)
+}
+
+func TestDataExportServiceDeletedUser(t *testing.T) {
+	ctx := context.Background()
+	tt := newDataExportServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+
+	export, err := tt.export.RequestExport(ctx, user.ID())
+	require.NoError(t, err)
+
+	require.NoError(t, tt.users.Delete(ctx, user.ID()))
+
+	_, err = tt.export.GeneratePending(ctx)
+	require.NoError(t, err)
+
+	canceled, err := tt.exports.GetLatestByUserID(ctx, user.ID())
+	require.NoError(t, err)
+	require.Equal(t, export.ID(), canceled.ID())
+	require.Equal(t, entities.DataExportStatusCanceled, canceled.Status())
+	require.Empty(t, canceled.Data())
+	require.Empty(t, tt.readyMessages.Messages())
+}
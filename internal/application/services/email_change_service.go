@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type EmailChangeServiceConfig struct {
+	Issuer string
+}
+
+// EmailChangeService changes the user email. The new email is confirmed with a
+// link sent to it, the old one is notified after the switch.
+type EmailChangeService struct {
+	userRepository         repo.UserRepository
+	emailChangeRepository  repo.EmailChangeRepository
+	refreshTokenRepository repo.OAuthRefreshTokenRepository
+	txManager              repo.TransactionManager
+	emailPolicy            EmailDomainPolicy
+	emailThrottle          *EmailThrottle
+	changeMsgSender        MessageSender
+	changedMsgSender       MessageSender
+	log                    *slog.Logger
+	tracer                 trace.Tracer
+	cfg                    EmailChangeServiceConfig
+}
+
+func NewEmailChangeService(
+	userRepository repo.UserRepository,
+	emailChangeRepository repo.EmailChangeRepository,
+	refreshTokenRepository repo.OAuthRefreshTokenRepository,
+	txManager repo.TransactionManager,
+	emailPolicy EmailDomainPolicy,
+	emailThrottle *EmailThrottle,
+	changeMsgSender MessageSender,
+	changedMsgSender MessageSender,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg EmailChangeServiceConfig,
+) *EmailChangeService {
+	return &EmailChangeService{
+		userRepository:         userRepository,
+		emailChangeRepository:  emailChangeRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		txManager:              txManager,
+		emailPolicy:            emailPolicy,
+		emailThrottle:          emailThrottle,
+		changeMsgSender:        changeMsgSender,
+		changedMsgSender:       changedMsgSender,
+		log:                    log,
+		tracer:                 tracer,
+		cfg:                    cfg,
+	}
+}
+
+// RequestChange sends a confirmation link to the new email. A user with a
+// password has to enter it again.
+func (s *EmailChangeService) RequestChange(ctx context.Context, userID uuid.UUID, newEmail string, password string) error {
+	ctx, span := s.tracer.Start(ctx, "EmailChangeService.RequestChange")
+	defer span.End()
+
+	if !s.emailPolicy.Allowed(newEmail) {
+		return errors.Wrapf(ErrEmailDomainNotAllowed, "email domain of %s is not allowed", newEmail)
+	}
+
+	// The email is counted before the transaction, a retried transaction
+	// would count it again.
+	if err := s.emailThrottle.Allow(ctx, newEmail); err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if user.HasPassword() && !user.CheckPassword(password) {
+			return ErrInvalidPassword
+		}
+
+		if user.Email() == newEmail {
+			return errors.Wrapf(ErrEmailUnchanged, "user already has email = %s", newEmail)
+		}
+
+		if _, err := s.userRepository.GetByEmail(ctx, newEmail); err == nil {
+			return errors.Wrapf(repo.ErrDuplicate, "user with email = %s already exists", newEmail)
+		} else if !errors.Is(err, repo.ErrObjectNotFound) {
+			return err
+		}
+
+		change := entities.NewEmailChange(user.ID(), newEmail)
+
+		if err := s.emailChangeRepository.Save(ctx, change); err != nil {
+			return err
+		}
+
+		changeMsg := EmailChangeMessage{
+			Email:       newEmail,
+			ConfirmLink: s.createConfirmLink(change.Token()),
+		}
+
+		return s.changeMsgSender.SendMessage(ctx, changeMsg)
+	})
+}
+
+// ConfirmChange switches the user to the new email and revokes all of its
+// sessions. The email can be taken by another user since the change was
+// requested, then the change fails with repo.ErrDuplicate.
+func (s *EmailChangeService) ConfirmChange(ctx context.Context, token string) error {
+	ctx, span := s.tracer.Start(ctx, "EmailChangeService.ConfirmChange")
+	defer span.End()
+
+	var oldEmail, newEmail string
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		change, err := s.emailChangeRepository.GetByToken(ctx, token)
+		if err != nil {
+			if errors.Is(err, repo.ErrObjectNotFound) {
+				return errors.Wrap(ErrInvalidEmailChangeToken, "email change token not exists")
+			}
+
+			return err
+		}
+
+		if !change.Valid() {
+			return errors.Wrap(ErrInvalidEmailChangeToken, "email change token is expired")
+		}
+
+		user, err := s.userRepository.GetByID(ctx, change.UserID())
+		if err != nil {
+			return err
+		}
+
+		oldEmail, newEmail = user.Email(), change.NewEmail()
+
+		user.ChangeEmail(newEmail)
+
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.emailChangeRepository.Delete(ctx, user.ID()); err != nil {
+			return err
+		}
+
+		if err := s.refreshTokenRepository.DeleteByUserID(ctx, user.ID()); err != nil {
+			return err
+		}
+
+		changedMsg := EmailChangedMessage{
+			Email:    oldEmail,
+			NewEmail: newEmail,
+		}
+
+		return s.changedMsgSender.SendMessage(ctx, changedMsg)
+	}); err != nil {
+		return err
+	}
+
+	s.log.Info("email changed", slog.String("old_email", oldEmail), slog.String("new_email", newEmail))
+
+	return nil
+}
+
+func (s *EmailChangeService) createConfirmLink(token string) string {
+	return fmt.Sprintf("%s/auth/email/confirm?token=%s", s.cfg.Issuer, url.QueryEscape(token))
+}
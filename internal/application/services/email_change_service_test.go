@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/stretchr/testify/require"
+)
+
+const testNewEmail = "new@example.com"
+
+type emailChangeServiceTest struct {
+	*authServiceTest
+	emailChange     *EmailChangeService
+	changes         *fakeEmailChangeRepository
+	changeMessages  *messageRecorder
+	changedMessages *messageRecorder
+}
+
+func newEmailChangeServiceTest(t *testing.T) *emailChangeServiceTest {
+	t.Helper()
+
+	test := &emailChangeServiceTest{
+		authServiceTest: newAuthServiceTest(t, RegistrationOpen),
+		changes:         newFakeEmailChangeRepository(),
+		changeMessages:  &messageRecorder{},
+		changedMessages: &messageRecorder{},
+	}
+
+	emailThrottle := NewEmailThrottle(newFakeEmailThrottleRepository(), EmailThrottleConfig{
+		Limit:  testEmailThrottleLimit,
+		Window: time.Hour,
+	})
+
+	test.emailChange = NewEmailChangeService(
+		test.users,
+		test.changes,
+		test.refreshTokens,
+		fakeTxManager{},
+		allowAllEmailPolicy{},
+		emailThrottle,
+		test.changeMessages,
+		test.changedMessages,
+		testLogger,
+		testTracer,
+		EmailChangeServiceConfig{
+			Issuer: testIssuer,
+		},
+	)
+
+	return test
+}
+
+func TestEmailChangeServiceRequestChange(t *testing.T) {
+	ctx := context.Background()
+	tt := newEmailChangeServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+	tt.createUser(t, "taken@example.com")
+
+	err := tt.emailChange.RequestChange(ctx, user.ID(), testNewEmail, "wrong-password")
+	require.ErrorIs(t, err, ErrInvalidPassword)
+
+	err = tt.emailChange.RequestChange(ctx, user.ID(), testUserEmail, testUserPassword)
+	require.ErrorIs(t, err, ErrEmailUnchanged)
+
+	err = tt.emailChange.RequestChange(ctx, user.ID(), "taken@example.com", testUserPassword)
+	require.ErrorIs(t, err, repo.ErrDuplicate)
+
+	require.NoError(t, tt.emailChange.RequestChange(ctx, user.ID(), testNewEmail, testUserPassword))
+
+	messages := tt.changeMessages.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, testNewEmail, messages[0].(EmailChangeMessage).Email)
+	require.Contains(t, messages[0].(EmailChangeMessage).ConfirmLink, tt.changes.Token(user.ID()))
+}
+
+func TestEmailChangeServiceConfirmChange(t *testing.T) {
+	ctx := context.Background()
+	tt := newEmailChangeServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+	tt.addOAuthRefreshToken(t, user)
+
+	result, err := tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+
+	require.NoError(t, tt.emailChange.RequestChange(ctx, user.ID(), testNewEmail, testUserPassword))
+	token := tt.changes.Token(user.ID())
+
+	require.NoError(t, tt.emailChange.ConfirmChange(ctx, token))
+
+	_, err = tt.service.Login(ctx, testNewEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+
+	_, err = tt.users.GetByEmail(ctx, testUserEmail)
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	// Sessions issued for the old email are revoked.
+	_, _, err = tt.service.Refresh(ctx, result.RefreshToken, AccessTokenRequest{})
+	require.Error(t, err)
+	tt.requireNoOAuthRefreshTokens(t, user)
+
+	messages := tt.changedMessages.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, EmailChangedMessage{Email: testUserEmail, NewEmail: testNewEmail}, messages[0])
+
+	err = tt.emailChange.ConfirmChange(ctx, token)
+	require.ErrorIs(t, err, ErrInvalidEmailChangeToken)
+}
+
+func TestEmailChangeServiceConfirmReplacedChange(t *testing.T) {
+	ctx := context.Background()
+	tt := newEmailChangeServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+
+	require.NoError(t, tt.emailChange.RequestChange(ctx, user.ID(), testNewEmail, testUserPassword))
+	replaced := tt.changes.Token(user.ID())
+
+	require.NoError(t, tt.emailChange.RequestChange(ctx, user.ID(), "newer@example.com", testUserPassword))
+
+	err := tt.emailChange.ConfirmChange(ctx, replaced)
+	require.ErrorIs(t, err, ErrInvalidEmailChangeToken)
+	require.Equal(t, testUserEmail, tt.getUser(t, testUserEmail).Email())
+}
+
+func TestEmailChangeServiceConfirmTakenEmail(t *testing.T) {
+	ctx := context.Background()
+	tt := newEmailChangeServiceTest(t)
+	user := tt.createUser(t, testUserEmail)
+
+	require.NoError(t, tt.emailChange.RequestChange(ctx, user.ID(), testNewEmail, testUserPassword))
+
+	// Someone signs up with the email before the change is confirmed.
+	tt.createUser(t, testNewEmail)
+
+	err := tt.emailChange.ConfirmChange(ctx, tt.changes.Token(user.ID()))
+	require.ErrorIs(t, err, repo.ErrDuplicate)
+	require.Equal(t, testUserEmail, tt.getUser(t, testUserEmail).Email())
+}
+
+func TestEmailChangeServicePasswordlessUser(t *testing.T) {
+	ctx := context.Background()
+	tt := newEmailChangeServiceTest(t)
+
+	user, _, err := tt.oauthLogin(ctx, testOAuthUser("1", testUserEmail, true))
+	require.NoError(t, err)
+
+	require.NoError(t, tt.emailChange.RequestChange(ctx, user.ID(), testNewEmail, ""))
+	require.NoError(t, tt.emailChange.ConfirmChange(ctx, tt.changes.Token(user.ID())))
+	require.Equal(t, user.ID(), tt.getUser(t, testNewEmail).ID())
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type EmailCodeServiceConfig struct {
+	// Length is the number of digits in a code.
+	Length int
+	TTL    time.Duration
+	// MaxAttempts is the number of invalid codes after which the code is
+	// dropped and a new one has to be sent.
+	MaxAttempts int
+}
+
+// EmailCodeService sends numeric one-time codes by email and checks them.
+type EmailCodeService struct {
+	repository    repo.EmailCodeRepository
+	emailPolicy   EmailDomainPolicy
+	emailThrottle *EmailThrottle
+	codeMsgSender MessageSender
+	log           *slog.Logger
+	tracer        trace.Tracer
+	cfg           EmailCodeServiceConfig
+}
+
+func NewEmailCodeService(
+	repository repo.EmailCodeRepository,
+	emailPolicy EmailDomainPolicy,
+	emailThrottle *EmailThrottle,
+	codeMsgSender MessageSender,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg EmailCodeServiceConfig,
+) *EmailCodeService {
+	return &EmailCodeService{
+		repository:    repository,
+		emailPolicy:   emailPolicy,
+		emailThrottle: emailThrottle,
+		codeMsgSender: codeMsgSender,
+		log:           log,
+		tracer:        tracer,
+		cfg:           cfg,
+	}
+}
+
+// Create generates a new code for the email without sending it, so it can be
+// put into another email, e.g. the registration one.
+func (s *EmailCodeService) Create(ctx context.Context, email string, purpose vobjects.EmailCodePurpose) (*vobjects.EmailCode, error) {
+	ctx, span := s.tracer.Start(ctx, "EmailCodeService.Create")
+	defer span.End()
+
+	code, err := vobjects.NewEmailCode(email, purpose, s.cfg.Length, s.cfg.TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.Save(ctx, code); err != nil {
+		return nil, err
+	}
+
+	return code, nil
+}
+
+// Send emails a new code, the code sent before stops working.
+func (s *EmailCodeService) Send(ctx context.Context, email string, purpose vobjects.EmailCodePurpose) error {
+	ctx, span := s.tracer.Start(ctx, "EmailCodeService.Send")
+	defer span.End()
+
+	if err := s.emailThrottle.Allow(ctx, email); err != nil {
+		return err
+	}
+
+	code, err := s.Create(ctx, email, purpose)
+	if err != nil {
+		return err
+	}
+
+	codeMsg := EmailCodeMessage{
+		Email:     email,
+		Code:      code.Code(),
+		Purpose:   string(purpose),
+		ExpiresAt: code.ExpiredAt(),
+	}
+
+	return s.codeMsgSender.SendMessage(ctx, codeMsg)
+}
+
+// SendLoginCode emails a login code, an alternative to the magic link. As
+// with the magic link, a code to an email without an account signs the user
+// up, so the email domain is checked.
+func (s *EmailCodeService) SendLoginCode(ctx context.Context, email string) error {
+	ctx, span := s.tracer.Start(ctx, "EmailCodeService.SendLoginCode")
+	defer span.End()
+
+	if !s.emailPolicy.Allowed(email) {
+		return errors.Wrapf(ErrEmailDomainNotAllowed, "email domain of %s is not allowed", email)
+	}
+
+	return s.Send(ctx, email, vobjects.EmailCodeLogin)
+}
+
+// VerifyLoginCode checks a code sent by SendLoginCode.
+func (s *EmailCodeService) VerifyLoginCode(ctx context.Context, email string, code string) error {
+	return s.Verify(ctx, email, vobjects.EmailCodeLogin, code)
+}
+
+// Verify checks the code entered by the user. A valid code is consumed, a
+// code with too many invalid attempts is dropped.
+func (s *EmailCodeService) Verify(ctx context.Context, email string, purpose vobjects.EmailCodePurpose, code string) error {
+	ctx, span := s.tracer.Start(ctx, "EmailCodeService.Verify")
+	defer span.End()
+
+	emailCode, err := s.repository.Get(ctx, email, purpose)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return errors.Wrap(ErrInvalidEmailCode, "email code is expired or already used")
+		}
+
+		return err
+	}
+
+	if !emailCode.Valid() {
+		return errors.Wrap(ErrInvalidEmailCode, "email code is expired")
+	}
+
+	// The attempt is counted before the code is checked, so concurrent
+	// requests can't check more codes than allowed.
+	attempts, err := s.repository.AddAttempt(ctx, email, purpose)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return errors.Wrap(ErrInvalidEmailCode, "email code is expired or already used")
+		}
+
+		return err
+	}
+
+	if attempts > s.cfg.MaxAttempts {
+		if err := s.repository.Delete(ctx, email, purpose); err != nil {
+			return err
+		}
+
+		return errors.Wrap(ErrInvalidEmailCode, "too many attempts")
+	}
+
+	if !emailCode.Check(code) {
+		if attempts >= s.cfg.MaxAttempts {
+			if err := s.repository.Delete(ctx, email, purpose); err != nil {
+				return err
+			}
+		}
+
+		return errors.Wrap(ErrInvalidEmailCode, "invalid email code")
+	}
+
+	if err := s.repository.Consume(ctx, emailCode); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return errors.Wrap(ErrInvalidEmailCode, "email code is expired or already used")
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,5 @@
+package services
+
+type EmailDomainPolicy interface {
+	Allowed(email string) bool
+}
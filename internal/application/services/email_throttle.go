@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+)
+
+type EmailThrottleConfig struct {
+	// Limit is the number of emails sent to an address within Window.
+	Limit  int64
+	Window time.Duration
+}
+
+// EmailThrottle limits emails sent to an address, it is shared by all
+// endpoints sending emails to addresses entered by the client, so they can't
+// be used to flood a mailbox.
+type EmailThrottle struct {
+	repository repo.EmailThrottleRepository
+	cfg        EmailThrottleConfig
+}
+
+func NewEmailThrottle(repository repo.EmailThrottleRepository, cfg EmailThrottleConfig) *EmailThrottle {
+	return &EmailThrottle{
+		repository: repository,
+		cfg:        cfg,
+	}
+}
+
+// Allow counts an email to the address, it fails with ErrTooManyEmails when
+// the limit is reached.
+func (t *EmailThrottle) Allow(ctx context.Context, email string) error {
+	count, err := t.repository.Hit(ctx, email, t.cfg.Window)
+	if err != nil {
+		return err
+	}
+
+	if count > t.cfg.Limit {
+		return errors.Wrapf(ErrTooManyEmails, "too many emails sent to %s", email)
+	}
+
+	return nil
+}
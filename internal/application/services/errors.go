@@ -3,6 +3,31 @@ package services
 import "errors"
 
 var (
-	ErrUnauthorizedRefresh = errors.New("unauthorized refresh")
-	ErrInvalidPassword     = errors.New("invalid password")
+	ErrUnauthorizedRefresh       = errors.New("unauthorized refresh")
+	ErrInvalidPassword           = errors.New("invalid password")
+	ErrEmailDomainNotAllowed     = errors.New("email domain not allowed")
+	ErrInvalidOAuthState         = errors.New("invalid oauth state")
+	ErrRedirectURINotAllowed     = errors.New("redirect uri not allowed")
+	ErrIdentityNotLinked         = errors.New("identity not linked")
+	ErrEmailNotVerified          = errors.New("email not verified")
+	ErrIdentityLinkedToOtherUser = errors.New("identity linked to other user")
+	ErrLastLoginMethod           = errors.New("last login method")
+	ErrInvalidLoginCode          = errors.New("invalid login code")
+	ErrInvalidOAuthClient        = errors.New("invalid oauth client")
+	ErrInvalidAudience           = errors.New("invalid audience")
+	ErrInvalidScope              = errors.New("invalid scope")
+	ErrAdminSelfAction           = errors.New("admin action on own account")
+	ErrEmailUnchanged            = errors.New("email unchanged")
+	ErrInvalidEmailChangeToken   = errors.New("invalid email change token")
+	ErrMFANotSetUp               = errors.New("mfa not set up")
+	ErrInvalidMFAChallenge       = errors.New("invalid mfa challenge")
+	ErrInvalidWebAuthnSession    = errors.New("invalid webauthn session")
+	ErrInvalidWebAuthnCredential = errors.New("invalid webauthn credential")
+	ErrTooManyEmails             = errors.New("too many emails")
+	ErrInvalidMagicLink          = errors.New("invalid magic link")
+	ErrInvalidEmailCode          = errors.New("invalid email code")
+	ErrInvalidReferralCode       = errors.New("invalid referral code")
+	ErrRegistrationClosed        = errors.New("registration closed")
+	ErrInvalidInvitation         = errors.New("invalid invitation")
+	ErrUserNotSignedUp           = errors.New("user not signed up")
 )
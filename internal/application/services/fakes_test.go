@@ -0,0 +1,1419 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+var (
+	testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	testTracer = noop.NewTracerProvider().Tracer("test")
+)
+
+// fakeTxManager runs the function without a transaction, the fakes below
+// guard their state on their own. The context is marked, so fakes of Redis
+// repositories can refuse calls that a retried transaction would repeat.
+type fakeTxManager struct{}
+
+type fakeTxKey struct{}
+
+func (fakeTxManager) WithTransaction(ctx context.Context, f func(ctx context.Context) error) error {
+	return f(context.WithValue(ctx, fakeTxKey{}, true))
+}
+
+// errCalledInTransaction is returned by fakes of Redis repositories called
+// inside a transaction. A retried transaction would find a popped value gone
+// or count a hit twice.
+var errCalledInTransaction = errors.New("redis call inside a transaction")
+
+func inFakeTransaction(ctx context.Context) bool {
+	return ctx.Value(fakeTxKey{}) != nil
+}
+
+type testSecretManager struct{}
+
+func (testSecretManager) SecretKey() domain.Secret {
+	return domain.Secret("test-secret-key")
+}
+
+type allowAllEmailPolicy struct{}
+
+func (allowAllEmailPolicy) Allowed(string) bool {
+	return true
+}
+
+// messageRecorder keeps sent messages.
+type messageRecorder struct {
+	mu       sync.Mutex
+	messages []any
+}
+
+func (r *messageRecorder) SendMessage(_ context.Context, msg any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = append(r.messages, msg)
+
+	return nil
+}
+
+func (r *messageRecorder) Messages() []any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]any(nil), r.messages...)
+}
+
+// plainCipher keeps secrets as they are.
+type plainCipher struct{}
+
+func (plainCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	return plaintext, nil
+}
+
+func (plainCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	return ciphertext, nil
+}
+
+type fakeMFAChallengeRepository struct {
+	mu         sync.Mutex
+	challenges map[string]vobjects.MFAChallenge
+	attempts   map[string]int
+}
+
+func newFakeMFAChallengeRepository() *fakeMFAChallengeRepository {
+	return &fakeMFAChallengeRepository{
+		challenges: make(map[string]vobjects.MFAChallenge),
+		attempts:   make(map[string]int),
+	}
+}
+
+func (r *fakeMFAChallengeRepository) Create(_ context.Context, challenge *vobjects.MFAChallenge, _ time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.challenges[challenge.Token()]; ok {
+		return repo.ErrDuplicate
+	}
+
+	r.challenges[challenge.Token()] = *challenge
+
+	return nil
+}
+
+func (r *fakeMFAChallengeRepository) Get(_ context.Context, token string) (*vobjects.MFAChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	challenge, ok := r.challenges[token]
+	if !ok {
+		return nil, repo.ErrObjectNotFound
+	}
+
+	return &challenge, nil
+}
+
+func (r *fakeMFAChallengeRepository) AddAttempt(_ context.Context, token string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.challenges[token]; !ok {
+		return 0, repo.ErrObjectNotFound
+	}
+
+	r.attempts[token]++
+
+	return r.attempts[token], nil
+}
+
+func (r *fakeMFAChallengeRepository) Pop(ctx context.Context, token string) (*vobjects.MFAChallenge, error) {
+	if inFakeTransaction(ctx) {
+		return nil, errCalledInTransaction
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	challenge, ok := r.challenges[token]
+	if !ok {
+		return nil, repo.ErrObjectNotFound
+	}
+
+	delete(r.challenges, token)
+	delete(r.attempts, token)
+
+	return &challenge, nil
+}
+
+func (r *fakeMFAChallengeRepository) Delete(_ context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.challenges, token)
+	delete(r.attempts, token)
+
+	return nil
+}
+
+// fakeUserTOTPRepository returns copies, like a database read without row
+// locks, and counts reads to check how many codes were checked.
+type fakeUserTOTPRepository struct {
+	mu    sync.Mutex
+	totps map[uuid.UUID]entities.UserTOTP
+	reads int
+}
+
+func newFakeUserTOTPRepository() *fakeUserTOTPRepository {
+	return &fakeUserTOTPRepository{
+		totps: make(map[uuid.UUID]entities.UserTOTP),
+	}
+}
+
+func (r *fakeUserTOTPRepository) Save(_ context.Context, totp *entities.UserTOTP) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.totps[totp.UserID()] = *totp
+
+	return nil
+}
+
+func (r *fakeUserTOTPRepository) GetByUserID(_ context.Context, userID uuid.UUID) (*entities.UserTOTP, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reads++
+
+	totp, ok := r.totps[userID]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "totp not exists")
+	}
+
+	return &totp, nil
+}
+
+func (r *fakeUserTOTPRepository) Delete(_ context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.totps, userID)
+
+	return nil
+}
+
+func (r *fakeUserTOTPRepository) Reads() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reads
+}
+
+// fakeUserRepository stores copies of users, like a database.
+type fakeUserRepository struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]entities.User
+}
+
+func newFakeUserRepository() *fakeUserRepository {
+	return &fakeUserRepository{
+		users: make(map[uuid.UUID]entities.User),
+	}
+}
+
+func (r *fakeUserRepository) Create(_ context.Context, user *entities.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if strings.EqualFold(existing.Email(), user.Email()) {
+			return errors.Wrap(repo.ErrDuplicate, "user already exists")
+		}
+	}
+
+	r.users[user.ID()] = *user
+
+	return nil
+}
+
+func (r *fakeUserRepository) Update(_ context.Context, user *entities.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID()]; !ok {
+		return errors.Wrap(repo.ErrObjectNotFound, "user not exists")
+	}
+
+	for _, existing := range r.users {
+		if existing.ID() != user.ID() && strings.EqualFold(existing.Email(), user.Email()) {
+			return errors.Wrap(repo.ErrDuplicate, "user already exists")
+		}
+	}
+
+	r.users[user.ID()] = *user
+
+	return nil
+}
+
+func (r *fakeUserRepository) Delete(_ context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, userID)
+
+	return nil
+}
+
+func (r *fakeUserRepository) GetByID(_ context.Context, userID uuid.UUID) (*entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "user not exists")
+	}
+
+	return &user, nil
+}
+
+func (r *fakeUserRepository) GetByEmail(_ context.Context, email string) (*entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email(), email) {
+			return &user, nil
+		}
+	}
+
+	return nil, errors.Wrap(repo.ErrObjectNotFound, "user not exists")
+}
+
+func (r *fakeUserRepository) GetByRefreshToken(_ context.Context, refreshToken string) (*entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.RefreshToken() != nil && user.RefreshToken().Token() == refreshToken {
+			return &user, nil
+		}
+	}
+
+	return nil, errors.Wrap(repo.ErrObjectNotFound, "user not exists")
+}
+
+func (r *fakeUserRepository) List(_ context.Context, _ *repo.UserFilters, _ *repo.Pagination) ([]entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make([]entities.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+func (r *fakeUserRepository) ListDueForDeletion(_ context.Context, _ time.Time, _ int32) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var userIDs []uuid.UUID
+	for _, user := range r.users {
+		if user.DeletionDue() {
+			userIDs = append(userIDs, user.ID())
+		}
+	}
+
+	return userIDs, nil
+}
+
+func (r *fakeUserRepository) Purge(ctx context.Context, userID uuid.UUID) error {
+	return r.Delete(ctx, userID)
+}
+
+type fakeUserIdentityRepository struct {
+	mu         sync.Mutex
+	identities []entities.UserIdentity
+}
+
+func (r *fakeUserIdentityRepository) Create(_ context.Context, identity *entities.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.identities {
+		if existing.Provider() == identity.Provider() &&
+			(existing.Subject() == identity.Subject() || existing.UserID() == identity.UserID()) {
+			return errors.Wrap(repo.ErrDuplicate, "identity already exists")
+		}
+	}
+
+	r.identities = append(r.identities, *identity)
+
+	return nil
+}
+
+func (r *fakeUserIdentityRepository) Delete(_ context.Context, userID uuid.UUID, provider string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, identity := range r.identities {
+		if identity.UserID() == userID && identity.Provider() == provider {
+			r.identities = append(r.identities[:i], r.identities[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.Wrap(repo.ErrObjectNotFound, "identity not exists")
+}
+
+func (r *fakeUserIdentityRepository) GetByProviderSubject(_ context.Context, provider string, subject string) (*entities.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, identity := range r.identities {
+		if identity.Provider() == provider && identity.Subject() == subject {
+			return &identity, nil
+		}
+	}
+
+	return nil, errors.Wrap(repo.ErrObjectNotFound, "identity not exists")
+}
+
+func (r *fakeUserIdentityRepository) ListByUserID(_ context.Context, userID uuid.UUID) ([]entities.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var identities []entities.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserID() == userID {
+			identities = append(identities, identity)
+		}
+	}
+
+	return identities, nil
+}
+
+type fakeRoleRepository struct {
+	mu          sync.Mutex
+	roles       map[string]*entities.Role
+	assignments map[uuid.UUID][]uuid.UUID
+}
+
+func newFakeRoleRepository(roles ...*entities.Role) *fakeRoleRepository {
+	r := &fakeRoleRepository{
+		roles:       make(map[string]*entities.Role),
+		assignments: make(map[uuid.UUID][]uuid.UUID),
+	}
+
+	for _, role := range roles {
+		r.roles[role.Name()] = role
+	}
+
+	return r
+}
+
+func (r *fakeRoleRepository) Create(_ context.Context, role *entities.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.roles[role.Name()]; ok {
+		return errors.Wrap(repo.ErrDuplicate, "role already exists")
+	}
+
+	r.roles[role.Name()] = role
+
+	return nil
+}
+
+func (r *fakeRoleRepository) GetByName(_ context.Context, name string) (*entities.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "role not exists")
+	}
+
+	return role, nil
+}
+
+func (r *fakeRoleRepository) List(_ context.Context) ([]*entities.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	roles := make([]*entities.Role, 0, len(r.roles))
+	for _, role := range r.roles {
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+func (r *fakeRoleRepository) ListByUserID(_ context.Context, userID uuid.UUID) ([]*entities.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var roles []*entities.Role
+	for _, roleID := range r.assignments[userID] {
+		for _, role := range r.roles {
+			if role.ID() == roleID {
+				roles = append(roles, role)
+			}
+		}
+	}
+
+	return roles, nil
+}
+
+func (r *fakeRoleRepository) Assign(_ context.Context, userID uuid.UUID, roleID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slices.Contains(r.assignments[userID], roleID) {
+		return nil
+	}
+
+	r.assignments[userID] = append(r.assignments[userID], roleID)
+
+	return nil
+}
+
+func (r *fakeRoleRepository) Unassign(_ context.Context, userID uuid.UUID, roleID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, id := range r.assignments[userID] {
+		if id == roleID {
+			r.assignments[userID] = append(r.assignments[userID][:i], r.assignments[userID][i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.Wrap(repo.ErrObjectNotFound, "role is not assigned")
+}
+
+type fakeWebAuthnCredentialRepository struct {
+	mu          sync.Mutex
+	credentials []*entities.WebAuthnCredential
+}
+
+func (r *fakeWebAuthnCredentialRepository) Create(_ context.Context, credential *entities.WebAuthnCredential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.credentials = append(r.credentials, credential)
+
+	return nil
+}
+
+func (r *fakeWebAuthnCredentialRepository) GetByID(_ context.Context, id []byte) (*entities.WebAuthnCredential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, credential := range r.credentials {
+		if string(credential.ID()) == string(id) {
+			return credential, nil
+		}
+	}
+
+	return nil, errors.Wrap(repo.ErrObjectNotFound, "credential not exists")
+}
+
+func (r *fakeWebAuthnCredentialRepository) ListByUserID(_ context.Context, userID uuid.UUID) ([]*entities.WebAuthnCredential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var credentials []*entities.WebAuthnCredential
+	for _, credential := range r.credentials {
+		if credential.UserID() == userID {
+			credentials = append(credentials, credential)
+		}
+	}
+
+	return credentials, nil
+}
+
+func (r *fakeWebAuthnCredentialRepository) UpdateUsage(_ context.Context, _ *entities.WebAuthnCredential) error {
+	return nil
+}
+
+func (r *fakeWebAuthnCredentialRepository) Delete(_ context.Context, userID uuid.UUID, id []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, credential := range r.credentials {
+		if credential.UserID() == userID && string(credential.ID()) == string(id) {
+			r.credentials = append(r.credentials[:i], r.credentials[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.Wrap(repo.ErrObjectNotFound, "credential not exists")
+}
+
+type fakeWebAuthnSessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]vobjects.WebAuthnSession
+}
+
+func newFakeWebAuthnSessionRepository() *fakeWebAuthnSessionRepository {
+	return &fakeWebAuthnSessionRepository{
+		sessions: make(map[string]vobjects.WebAuthnSession),
+	}
+}
+
+func (r *fakeWebAuthnSessionRepository) Create(_ context.Context, session *vobjects.WebAuthnSession, _ time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[string(session.Challenge())] = *session
+
+	return nil
+}
+
+func (r *fakeWebAuthnSessionRepository) Pop(ctx context.Context, challenge []byte) (*vobjects.WebAuthnSession, error) {
+	if inFakeTransaction(ctx) {
+		return nil, errCalledInTransaction
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[string(challenge)]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "webauthn session not exists")
+	}
+
+	delete(r.sessions, string(challenge))
+
+	return &session, nil
+}
+
+type fakeEmailCodeRepository struct {
+	mu       sync.Mutex
+	codes    map[string]vobjects.EmailCode
+	attempts map[string]int
+}
+
+func newFakeEmailCodeRepository() *fakeEmailCodeRepository {
+	return &fakeEmailCodeRepository{
+		codes:    make(map[string]vobjects.EmailCode),
+		attempts: make(map[string]int),
+	}
+}
+
+func fakeEmailCodeKey(email string, purpose vobjects.EmailCodePurpose) string {
+	return string(purpose) + ":" + strings.ToLower(email)
+}
+
+func (r *fakeEmailCodeRepository) Save(_ context.Context, code *vobjects.EmailCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := fakeEmailCodeKey(code.Email(), code.Purpose())
+	r.codes[key] = *code
+	delete(r.attempts, key)
+
+	return nil
+}
+
+func (r *fakeEmailCodeRepository) Get(_ context.Context, email string, purpose vobjects.EmailCodePurpose) (*vobjects.EmailCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.codes[fakeEmailCodeKey(email, purpose)]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "email code not exists")
+	}
+
+	return &code, nil
+}
+
+func (r *fakeEmailCodeRepository) AddAttempt(_ context.Context, email string, purpose vobjects.EmailCodePurpose) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := fakeEmailCodeKey(email, purpose)
+	if _, ok := r.codes[key]; !ok {
+		return 0, errors.Wrap(repo.ErrObjectNotFound, "email code not exists")
+	}
+
+	r.attempts[key]++
+
+	return r.attempts[key], nil
+}
+
+func (r *fakeEmailCodeRepository) Consume(_ context.Context, code *vobjects.EmailCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := fakeEmailCodeKey(code.Email(), code.Purpose())
+
+	stored, ok := r.codes[key]
+	if !ok || stored.Code() != code.Code() {
+		return errors.Wrap(repo.ErrObjectNotFound, "email code not exists")
+	}
+
+	delete(r.codes, key)
+	delete(r.attempts, key)
+
+	return nil
+}
+
+func (r *fakeEmailCodeRepository) Delete(_ context.Context, email string, purpose vobjects.EmailCodePurpose) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := fakeEmailCodeKey(email, purpose)
+	delete(r.codes, key)
+	delete(r.attempts, key)
+
+	return nil
+}
+
+type fakeEmailThrottleRepository struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func newFakeEmailThrottleRepository() *fakeEmailThrottleRepository {
+	return &fakeEmailThrottleRepository{
+		counts: make(map[string]int64),
+	}
+}
+
+func (r *fakeEmailThrottleRepository) Hit(ctx context.Context, email string, _ time.Duration) (int64, error) {
+	if inFakeTransaction(ctx) {
+		return 0, errCalledInTransaction
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counts[strings.ToLower(email)]++
+
+	return r.counts[strings.ToLower(email)], nil
+}
+
+type fakeInvitationRepository struct {
+	mu          sync.Mutex
+	invitations map[uuid.UUID]entities.Invitation
+}
+
+func newFakeInvitationRepository() *fakeInvitationRepository {
+	return &fakeInvitationRepository{
+		invitations: make(map[uuid.UUID]entities.Invitation),
+	}
+}
+
+func (r *fakeInvitationRepository) Create(_ context.Context, invitation *entities.Invitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.invitations {
+		if strings.EqualFold(existing.Email(), invitation.Email()) {
+			return errors.Wrap(repo.ErrDuplicate, "invitation already exists")
+		}
+	}
+
+	r.invitations[invitation.ID()] = *invitation
+
+	return nil
+}
+
+func (r *fakeInvitationRepository) GetByID(_ context.Context, id uuid.UUID) (*entities.Invitation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "invitation not exists")
+	}
+
+	return &invitation, nil
+}
+
+func (r *fakeInvitationRepository) GetByEmail(_ context.Context, email string) (*entities.Invitation, error) {
+	return r.find(func(invitation entities.Invitation) bool {
+		return strings.EqualFold(invitation.Email(), email)
+	})
+}
+
+func (r *fakeInvitationRepository) GetByToken(_ context.Context, token string) (*entities.Invitation, error) {
+	return r.find(func(invitation entities.Invitation) bool {
+		return invitation.Token() == token
+	})
+}
+
+func (r *fakeInvitationRepository) find(match func(entities.Invitation) bool) (*entities.Invitation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, invitation := range r.invitations {
+		if match(invitation) {
+			return &invitation, nil
+		}
+	}
+
+	return nil, errors.Wrap(repo.ErrObjectNotFound, "invitation not exists")
+}
+
+func (r *fakeInvitationRepository) List(_ context.Context) ([]*entities.Invitation, error) {
+	return r.list(func(entities.Invitation) bool {
+		return true
+	}), nil
+}
+
+func (r *fakeInvitationRepository) ListByInviterID(_ context.Context, inviterID uuid.UUID) ([]*entities.Invitation, error) {
+	return r.list(func(invitation entities.Invitation) bool {
+		return invitation.InviterID() == inviterID
+	}), nil
+}
+
+func (r *fakeInvitationRepository) list(match func(entities.Invitation) bool) []*entities.Invitation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var invitations []*entities.Invitation
+	for _, invitation := range r.invitations {
+		if match(invitation) {
+			invitations = append(invitations, &invitation)
+		}
+	}
+
+	return invitations
+}
+
+func (r *fakeInvitationRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.invitations[id]; !ok {
+		return errors.Wrap(repo.ErrObjectNotFound, "invitation not exists")
+	}
+
+	delete(r.invitations, id)
+
+	return nil
+}
+
+type fakeMFARecoveryCodeRepository struct {
+	mu     sync.Mutex
+	hashes map[uuid.UUID]map[string]bool
+}
+
+func newFakeMFARecoveryCodeRepository() *fakeMFARecoveryCodeRepository {
+	return &fakeMFARecoveryCodeRepository{
+		hashes: make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+func (r *fakeMFARecoveryCodeRepository) Replace(_ context.Context, userID uuid.UUID, codeHashes []string, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hashes[userID] = make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		r.hashes[userID][hash] = false
+	}
+
+	return nil
+}
+
+func (r *fakeMFARecoveryCodeRepository) Use(_ context.Context, userID uuid.UUID, codeHash string, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	used, ok := r.hashes[userID][codeHash]
+	if !ok || used {
+		return errors.Wrap(repo.ErrObjectNotFound, "recovery code not exists")
+	}
+
+	r.hashes[userID][codeHash] = true
+
+	return nil
+}
+
+func (r *fakeMFARecoveryCodeRepository) CountUnused(_ context.Context, userID uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for _, used := range r.hashes[userID] {
+		if !used {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+type fakeOAuthRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]vobjects.OAuthRefreshToken
+}
+
+func newFakeOAuthRefreshTokenRepository() *fakeOAuthRefreshTokenRepository {
+	return &fakeOAuthRefreshTokenRepository{
+		tokens: make(map[string]vobjects.OAuthRefreshToken),
+	}
+}
+
+func (r *fakeOAuthRefreshTokenRepository) Create(_ context.Context, token *vobjects.OAuthRefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[token.Token()] = *token
+
+	return nil
+}
+
+func (r *fakeOAuthRefreshTokenRepository) Pop(_ context.Context, token string) (*vobjects.OAuthRefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	refreshToken, ok := r.tokens[token]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "refresh token not exists")
+	}
+
+	delete(r.tokens, token)
+
+	return &refreshToken, nil
+}
+
+func (r *fakeOAuthRefreshTokenRepository) DeleteByUserID(_ context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for token, refreshToken := range r.tokens {
+		if refreshToken.UserID() == userID {
+			delete(r.tokens, token)
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeOAuthRefreshTokenRepository) ListByUserID(_ context.Context, userID uuid.UUID) ([]*vobjects.OAuthRefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tokens []*vobjects.OAuthRefreshToken
+	for _, refreshToken := range r.tokens {
+		if refreshToken.UserID() == userID {
+			tokens = append(tokens, &refreshToken)
+		}
+	}
+
+	return tokens, nil
+}
+
+type fakeMagicLinkRepository struct {
+	mu     sync.Mutex
+	tokens map[string]vobjects.MagicLinkToken
+}
+
+func newFakeMagicLinkRepository() *fakeMagicLinkRepository {
+	return &fakeMagicLinkRepository{
+		tokens: make(map[string]vobjects.MagicLinkToken),
+	}
+}
+
+func (r *fakeMagicLinkRepository) Create(_ context.Context, token *vobjects.MagicLinkToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[token.Token()] = *token
+
+	return nil
+}
+
+func (r *fakeMagicLinkRepository) Pop(_ context.Context, token string) (*vobjects.MagicLinkToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	magicLink, ok := r.tokens[token]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "magic link not exists")
+	}
+
+	delete(r.tokens, token)
+
+	return &magicLink, nil
+}
+
+// LastToken returns the token of a link sent to the email.
+func (r *fakeMagicLinkRepository) LastToken(email string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for token, magicLink := range r.tokens {
+		if magicLink.Email() == email {
+			return token
+		}
+	}
+
+	return ""
+}
+
+// testTokenSigner signs tokens with the test secret key instead of the
+// service keys.
+type testTokenSigner struct{}
+
+func (testTokenSigner) Sign(claims jwt.Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testSecretManager{}.SecretKey().Get())
+}
+
+func (testTokenSigner) PublicKeys() []PublicKey {
+	return nil
+}
+
+type fakeOAuthClientRepository struct {
+	mu      sync.Mutex
+	clients map[string]*entities.OAuthClient
+}
+
+func newFakeOAuthClientRepository() *fakeOAuthClientRepository {
+	return &fakeOAuthClientRepository{
+		clients: make(map[string]*entities.OAuthClient),
+	}
+}
+
+func (r *fakeOAuthClientRepository) Create(_ context.Context, client *entities.OAuthClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clients[client.ID()] = client
+
+	return nil
+}
+
+func (r *fakeOAuthClientRepository) GetByID(_ context.Context, clientID string) (*entities.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.clients[clientID]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "client not exists")
+	}
+
+	return client, nil
+}
+
+type fakeOAuthConsentRepository struct {
+	mu       sync.Mutex
+	consents map[string]entities.OAuthConsent
+}
+
+func newFakeOAuthConsentRepository() *fakeOAuthConsentRepository {
+	return &fakeOAuthConsentRepository{
+		consents: make(map[string]entities.OAuthConsent),
+	}
+}
+
+func (r *fakeOAuthConsentRepository) Save(_ context.Context, consent *entities.OAuthConsent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.consents[consent.UserID().String()+consent.ClientID()] = *consent
+
+	return nil
+}
+
+func (r *fakeOAuthConsentRepository) Get(_ context.Context, userID uuid.UUID, clientID string) (*entities.OAuthConsent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	consent, ok := r.consents[userID.String()+clientID]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "consent not exists")
+	}
+
+	return &consent, nil
+}
+
+func (r *fakeOAuthConsentRepository) ListByUserID(_ context.Context, userID uuid.UUID) ([]*entities.OAuthConsent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var consents []*entities.OAuthConsent
+	for _, consent := range r.consents {
+		if consent.UserID() == userID {
+			consents = append(consents, &consent)
+		}
+	}
+
+	return consents, nil
+}
+
+type fakeAuthorizationRequestRepository struct {
+	mu       sync.Mutex
+	requests map[string]vobjects.AuthorizationRequest
+}
+
+func newFakeAuthorizationRequestRepository() *fakeAuthorizationRequestRepository {
+	return &fakeAuthorizationRequestRepository{
+		requests: make(map[string]vobjects.AuthorizationRequest),
+	}
+}
+
+func (r *fakeAuthorizationRequestRepository) Create(_ context.Context, request *vobjects.AuthorizationRequest, _ time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests[request.ID()] = *request
+
+	return nil
+}
+
+func (r *fakeAuthorizationRequestRepository) Get(_ context.Context, id string) (*vobjects.AuthorizationRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request, ok := r.requests[id]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "authorization request not exists")
+	}
+
+	return &request, nil
+}
+
+func (r *fakeAuthorizationRequestRepository) Pop(_ context.Context, id string) (*vobjects.AuthorizationRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request, ok := r.requests[id]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "authorization request not exists")
+	}
+
+	delete(r.requests, id)
+
+	return &request, nil
+}
+
+type fakeAuthorizationCodeRepository struct {
+	mu    sync.Mutex
+	codes map[string]vobjects.AuthorizationCode
+}
+
+func newFakeAuthorizationCodeRepository() *fakeAuthorizationCodeRepository {
+	return &fakeAuthorizationCodeRepository{
+		codes: make(map[string]vobjects.AuthorizationCode),
+	}
+}
+
+func (r *fakeAuthorizationCodeRepository) Create(_ context.Context, code *vobjects.AuthorizationCode, _ time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codes[code.Code()] = *code
+
+	return nil
+}
+
+func (r *fakeAuthorizationCodeRepository) Pop(_ context.Context, code string) (*vobjects.AuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	authorizationCode, ok := r.codes[code]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "authorization code not exists")
+	}
+
+	delete(r.codes, code)
+
+	return &authorizationCode, nil
+}
+
+type fakeLoginCodeRepository struct {
+	mu    sync.Mutex
+	codes map[string]vobjects.LoginCode
+}
+
+func newFakeLoginCodeRepository() *fakeLoginCodeRepository {
+	return &fakeLoginCodeRepository{
+		codes: make(map[string]vobjects.LoginCode),
+	}
+}
+
+func (r *fakeLoginCodeRepository) Create(_ context.Context, code *vobjects.LoginCode, _ time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codes[code.Code()] = *code
+
+	return nil
+}
+
+func (r *fakeLoginCodeRepository) Pop(_ context.Context, code string) (*vobjects.LoginCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loginCode, ok := r.codes[code]
+	if !ok {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "login code not exists")
+	}
+
+	delete(r.codes, code)
+
+	return &loginCode, nil
+}
+
+type fakeAdminAuditLogRepository struct {
+	mu      sync.Mutex
+	records []entities.AdminAuditRecord
+}
+
+func (r *fakeAdminAuditLogRepository) Create(_ context.Context, record *entities.AdminAuditRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = append(r.records, *record)
+
+	return nil
+}
+
+func (r *fakeAdminAuditLogRepository) ListByTargetUserID(_ context.Context, userID uuid.UUID) ([]*entities.AdminAuditRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var records []*entities.AdminAuditRecord
+	for _, record := range r.records {
+		if record.TargetUserID() == userID {
+			records = append(records, &record)
+		}
+	}
+
+	return records, nil
+}
+
+type fakeDataExportRepository struct {
+	mu      sync.Mutex
+	exports []entities.DataExport
+}
+
+func (r *fakeDataExportRepository) Create(_ context.Context, export *entities.DataExport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.exports = append(r.exports, *export)
+
+	return nil
+}
+
+func (r *fakeDataExportRepository) Update(_ context.Context, export *entities.DataExport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.exports {
+		if r.exports[i].ID() == export.ID() {
+			r.exports[i] = *export
+			return nil
+		}
+	}
+
+	return errors.Wrap(repo.ErrObjectNotFound, "data export not exists")
+}
+
+func (r *fakeDataExportRepository) GetLatestByUserID(_ context.Context, userID uuid.UUID) (*entities.DataExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.exports) - 1; i >= 0; i-- {
+		if r.exports[i].UserID() == userID {
+			export := r.exports[i]
+			return &export, nil
+		}
+	}
+
+	return nil, errors.Wrap(repo.ErrObjectNotFound, "data export not exists")
+}
+
+func (r *fakeDataExportRepository) ListPending(_ context.Context, limit int32) ([]*entities.DataExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var exports []*entities.DataExport
+	for _, export := range r.exports {
+		if export.Pending() && len(exports) < int(limit) {
+			exports = append(exports, &export)
+		}
+	}
+
+	return exports, nil
+}
+
+type fakeEmailChangeRepository struct {
+	mu      sync.Mutex
+	changes map[uuid.UUID]entities.EmailChange
+}
+
+func newFakeEmailChangeRepository() *fakeEmailChangeRepository {
+	return &fakeEmailChangeRepository{
+		changes: make(map[uuid.UUID]entities.EmailChange),
+	}
+}
+
+func (r *fakeEmailChangeRepository) Save(_ context.Context, change *entities.EmailChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes[change.UserID()] = *change
+
+	return nil
+}
+
+func (r *fakeEmailChangeRepository) GetByToken(_ context.Context, token string) (*entities.EmailChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, change := range r.changes {
+		if change.Token() == token {
+			return &change, nil
+		}
+	}
+
+	return nil, errors.Wrap(repo.ErrObjectNotFound, "email change not exists")
+}
+
+func (r *fakeEmailChangeRepository) Delete(_ context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.changes, userID)
+
+	return nil
+}
+
+// Token returns the token of the pending email change of the user.
+func (r *fakeEmailChangeRepository) Token(userID uuid.UUID) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	change := r.changes[userID]
+
+	return change.Token()
+}
+
+// fakeReferralCodeRepository drops expired codes, like the cache does.
+type fakeReferralCodeRepository struct {
+	mu    sync.Mutex
+	codes []vobjects.ReferralCode
+}
+
+func (r *fakeReferralCodeRepository) Create(_ context.Context, code *vobjects.ReferralCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codes = append(r.codes, *code)
+
+	return nil
+}
+
+func (r *fakeReferralCodeRepository) GetByCode(_ context.Context, code string) (*vobjects.ReferralCode, error) {
+	return r.find(func(referralCode vobjects.ReferralCode) bool {
+		return referralCode.Code() == code
+	})
+}
+
+func (r *fakeReferralCodeRepository) GetByUserID(_ context.Context, userID uuid.UUID) (*vobjects.ReferralCode, error) {
+	return r.find(func(referralCode vobjects.ReferralCode) bool {
+		return referralCode.UserID() == userID
+	})
+}
+
+func (r *fakeReferralCodeRepository) find(match func(vobjects.ReferralCode) bool) (*vobjects.ReferralCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, code := range r.codes {
+		if match(code) && code.ExpiredAt().After(time.Now()) {
+			return &code, nil
+		}
+	}
+
+	return nil, errors.Wrap(repo.ErrObjectNotFound, "referral code not exists")
+}
+
+type fakeReferralRepository struct {
+	mu        sync.Mutex
+	referrals []entities.Referral
+}
+
+func (r *fakeReferralRepository) Create(_ context.Context, referral *entities.Referral) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.referrals = append(r.referrals, *referral)
+
+	return nil
+}
+
+func (r *fakeReferralRepository) CountByReferrerID(_ context.Context, referrerID uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for _, referral := range r.referrals {
+		if referral.ReferrerID() == referrerID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// fakeOAuthStateRepository drops states after their TTL, like the Redis keys.
+type fakeOAuthStateRepository struct {
+	mu        sync.Mutex
+	states    map[string]vobjects.OAuthState
+	expiresAt map[string]time.Time
+}
+
+func newFakeOAuthStateRepository() *fakeOAuthStateRepository {
+	return &fakeOAuthStateRepository{
+		states:    make(map[string]vobjects.OAuthState),
+		expiresAt: make(map[string]time.Time),
+	}
+}
+
+func (r *fakeOAuthStateRepository) Create(_ context.Context, state *vobjects.OAuthState, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.states[state.State()] = *state
+	r.expiresAt[state.State()] = time.Now().Add(ttl)
+
+	return nil
+}
+
+func (r *fakeOAuthStateRepository) Pop(_ context.Context, state string) (*vobjects.OAuthState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	oauthState, ok := r.states[state]
+	if !ok || !time.Now().Before(r.expiresAt[state]) {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "oauth state not exists")
+	}
+
+	delete(r.states, state)
+	delete(r.expiresAt, state)
+
+	return &oauthState, nil
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RegistrationMode controls who can sign up.
+type RegistrationMode string
+
+const (
+	// RegistrationOpen lets anyone sign up.
+	RegistrationOpen RegistrationMode = "open"
+	// RegistrationInviteOnly lets only invited emails sign up.
+	RegistrationInviteOnly RegistrationMode = "invite_only"
+	// RegistrationClosed disables sign-up and invitations.
+	RegistrationClosed RegistrationMode = "closed"
+)
+
+type InvitationServiceConfig struct {
+	Mode RegistrationMode
+	TTL  time.Duration
+}
+
+// InvitationService manages invitations and decides whether a new user can
+// sign up under the registration mode. An invitation is accepted with its
+// token, or by signing up with the invited email through a login that verifies
+// the email.
+type InvitationService struct {
+	repository          repo.InvitationRepository
+	userRepository      repo.UserRepository
+	roleRepository      repo.RoleRepository
+	txManager           repo.TransactionManager
+	emailPolicy         EmailDomainPolicy
+	emailThrottle       *EmailThrottle
+	invitationMsgSender MessageSender
+	log                 *slog.Logger
+	tracer              trace.Tracer
+	cfg                 InvitationServiceConfig
+}
+
+func NewInvitationService(
+	repository repo.InvitationRepository,
+	userRepository repo.UserRepository,
+	roleRepository repo.RoleRepository,
+	txManager repo.TransactionManager,
+	emailPolicy EmailDomainPolicy,
+	emailThrottle *EmailThrottle,
+	invitationMsgSender MessageSender,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg InvitationServiceConfig,
+) *InvitationService {
+	return &InvitationService{
+		repository:          repository,
+		userRepository:      userRepository,
+		roleRepository:      roleRepository,
+		txManager:           txManager,
+		emailPolicy:         emailPolicy,
+		emailThrottle:       emailThrottle,
+		invitationMsgSender: invitationMsgSender,
+		log:                 log,
+		tracer:              tracer,
+		cfg:                 cfg,
+	}
+}
+
+// Invite sends an invitation to the email. An empty role means the invited
+// user gets no role. An expired invitation to the same email is replaced.
+func (s *InvitationService) Invite(ctx context.Context, inviterID uuid.UUID, email string, role string) (*entities.Invitation, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationService.Invite")
+	defer span.End()
+
+	if s.cfg.Mode == RegistrationClosed {
+		return nil, errors.Wrap(ErrRegistrationClosed, "invitations are disabled")
+	}
+
+	if !s.emailPolicy.Allowed(email) {
+		return nil, errors.Wrapf(ErrEmailDomainNotAllowed, "email domain of %s is not allowed", email)
+	}
+
+	// The email is counted before the transaction, a retried transaction
+	// would count it again.
+	if err := s.emailThrottle.Allow(ctx, email); err != nil {
+		return nil, err
+	}
+
+	var invitation *entities.Invitation
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if role != "" {
+			if _, err := s.roleRepository.GetByName(ctx, role); err != nil {
+				return err
+			}
+		}
+
+		if _, err := s.userRepository.GetByEmail(ctx, email); err == nil {
+			return errors.Wrapf(repo.ErrDuplicate, "user with email = %s already exists", email)
+		} else if !errors.Is(err, repo.ErrObjectNotFound) {
+			return err
+		}
+
+		existing, err := s.repository.GetByEmail(ctx, email)
+		if err == nil {
+			if existing.Valid() {
+				return errors.Wrapf(repo.ErrDuplicate, "invitation for email = %s already exists", email)
+			}
+
+			if err := s.repository.Delete(ctx, existing.ID()); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, repo.ErrObjectNotFound) {
+			return err
+		}
+
+		invitation = entities.NewInvitation(inviterID, email, role, s.cfg.TTL)
+
+		if err := s.repository.Create(ctx, invitation); err != nil {
+			return err
+		}
+
+		invitationMsg := InvitationMessage{
+			Email:     invitation.Email(),
+			InviterID: invitation.InviterID(),
+			Role:      invitation.Role(),
+			Token:     invitation.Token(),
+			ExpiresAt: invitation.ExpiredAt(),
+		}
+
+		return s.invitationMsgSender.SendMessage(ctx, invitationMsg)
+	}); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (s *InvitationService) List(ctx context.Context) ([]*entities.Invitation, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationService.List")
+	defer span.End()
+
+	return s.repository.List(ctx)
+}
+
+func (s *InvitationService) ListByInviter(ctx context.Context, inviterID uuid.UUID) ([]*entities.Invitation, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationService.ListByInviter")
+	defer span.End()
+
+	return s.repository.ListByInviterID(ctx, inviterID)
+}
+
+// Revoke deletes any invitation.
+func (s *InvitationService) Revoke(ctx context.Context, id uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "InvitationService.Revoke")
+	defer span.End()
+
+	return s.repository.Delete(ctx, id)
+}
+
+// RevokeOwn deletes an invitation sent by the inviter, invitations of other
+// users are reported as not existing.
+func (s *InvitationService) RevokeOwn(ctx context.Context, inviterID uuid.UUID, id uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "InvitationService.RevokeOwn")
+	defer span.End()
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		invitation, err := s.repository.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if invitation.InviterID() != inviterID {
+			return errors.Wrap(repo.ErrObjectNotFound, "invitation not exists")
+		}
+
+		return s.repository.Delete(ctx, id)
+	})
+}
+
+// GetByToken returns the valid invitation with the token.
+func (s *InvitationService) GetByToken(ctx context.Context, token string) (*entities.Invitation, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationService.GetByToken")
+	defer span.End()
+
+	if s.cfg.Mode == RegistrationClosed {
+		return nil, errors.Wrap(ErrRegistrationClosed, "registration is closed")
+	}
+
+	invitation, err := s.repository.GetByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrap(ErrInvalidInvitation, "invitation not exists")
+		}
+
+		return nil, err
+	}
+
+	if !invitation.Valid() {
+		return nil, errors.Wrap(ErrInvalidInvitation, "invitation is expired")
+	}
+
+	return invitation, nil
+}
+
+// SignUp signs up the invited user with a password. The email is confirmed by
+// the invitation and the user gets the invited role.
+func (s *InvitationService) SignUp(ctx context.Context, token string, passwordStr string) (*entities.User, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationService.SignUp")
+	defer span.End()
+
+	invitation, err := s.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *entities.User
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		password, err := vobjects.NewPassword(passwordStr)
+		if err != nil {
+			return err
+		}
+
+		user = entities.NewUser(invitation.Email(), password)
+		user.ForceConfirm()
+
+		if err := s.userRepository.Create(ctx, user); err != nil {
+			return err
+		}
+
+		return s.Accept(ctx, invitation, user)
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CheckOpenSignUp reports whether a user can sign up without an invitation,
+// as with a password sign-up which does not verify the email.
+func (s *InvitationService) CheckOpenSignUp() error {
+	if s.cfg.Mode != RegistrationOpen {
+		return errors.Wrap(ErrRegistrationClosed, "registration is invite-only or closed")
+	}
+
+	return nil
+}
+
+// SignUpInvitation checks that a new user can sign up with the email and
+// returns the pending invitation to it, if any. The invitation is used only
+// when the sign-up verifies the email, in the invite-only mode a sign-up
+// without one is rejected.
+func (s *InvitationService) SignUpInvitation(ctx context.Context, email string, emailVerified bool) (*entities.Invitation, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationService.SignUpInvitation")
+	defer span.End()
+
+	if s.cfg.Mode == RegistrationClosed {
+		return nil, errors.Wrap(ErrRegistrationClosed, "registration is closed")
+	}
+
+	if emailVerified {
+		invitation, err := s.repository.GetByEmail(ctx, email)
+		if err == nil && invitation.Valid() {
+			return invitation, nil
+		}
+
+		if err != nil && !errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, err
+		}
+	}
+
+	if s.cfg.Mode == RegistrationInviteOnly {
+		return nil, errors.Wrapf(ErrRegistrationClosed, "no invitation for email = %s", email)
+	}
+
+	return nil, nil
+}
+
+// Accept assigns the invited role to the new user and deletes the invitation,
+// it runs in the transaction creating the user.
+func (s *InvitationService) Accept(ctx context.Context, invitation *entities.Invitation, user *entities.User) error {
+	ctx, span := s.tracer.Start(ctx, "InvitationService.Accept")
+	defer span.End()
+
+	if invitation.Role() != "" {
+		role, err := s.roleRepository.GetByName(ctx, invitation.Role())
+		if err != nil {
+			return err
+		}
+
+		if err := s.roleRepository.Assign(ctx, user.ID(), role.ID()); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repository.Delete(ctx, invitation.ID()); err != nil {
+		return err
+	}
+
+	s.log.Info("invitation accepted", slog.String("email", user.Email()), slog.String("role", invitation.Role()))
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/stretchr/testify/require"
+)
+
+const testRoleName = "editor"
+
+// createRole stores a role the invitations can grant.
+func (tt *authServiceTest) createRole(t *testing.T) *entities.Role {
+	t.Helper()
+
+	role := entities.NewRole(testRoleName, "", []string{entities.PermissionUsersRead})
+	require.NoError(t, tt.roles.Create(context.Background(), role))
+
+	return role
+}
+
+// requireRoles checks the names of the roles assigned to the user.
+func (tt *authServiceTest) requireRoles(t *testing.T, userID uuid.UUID, names ...string) {
+	t.Helper()
+
+	roles, err := tt.roles.ListByUserID(context.Background(), userID)
+	require.NoError(t, err)
+
+	assigned := make([]string, 0, len(roles))
+	for _, role := range roles {
+		assigned = append(assigned, role.Name())
+	}
+
+	require.ElementsMatch(t, names, assigned)
+}
+
+func TestInvitationServiceInvite(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationInviteOnly)
+	tt.createRole(t)
+	inviterID := uuid.New()
+
+	invitation, err := tt.invitationService.Invite(ctx, inviterID, testUserEmail, testRoleName)
+	require.NoError(t, err)
+	require.Equal(t, inviterID, invitation.InviterID())
+	require.Equal(t, testRoleName, invitation.Role())
+
+	_, err = tt.invitationService.Invite(ctx, inviterID, testUserEmail, "")
+	require.ErrorIs(t, err, repo.ErrDuplicate)
+
+	_, err = tt.invitationService.Invite(ctx, inviterID, "other@example.com", "unknown")
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	tt.createUser(t, "user2@example.com")
+
+	_, err = tt.invitationService.Invite(ctx, inviterID, "user2@example.com", "")
+	require.ErrorIs(t, err, repo.ErrDuplicate)
+}
+
+func TestInvitationServiceInviteReplacesExpired(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationInviteOnly)
+
+	expired := entities.NewInvitation(uuid.New(), testUserEmail, "", -time.Minute)
+	require.NoError(t, tt.invitations.Create(ctx, expired))
+
+	invitation, err := tt.invitationService.Invite(ctx, uuid.New(), testUserEmail, "")
+	require.NoError(t, err)
+
+	_, err = tt.invitations.GetByID(ctx, expired.ID())
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	invitations, err := tt.invitationService.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	require.Equal(t, invitation.ID(), invitations[0].ID())
+}
+
+func TestInvitationServiceClosed(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationClosed)
+
+	_, err := tt.invitationService.Invite(ctx, uuid.New(), testUserEmail, "")
+	require.ErrorIs(t, err, ErrRegistrationClosed)
+
+	invitation := entities.NewInvitation(uuid.New(), testUserEmail, "", time.Hour)
+	require.NoError(t, tt.invitations.Create(ctx, invitation))
+
+	_, err = tt.invitationService.SignUp(ctx, invitation.Token(), testUserPassword)
+	require.ErrorIs(t, err, ErrRegistrationClosed)
+
+	_, err = tt.registrationService.Register(ctx, "user2@example.com", testUserPassword, "")
+	require.ErrorIs(t, err, ErrRegistrationClosed)
+
+	require.NoError(t, tt.emailCodeService.SendLoginCode(ctx, "user2@example.com"))
+
+	_, err = tt.codeLogin(ctx, "user2@example.com", tt.lastCode(t, "user2@example.com", vobjects.EmailCodeLogin), AccessTokenRequest{})
+	require.ErrorIs(t, err, ErrRegistrationClosed)
+}
+
+func TestInvitationServiceInviteOnlySignUp(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationInviteOnly)
+	tt.createRole(t)
+
+	_, err := tt.registrationService.Register(ctx, testUserEmail, testUserPassword, "")
+	require.ErrorIs(t, err, ErrRegistrationClosed)
+
+	// A login which verifies the email signs up only an invited user.
+	require.NoError(t, tt.emailCodeService.SendLoginCode(ctx, testUserEmail))
+
+	_, err = tt.codeLogin(ctx, testUserEmail, tt.lastCode(t, testUserEmail, vobjects.EmailCodeLogin), AccessTokenRequest{})
+	require.ErrorIs(t, err, ErrRegistrationClosed)
+
+	invitation, err := tt.invitationService.Invite(ctx, uuid.New(), testUserEmail, testRoleName)
+	require.NoError(t, err)
+
+	require.NoError(t, tt.emailCodeService.SendLoginCode(ctx, testUserEmail))
+
+	_, err = tt.codeLogin(ctx, testUserEmail, tt.lastCode(t, testUserEmail, vobjects.EmailCodeLogin), AccessTokenRequest{})
+	require.NoError(t, err)
+
+	user := tt.getUser(t, testUserEmail)
+	require.True(t, user.Confirmed())
+	tt.requireRoles(t, user.ID(), testRoleName)
+
+	_, err = tt.invitations.GetByID(ctx, invitation.ID())
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+}
+
+func TestInvitationServiceAccept(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationInviteOnly)
+	tt.createRole(t)
+
+	invitation, err := tt.invitationService.Invite(ctx, uuid.New(), testUserEmail, testRoleName)
+	require.NoError(t, err)
+
+	user, err := tt.invitationService.SignUp(ctx, invitation.Token(), testUserPassword)
+	require.NoError(t, err)
+	require.Equal(t, testUserEmail, user.Email())
+	require.True(t, tt.getUser(t, testUserEmail).Confirmed())
+	tt.requireRoles(t, user.ID(), testRoleName)
+
+	_, err = tt.invitationService.SignUp(ctx, invitation.Token(), testUserPassword)
+	require.ErrorIs(t, err, ErrInvalidInvitation)
+
+	_, err = tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+}
+
+func TestInvitationServiceAcceptExpired(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationInviteOnly)
+
+	invitation := entities.NewInvitation(uuid.New(), testUserEmail, "", -time.Minute)
+	require.NoError(t, tt.invitations.Create(ctx, invitation))
+
+	_, err := tt.invitationService.SignUp(ctx, invitation.Token(), testUserPassword)
+	require.ErrorIs(t, err, ErrInvalidInvitation)
+
+	_, err = tt.users.GetByEmail(ctx, testUserEmail)
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+}
+
+func TestInvitationServiceRevokeOwn(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationInviteOnly)
+	inviterID := uuid.New()
+
+	invitation, err := tt.invitationService.Invite(ctx, inviterID, testUserEmail, "")
+	require.NoError(t, err)
+
+	err = tt.invitationService.RevokeOwn(ctx, uuid.New(), invitation.ID())
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	invitations, err := tt.invitationService.ListByInviter(ctx, inviterID)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+
+	require.NoError(t, tt.invitationService.RevokeOwn(ctx, inviterID, invitation.ID()))
+
+	invitations, err = tt.invitationService.ListByInviter(ctx, inviterID)
+	require.NoError(t, err)
+	require.Empty(t, invitations)
+
+	err = tt.invitationService.RevokeOwn(ctx, inviterID, invitation.ID())
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+}
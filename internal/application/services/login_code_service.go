@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type LoginCodeServiceConfig struct {
+	CodeTTL time.Duration
+}
+
+type LoginCodeService struct {
+	repository repo.LoginCodeRepository
+	log        *slog.Logger
+	tracer     trace.Tracer
+	cfg        LoginCodeServiceConfig
+}
+
+func NewLoginCodeService(
+	repository repo.LoginCodeRepository,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg LoginCodeServiceConfig,
+) *LoginCodeService {
+	return &LoginCodeService{
+		repository: repository,
+		log:        log,
+		tracer:     tracer,
+		cfg:        cfg,
+	}
+}
+
+// Issue issues a code for the tokens of a login, or for its MFA challenge
+// token when the user has to pass a second factor.
+func (s *LoginCodeService) Issue(ctx context.Context, result LoginResult) (*vobjects.LoginCode, error) {
+	ctx, span := s.tracer.Start(ctx, "LoginCodeService.Issue")
+	defer span.End()
+
+	code := vobjects.NewLoginCode(result.AccessToken, result.RefreshToken)
+	if result.MFARequired() {
+		code = vobjects.NewMFALoginCode(result.MFAToken)
+	}
+
+	if err := s.repository.Create(ctx, code, s.cfg.CodeTTL); err != nil {
+		return nil, err
+	}
+
+	return code, nil
+}
+
+// Exchange consumes the code, so it can be exchanged for tokens only once.
+func (s *LoginCodeService) Exchange(ctx context.Context, code string) (*vobjects.LoginCode, error) {
+	ctx, span := s.tracer.Start(ctx, "LoginCodeService.Exchange")
+	defer span.End()
+
+	loginCode, err := s.repository.Pop(ctx, code)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrap(ErrInvalidLoginCode, "login code is expired or already used")
+		}
+
+		return nil, err
+	}
+
+	return loginCode, nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLoginCodeService() *LoginCodeService {
+	return NewLoginCodeService(newFakeLoginCodeRepository(), testLogger, testTracer, LoginCodeServiceConfig{
+		CodeTTL: time.Minute,
+	})
+}
+
+func TestLoginCodeServiceExchange(t *testing.T) {
+	ctx := context.Background()
+	service := newTestLoginCodeService()
+
+	code, err := service.Issue(ctx, LoginResult{AccessToken: "access", RefreshToken: "refresh"})
+	require.NoError(t, err)
+
+	loginCode, err := service.Exchange(ctx, code.Code())
+	require.NoError(t, err)
+	require.False(t, loginCode.MFARequired())
+	require.Equal(t, "access", loginCode.AccessToken())
+	require.Equal(t, "refresh", loginCode.RefreshToken())
+
+	_, err = service.Exchange(ctx, code.Code())
+	require.ErrorIs(t, err, ErrInvalidLoginCode)
+
+	_, err = service.Exchange(ctx, "unknown")
+	require.ErrorIs(t, err, ErrInvalidLoginCode)
+}
+
+func TestLoginCodeServiceExchangeMFA(t *testing.T) {
+	ctx := context.Background()
+	service := newTestLoginCodeService()
+
+	code, err := service.Issue(ctx, LoginResult{MFAToken: "mfa"})
+	require.NoError(t, err)
+
+	loginCode, err := service.Exchange(ctx, code.Code())
+	require.NoError(t, err)
+	require.True(t, loginCode.MFARequired())
+	require.Equal(t, "mfa", loginCode.MFAToken())
+	require.Empty(t, loginCode.AccessToken())
+	require.Empty(t, loginCode.RefreshToken())
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type MagicLinkServiceConfig struct {
+	Issuer string
+	TTL    time.Duration
+}
+
+// MagicLinkService sends login links by email. A link to an email without an
+// account signs the user up, so it is sent regardless of whether the account
+// exists.
+type MagicLinkService struct {
+	repository    repo.MagicLinkRepository
+	emailPolicy   EmailDomainPolicy
+	emailThrottle *EmailThrottle
+	linkMsgSender MessageSender
+	log           *slog.Logger
+	tracer        trace.Tracer
+	cfg           MagicLinkServiceConfig
+}
+
+func NewMagicLinkService(
+	repository repo.MagicLinkRepository,
+	emailPolicy EmailDomainPolicy,
+	emailThrottle *EmailThrottle,
+	linkMsgSender MessageSender,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg MagicLinkServiceConfig,
+) *MagicLinkService {
+	return &MagicLinkService{
+		repository:    repository,
+		emailPolicy:   emailPolicy,
+		emailThrottle: emailThrottle,
+		linkMsgSender: linkMsgSender,
+		log:           log,
+		tracer:        tracer,
+		cfg:           cfg,
+	}
+}
+
+// Send emails a new login link to the address.
+func (s *MagicLinkService) Send(ctx context.Context, email string) error {
+	ctx, span := s.tracer.Start(ctx, "MagicLinkService.Send")
+	defer span.End()
+
+	if !s.emailPolicy.Allowed(email) {
+		return errors.Wrapf(ErrEmailDomainNotAllowed, "email domain of %s is not allowed", email)
+	}
+
+	if err := s.emailThrottle.Allow(ctx, email); err != nil {
+		return err
+	}
+
+	token := vobjects.NewMagicLinkToken(email, s.cfg.TTL)
+
+	if err := s.repository.Create(ctx, token); err != nil {
+		return err
+	}
+
+	linkMsg := MagicLinkMessage{
+		Email:     email,
+		Link:      s.createLink(token.Token()),
+		ExpiresAt: token.ExpiredAt(),
+	}
+
+	return s.linkMsgSender.SendMessage(ctx, linkMsg)
+}
+
+// Consume checks the token of a link, a link can be used only once.
+func (s *MagicLinkService) Consume(ctx context.Context, token string) (*vobjects.MagicLinkToken, error) {
+	ctx, span := s.tracer.Start(ctx, "MagicLinkService.Consume")
+	defer span.End()
+
+	magicLink, err := s.repository.Pop(ctx, token)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrap(ErrInvalidMagicLink, "magic link is expired or already used")
+		}
+
+		return nil, err
+	}
+
+	if !magicLink.Valid() {
+		return nil, errors.Wrap(ErrInvalidMagicLink, "magic link is expired")
+	}
+
+	return magicLink, nil
+}
+
+func (s *MagicLinkService) createLink(token string) string {
+	return fmt.Sprintf("%s/auth/magic-link/verify?token=%s", s.cfg.Issuer, url.QueryEscape(token))
+}
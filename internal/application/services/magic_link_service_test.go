@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMagicLinkService(repository *fakeMagicLinkRepository, messages *messageRecorder, throttle *EmailThrottle, ttl time.Duration) *MagicLinkService {
+	return NewMagicLinkService(repository, allowAllEmailPolicy{}, throttle, messages, testLogger, testTracer, MagicLinkServiceConfig{
+		Issuer: testIssuer,
+		TTL:    ttl,
+	})
+}
+
+func newTestEmailThrottle(limit int64) *EmailThrottle {
+	return NewEmailThrottle(newFakeEmailThrottleRepository(), EmailThrottleConfig{
+		Limit:  limit,
+		Window: time.Hour,
+	})
+}
+
+func TestMagicLinkServiceConsume(t *testing.T) {
+	ctx := context.Background()
+	repository := newFakeMagicLinkRepository()
+	messages := &messageRecorder{}
+	service := newTestMagicLinkService(repository, messages, newTestEmailThrottle(testEmailThrottleLimit), time.Minute)
+
+	require.NoError(t, service.Send(ctx, testUserEmail))
+	token := repository.LastToken(testUserEmail)
+
+	sent := messages.Messages()
+	require.Len(t, sent, 1)
+	require.Equal(t, testUserEmail, sent[0].(MagicLinkMessage).Email)
+	require.Contains(t, sent[0].(MagicLinkMessage).Link, token)
+
+	magicLink, err := service.Consume(ctx, token)
+	require.NoError(t, err)
+	require.Equal(t, testUserEmail, magicLink.Email())
+
+	_, err = service.Consume(ctx, token)
+	require.ErrorIs(t, err, ErrInvalidMagicLink)
+}
+
+func TestMagicLinkServiceConsumeExpired(t *testing.T) {
+	ctx := context.Background()
+	repository := newFakeMagicLinkRepository()
+	service := newTestMagicLinkService(repository, &messageRecorder{}, newTestEmailThrottle(testEmailThrottleLimit), -time.Minute)
+
+	require.NoError(t, service.Send(ctx, testUserEmail))
+
+	_, err := service.Consume(ctx, repository.LastToken(testUserEmail))
+	require.ErrorIs(t, err, ErrInvalidMagicLink)
+}
+
+func TestMagicLinkServiceThrottle(t *testing.T) {
+	ctx := context.Background()
+	throttle := newTestEmailThrottle(2)
+	service := newTestMagicLinkService(newFakeMagicLinkRepository(), &messageRecorder{}, throttle, time.Minute)
+
+	require.NoError(t, service.Send(ctx, testUserEmail))
+	require.NoError(t, service.Send(ctx, testUserEmail))
+
+	err := service.Send(ctx, testUserEmail)
+	require.ErrorIs(t, err, ErrTooManyEmails)
+
+	// The limit is per address and shared by everything sending emails.
+	require.NoError(t, service.Send(ctx, "other@example.com"))
+	require.ErrorIs(t, throttle.Allow(ctx, testUserEmail), ErrTooManyEmails)
+}
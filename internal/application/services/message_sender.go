@@ -1,6 +1,11 @@
 package services
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type MessageSender interface {
 	SendMessage(context.Context, any) error
@@ -13,4 +18,70 @@ type LoginMessage struct {
 type RegisterMessage struct {
 	Email       string `json:"email"`
 	ConfirmLink string `json:"confirm_link"`
+	ConfirmCode string `json:"confirm_code"`
+}
+
+type UserStatusMessage struct {
+	UserID uuid.UUID  `json:"user_id"`
+	Email  string     `json:"email"`
+	Status string     `json:"status"`
+	Reason string     `json:"reason,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+type UserDeletedMessage struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type DataExportReadyMessage struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	ExportID  uuid.UUID `json:"export_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type EmailChangeMessage struct {
+	Email       string `json:"email"`
+	ConfirmLink string `json:"confirm_link"`
+}
+
+type EmailChangedMessage struct {
+	Email    string `json:"email"`
+	NewEmail string `json:"new_email"`
+}
+
+type RecoveryCodeUsedMessage struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Email          string    `json:"email"`
+	RemainingCodes int64     `json:"remaining_codes"`
+}
+
+type MagicLinkMessage struct {
+	Email     string    `json:"email"`
+	Link      string    `json:"link"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type EmailCodeMessage struct {
+	Email     string    `json:"email"`
+	Code      string    `json:"code"`
+	Purpose   string    `json:"purpose"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ReferralRegisteredMessage struct {
+	ReferrerID   uuid.UUID `json:"referrer_id"`
+	RefereeID    uuid.UUID `json:"referee_id"`
+	RefereeEmail string    `json:"referee_email"`
+	Code         string    `json:"code"`
+}
+
+type InvitationMessage struct {
+	Email     string    `json:"email"`
+	InviterID uuid.UUID `json:"inviter_id"`
+	Role      string    `json:"role,omitempty"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
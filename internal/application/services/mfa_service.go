@@ -0,0 +1,460 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/pkg/webauthn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type MFAServiceConfig struct {
+	// Issuer is the account issuer shown in authenticator apps.
+	Issuer       string
+	ChallengeTTL time.Duration
+	// MaxAttempts is the number of invalid codes after which the challenge
+	// is dropped and the user has to log in again.
+	MaxAttempts       int
+	RecoveryCodeCount int
+}
+
+// MFAProof is a second factor sent for an MFA challenge, either a TOTP code,
+// a recovery code or a WebAuthn assertion.
+type MFAProof struct {
+	Code         string
+	RecoveryCode string
+	WebAuthn     *webauthn.AssertionResponse
+}
+
+// TOTPSetup is a new TOTP secret to be added to an authenticator app.
+type TOTPSetup struct {
+	Secret string
+	URI    string
+}
+
+type MFAService struct {
+	userRepository         repo.UserRepository
+	totpRepository         repo.UserTOTPRepository
+	recoveryCodeRepository repo.MFARecoveryCodeRepository
+	challengeRepository    repo.MFAChallengeRepository
+	webauthnService        *WebAuthnService
+	txManager              repo.TransactionManager
+	cipher                 SecretCipher
+	recoveryMsgSender      MessageSender
+	clock                  domain.Clock
+	log                    *slog.Logger
+	tracer                 trace.Tracer
+	cfg                    MFAServiceConfig
+}
+
+func NewMFAService(
+	userRepository repo.UserRepository,
+	totpRepository repo.UserTOTPRepository,
+	recoveryCodeRepository repo.MFARecoveryCodeRepository,
+	challengeRepository repo.MFAChallengeRepository,
+	webauthnService *WebAuthnService,
+	txManager repo.TransactionManager,
+	cipher SecretCipher,
+	recoveryMsgSender MessageSender,
+	clock domain.Clock,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg MFAServiceConfig,
+) *MFAService {
+	return &MFAService{
+		userRepository:         userRepository,
+		totpRepository:         totpRepository,
+		recoveryCodeRepository: recoveryCodeRepository,
+		challengeRepository:    challengeRepository,
+		webauthnService:        webauthnService,
+		txManager:              txManager,
+		cipher:                 cipher,
+		recoveryMsgSender:      recoveryMsgSender,
+		clock:                  clock,
+		log:                    log,
+		tracer:                 tracer,
+		cfg:                    cfg,
+	}
+}
+
+// SetupTOTP generates a new TOTP secret for the user. The secret is not used
+// at login until it is verified with VerifyTOTP, setting it up again replaces
+// a secret that is not verified yet.
+func (s *MFAService) SetupTOTP(ctx context.Context, userID uuid.UUID) (TOTPSetup, error) {
+	ctx, span := s.tracer.Start(ctx, "MFAService.SetupTOTP")
+	defer span.End()
+
+	var setup TOTPSetup
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.totpRepository.GetByUserID(ctx, userID)
+		if err == nil && existing.Active() {
+			return errors.Wrap(domain.ErrMFAAlreadyEnabled, "totp is already activated")
+		}
+
+		if err != nil && !errors.Is(err, repo.ErrObjectNotFound) {
+			return err
+		}
+
+		key, err := vobjects.NewTOTPKey()
+		if err != nil {
+			return err
+		}
+
+		encryptedSecret, err := s.cipher.Encrypt(key.Secret())
+		if err != nil {
+			return err
+		}
+
+		totp := entities.NewUserTOTP(userID, encryptedSecret, s.clock.Now())
+
+		if err := s.totpRepository.Save(ctx, totp); err != nil {
+			return err
+		}
+
+		setup = TOTPSetup{
+			Secret: key.Base32(),
+			URI:    key.URI(s.cfg.Issuer, user.Email()),
+		}
+
+		return nil
+	}); err != nil {
+		return TOTPSetup{}, err
+	}
+
+	return setup, nil
+}
+
+// VerifyTOTP activates the TOTP secret of the user with a code from the
+// authenticator app, from then on the code is required at login. Recovery
+// codes are generated on activation, they are returned only once.
+func (s *MFAService) VerifyTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	ctx, span := s.tracer.Start(ctx, "MFAService.VerifyTOTP")
+	defer span.End()
+
+	var recoveryCodes []string
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		totp, err := s.totpRepository.GetByUserID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repo.ErrObjectNotFound) {
+				return errors.Wrap(ErrMFANotSetUp, "totp is not set up")
+			}
+
+			return err
+		}
+
+		if totp.Active() {
+			return errors.Wrap(domain.ErrMFAAlreadyEnabled, "totp is already activated")
+		}
+
+		if err := s.checkCode(totp, code); err != nil {
+			return err
+		}
+
+		if err := totp.Activate(s.clock.Now()); err != nil {
+			return err
+		}
+
+		if err := s.totpRepository.Save(ctx, totp); err != nil {
+			return err
+		}
+
+		recoveryCodes, err = s.replaceRecoveryCodes(ctx, userID)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user with a new
+// set, the old codes stop working.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	ctx, span := s.tracer.Start(ctx, "MFAService.RegenerateRecoveryCodes")
+	defer span.End()
+
+	var recoveryCodes []string
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		enabled, err := s.Enabled(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if !enabled {
+			return errors.Wrap(ErrMFANotSetUp, "mfa is not enabled")
+		}
+
+		recoveryCodes, err = s.replaceRecoveryCodes(ctx, userID)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+func (s *MFAService) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes, err := vobjects.NewRecoveryCodes(s.cfg.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	plain := make([]string, 0, len(codes))
+	hashes := make([]string, 0, len(codes))
+
+	for _, code := range codes {
+		plain = append(plain, code.Code())
+		hashes = append(hashes, code.Hash())
+	}
+
+	if err := s.recoveryCodeRepository.Replace(ctx, userID, hashes, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+// ResetTOTP deletes the TOTP authenticator and the recovery codes of the user.
+func (s *MFAService) ResetTOTP(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "MFAService.ResetTOTP")
+	defer span.End()
+
+	if err := s.totpRepository.Delete(ctx, userID); err != nil && !errors.Is(err, repo.ErrObjectNotFound) {
+		return err
+	}
+
+	return s.recoveryCodeRepository.Replace(ctx, userID, nil, s.clock.Now())
+}
+
+// Enabled reports whether the user has to pass a second factor at login, i.e.
+// has an active TOTP secret or a registered WebAuthn credential.
+func (s *MFAService) Enabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "MFAService.Enabled")
+	defer span.End()
+
+	totp, err := s.totpRepository.GetByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, repo.ErrObjectNotFound) {
+		return false, err
+	}
+
+	if err == nil && totp.Active() {
+		return true, nil
+	}
+
+	return s.webauthnService.HasCredentials(ctx, userID)
+}
+
+// CreateChallenge starts the second step of a login, the access token request
+// is kept until the challenge is passed.
+func (s *MFAService) CreateChallenge(ctx context.Context, userID uuid.UUID, tokenRequest AccessTokenRequest) (*vobjects.MFAChallenge, error) {
+	ctx, span := s.tracer.Start(ctx, "MFAService.CreateChallenge")
+	defer span.End()
+
+	challenge := vobjects.NewMFAChallenge(userID, tokenRequest.Audience, tokenRequest.Scope)
+
+	if err := s.challengeRepository.Create(ctx, challenge, s.cfg.ChallengeTTL); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// BeginWebAuthnChallenge starts a WebAuthn assertion with the credentials of
+// the challenged user, the assertion is sent to PassChallenge.
+func (s *MFAService) BeginWebAuthnChallenge(ctx context.Context, token string) (webauthn.RequestOptions, error) {
+	ctx, span := s.tracer.Start(ctx, "MFAService.BeginWebAuthnChallenge")
+	defer span.End()
+
+	challenge, err := s.challengeRepository.Get(ctx, token)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return webauthn.RequestOptions{}, errors.Wrap(ErrInvalidMFAChallenge, "mfa challenge is expired or already used")
+		}
+
+		return webauthn.RequestOptions{}, err
+	}
+
+	return s.webauthnService.BeginAssertion(ctx, challenge.UserID())
+}
+
+// PassChallenge checks a TOTP code, a recovery code or a WebAuthn assertion
+// for the challenge. A passed challenge is consumed, a challenge with too many
+// invalid codes is dropped.
+func (s *MFAService) PassChallenge(ctx context.Context, token string, proof MFAProof) (*vobjects.MFAChallenge, error) {
+	ctx, span := s.tracer.Start(ctx, "MFAService.PassChallenge")
+	defer span.End()
+
+	challenge, err := s.challengeRepository.Get(ctx, token)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrap(ErrInvalidMFAChallenge, "mfa challenge is expired or already used")
+		}
+
+		return nil, err
+	}
+
+	// The attempt is counted before the proof is checked, so concurrent
+	// requests can't check more proofs than allowed.
+	attempts, err := s.challengeRepository.AddAttempt(ctx, token)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrap(ErrInvalidMFAChallenge, "mfa challenge is expired or already used")
+		}
+
+		return nil, err
+	}
+
+	if attempts > s.cfg.MaxAttempts {
+		if err := s.challengeRepository.Delete(ctx, token); err != nil {
+			return nil, err
+		}
+
+		return nil, errors.Wrap(ErrInvalidMFAChallenge, "too many attempts")
+	}
+
+	// A WebAuthn assertion uses up its challenge, so it is verified before the
+	// transaction, which may be retried.
+	var assertion *WebAuthnAssertion
+	if proof.RecoveryCode == "" && proof.WebAuthn != nil {
+		assertion, err = s.webauthnService.VerifyAssertion(ctx, challenge.UserID(), *proof.WebAuthn)
+		if err != nil {
+			return nil, s.failAttempt(ctx, token, attempts, invalidAssertion(err))
+		}
+	}
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		return s.checkProof(ctx, challenge.UserID(), proof, assertion)
+	}); err != nil {
+		return nil, s.failAttempt(ctx, token, attempts, err)
+	}
+
+	// The challenge is consumed once the proof is committed, a retried
+	// transaction would not find it again. Only one of concurrent requests
+	// with a valid proof gets it.
+	if _, err := s.challengeRepository.Pop(ctx, token); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrap(ErrInvalidMFAChallenge, "mfa challenge is expired or already used")
+		}
+
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// failAttempt drops the challenge if the last allowed attempt had an invalid
+// code, and returns the error of the attempt.
+func (s *MFAService) failAttempt(ctx context.Context, token string, attempts int, err error) error {
+	if errors.Is(err, domain.ErrInvalidMFACode) && attempts >= s.cfg.MaxAttempts {
+		if err := s.challengeRepository.Delete(ctx, token); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+// checkProof checks a recovery code, a TOTP code of the user or records the
+// WebAuthn assertion verified for the proof.
+func (s *MFAService) checkProof(ctx context.Context, userID uuid.UUID, proof MFAProof, assertion *WebAuthnAssertion) error {
+	if proof.RecoveryCode != "" {
+		return s.useRecoveryCode(ctx, userID, proof.RecoveryCode)
+	}
+
+	if assertion != nil {
+		return invalidAssertion(s.webauthnService.UseCredential(ctx, assertion))
+	}
+
+	totp, err := s.totpRepository.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return errors.Wrap(domain.ErrInvalidMFACode, "totp is not set up")
+		}
+
+		return err
+	}
+
+	if err := s.checkCode(totp, proof.Code); err != nil {
+		return err
+	}
+
+	return s.totpRepository.Save(ctx, totp)
+}
+
+// useRecoveryCode consumes the recovery code and notifies the user, since a
+// used code may mean the authenticator is lost or the account is attacked.
+func (s *MFAService) useRecoveryCode(ctx context.Context, userID uuid.UUID, recoveryCode string) error {
+	if err := s.recoveryCodeRepository.Use(ctx, userID, vobjects.HashRecoveryCode(recoveryCode), s.clock.Now()); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return errors.Wrap(domain.ErrInvalidMFACode, "invalid recovery code")
+		}
+
+		return err
+	}
+
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	remaining, err := s.recoveryCodeRepository.CountUnused(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	recoveryMsg := RecoveryCodeUsedMessage{
+		UserID:         userID,
+		Email:          user.Email(),
+		RemainingCodes: remaining,
+	}
+
+	return s.recoveryMsgSender.SendMessage(ctx, recoveryMsg)
+}
+
+// invalidAssertion makes a failed WebAuthn assertion count as an invalid
+// code.
+func invalidAssertion(err error) error {
+	switch {
+	case errors.Is(err, webauthn.ErrInvalidResponse),
+		errors.Is(err, webauthn.ErrUnsupportedAlgorithm),
+		errors.Is(err, domain.ErrInvalidSignCount),
+		errors.Is(err, ErrInvalidWebAuthnSession),
+		errors.Is(err, ErrInvalidWebAuthnCredential):
+		return errors.Wrap(domain.ErrInvalidMFACode, err.Error())
+	}
+
+	return err
+}
+
+// checkCode validates the code against the decrypted secret and marks its
+// period as used.
+func (s *MFAService) checkCode(totp *entities.UserTOTP, code string) error {
+	secret, err := s.cipher.Decrypt(totp.EncryptedSecret())
+	if err != nil {
+		return err
+	}
+
+	step, ok := vobjects.NewExistingTOTPKey(secret).Validate(code, s.clock.Now())
+	if !ok {
+		return errors.Wrap(domain.ErrInvalidMFACode, "invalid totp code")
+	}
+
+	return totp.UseStep(step)
+}
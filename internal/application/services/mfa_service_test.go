@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/stretchr/testify/require"
+)
+
+const testMFAMaxAttempts = 3
+
+type mfaServiceTest struct {
+	service             *MFAService
+	challengeRepository *fakeMFAChallengeRepository
+	totpRepository      *fakeUserTOTPRepository
+	key                 vobjects.TOTPKey
+	clock               domain.Clock
+	userID              uuid.UUID
+}
+
+func newMFAServiceTest(t *testing.T) *mfaServiceTest {
+	key, err := vobjects.NewTOTPKey()
+	require.NoError(t, err)
+
+	test := &mfaServiceTest{
+		challengeRepository: newFakeMFAChallengeRepository(),
+		totpRepository:      newFakeUserTOTPRepository(),
+		key:                 key,
+		clock:               domain.FixedClock(time.Unix(1700000000, 0)),
+		userID:              uuid.New(),
+	}
+
+	totp := entities.NewUserTOTP(test.userID, key.Secret(), test.clock.Now())
+	require.NoError(t, totp.Activate(test.clock.Now()))
+	require.NoError(t, test.totpRepository.Save(context.Background(), totp))
+
+	test.service = NewMFAService(
+		nil,
+		test.totpRepository,
+		nil,
+		test.challengeRepository,
+		nil,
+		fakeTxManager{},
+		plainCipher{},
+		nil,
+		test.clock,
+		testLogger,
+		testTracer,
+		MFAServiceConfig{
+			Issuer:       "auth-service",
+			ChallengeTTL: time.Minute,
+			MaxAttempts:  testMFAMaxAttempts,
+		},
+	)
+
+	return test
+}
+
+func (tt *mfaServiceTest) createChallenge(t *testing.T) string {
+	challenge, err := tt.service.CreateChallenge(context.Background(), tt.userID, AccessTokenRequest{})
+	require.NoError(t, err)
+
+	return challenge.Token()
+}
+
+func TestMFAServicePassChallenge(t *testing.T) {
+	tt := newMFAServiceTest(t)
+	token := tt.createChallenge(t)
+
+	challenge, err := tt.service.PassChallenge(context.Background(), token, MFAProof{Code: tt.key.Code(tt.clock.Now())})
+	require.NoError(t, err)
+	require.Equal(t, tt.userID, challenge.UserID())
+
+	_, err = tt.service.PassChallenge(context.Background(), token, MFAProof{Code: tt.key.Code(tt.clock.Now())})
+	require.ErrorIs(t, err, ErrInvalidMFAChallenge)
+}
+
+func TestMFAServicePassChallengeMaxAttempts(t *testing.T) {
+	tt := newMFAServiceTest(t)
+	token := tt.createChallenge(t)
+
+	for range testMFAMaxAttempts {
+		_, err := tt.service.PassChallenge(context.Background(), token, MFAProof{Code: "000000"})
+		require.ErrorIs(t, err, domain.ErrInvalidMFACode)
+	}
+
+	_, err := tt.service.PassChallenge(context.Background(), token, MFAProof{Code: tt.key.Code(tt.clock.Now())})
+	require.ErrorIs(t, err, ErrInvalidMFAChallenge)
+}
+
+func TestMFAServicePassChallengeConcurrentAttempts(t *testing.T) {
+	tt := newMFAServiceTest(t)
+	token := tt.createChallenge(t)
+
+	var wg sync.WaitGroup
+
+	for range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := tt.service.PassChallenge(context.Background(), token, MFAProof{Code: "000000"})
+			require.Error(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	require.LessOrEqual(t, tt.totpRepository.Reads(), testMFAMaxAttempts)
+}
+
+func TestMFAServicePassChallengeConcurrentValidCodes(t *testing.T) {
+	tt := newMFAServiceTest(t)
+	token := tt.createChallenge(t)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		passed int
+	)
+
+	for range testMFAMaxAttempts {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := tt.service.PassChallenge(context.Background(), token, MFAProof{Code: tt.key.Code(tt.clock.Now())})
+			if err == nil {
+				mu.Lock()
+				passed++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, 1, passed)
+}
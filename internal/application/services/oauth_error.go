@@ -0,0 +1,30 @@
+package services
+
+const (
+	OAuthErrorInvalidRequest          = "invalid_request"
+	OAuthErrorInvalidClient           = "invalid_client"
+	OAuthErrorInvalidGrant            = "invalid_grant"
+	OAuthErrorInvalidScope            = "invalid_scope"
+	OAuthErrorInvalidTarget           = "invalid_target"
+	OAuthErrorUnauthorizedClient      = "unauthorized_client"
+	OAuthErrorAccessDenied            = "access_denied"
+	OAuthErrorUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrorUnsupportedResponseType = "unsupported_response_type"
+)
+
+// OAuthError is an error reported to an OAuth client as defined by RFC 6749.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func newOAuthError(code string, description string) *OAuthError {
+	return &OAuthError{
+		Code:        code,
+		Description: description,
+	}
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
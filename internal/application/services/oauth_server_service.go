@@ -0,0 +1,539 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	responseTypeCode        = "code"
+	codeChallengeMethodS256 = "S256"
+
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+type OAuthServerServiceConfig struct {
+	Issuer                  string
+	AuthorizationRequestTTL time.Duration
+	AuthorizationCodeTTL    time.Duration
+	AccessTokenTTL          time.Duration
+	RefreshTokenTTL         time.Duration
+	IDTokenTTL              time.Duration
+}
+
+type AuthorizeParams struct {
+	ClientID            string
+	RedirectURI         string
+	ResponseType        string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
+}
+
+// AuthorizeResult holds the authorization request and the code issued for it,
+// the code is nil while the request waits for the user consent or when the
+// user denied it.
+type AuthorizeResult struct {
+	Request *vobjects.AuthorizationRequest
+	Code    *vobjects.AuthorizationCode
+}
+
+type TokenParams struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+	Audience     string
+}
+
+type TokenResult struct {
+	AccessToken  string
+	RefreshToken string
+	IDToken      string
+	ExpiresIn    time.Duration
+	Scopes       vobjects.Scopes
+}
+
+// OAuthServerService lets other applications log users in through this
+// service with the OAuth 2.0 authorization code flow and PKCE, and issues
+// tokens to machine clients with the client credentials grant.
+type OAuthServerService struct {
+	clientRepository       repo.OAuthClientRepository
+	consentRepository      repo.OAuthConsentRepository
+	refreshTokenRepository repo.OAuthRefreshTokenRepository
+	requestRepository      repo.AuthorizationRequestRepository
+	codeRepository         repo.AuthorizationCodeRepository
+	userRepository         repo.UserRepository
+	txManager              repo.TransactionManager
+	secretManager          SecretManager
+	signer                 TokenSigner
+	apiRegistry            *APIRegistry
+	log                    *slog.Logger
+	tracer                 trace.Tracer
+	cfg                    OAuthServerServiceConfig
+}
+
+func NewOAuthServerService(
+	clientRepository repo.OAuthClientRepository,
+	consentRepository repo.OAuthConsentRepository,
+	refreshTokenRepository repo.OAuthRefreshTokenRepository,
+	requestRepository repo.AuthorizationRequestRepository,
+	codeRepository repo.AuthorizationCodeRepository,
+	userRepository repo.UserRepository,
+	txManager repo.TransactionManager,
+	secretManager SecretManager,
+	signer TokenSigner,
+	apiRegistry *APIRegistry,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg OAuthServerServiceConfig,
+) *OAuthServerService {
+	return &OAuthServerService{
+		clientRepository:       clientRepository,
+		consentRepository:      consentRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		requestRepository:      requestRepository,
+		codeRepository:         codeRepository,
+		userRepository:         userRepository,
+		txManager:              txManager,
+		secretManager:          secretManager,
+		signer:                 signer,
+		apiRegistry:            apiRegistry,
+		log:                    log,
+		tracer:                 tracer,
+		cfg:                    cfg,
+	}
+}
+
+// Authorize validates an authorization request of a logged in user and issues
+// a code right away if the user already granted the requested scopes.
+//
+// Errors other than *OAuthError mean the client or redirect uri can't be
+// trusted and must not be redirected to.
+func (s *OAuthServerService) Authorize(ctx context.Context, userID uuid.UUID, params AuthorizeParams) (*AuthorizeResult, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthServerService.Authorize")
+	defer span.End()
+
+	client, err := s.clientRepository.GetByID(ctx, params.ClientID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrapf(ErrInvalidOAuthClient, "client %s is not registered", params.ClientID)
+		}
+
+		return nil, err
+	}
+
+	if !client.RedirectURIAllowed(params.RedirectURI) {
+		return nil, errors.Wrapf(ErrRedirectURINotAllowed, "redirect uri %s is not registered for client", params.RedirectURI)
+	}
+
+	if params.ResponseType != responseTypeCode {
+		return nil, newOAuthError(OAuthErrorUnsupportedResponseType, "only code response type is supported")
+	}
+
+	if !client.GrantTypeAllowed(GrantTypeAuthorizationCode) {
+		return nil, newOAuthError(OAuthErrorUnauthorizedClient, "authorization code grant is not allowed for client")
+	}
+
+	scopes := vobjects.ParseScopes(params.Scope)
+	if len(scopes) == 0 {
+		return nil, newOAuthError(OAuthErrorInvalidScope, "scope is required")
+	}
+
+	if !client.ScopesAllowed(scopes) {
+		return nil, newOAuthError(OAuthErrorInvalidScope, "scope is not allowed for client")
+	}
+
+	if params.CodeChallenge == "" || params.CodeChallengeMethod != codeChallengeMethodS256 {
+		return nil, newOAuthError(OAuthErrorInvalidRequest, "pkce with S256 code challenge method is required")
+	}
+
+	request := vobjects.NewAuthorizationRequest(
+		client.ID(),
+		userID,
+		params.RedirectURI,
+		scopes,
+		params.State,
+		params.CodeChallenge,
+		params.Nonce,
+	)
+
+	consent, err := s.consentRepository.Get(ctx, userID, client.ID())
+	if err != nil && !errors.Is(err, repo.ErrObjectNotFound) {
+		return nil, err
+	}
+
+	if consent != nil && consent.Covers(scopes) {
+		code, err := s.issueCode(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return &AuthorizeResult{Request: request, Code: code}, nil
+	}
+
+	if err := s.requestRepository.Create(ctx, request, s.cfg.AuthorizationRequestTTL); err != nil {
+		return nil, err
+	}
+
+	return &AuthorizeResult{Request: request}, nil
+}
+
+// GetAuthorizationRequest returns a request waiting for consent of the user
+// with the client that made it.
+func (s *OAuthServerService) GetAuthorizationRequest(
+	ctx context.Context,
+	userID uuid.UUID,
+	requestID string,
+) (*vobjects.AuthorizationRequest, *entities.OAuthClient, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthServerService.GetAuthorizationRequest")
+	defer span.End()
+
+	request, err := s.requestRepository.Get(ctx, requestID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if request.UserID() != userID {
+		return nil, nil, errors.Wrap(repo.ErrObjectNotFound, "authorization request not exists")
+	}
+
+	client, err := s.clientRepository.GetByID(ctx, request.ClientID())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return request, client, nil
+}
+
+// Consent completes a request waiting for consent. An approved request gets
+// a code and the consent is remembered for later requests of the client.
+func (s *OAuthServerService) Consent(ctx context.Context, userID uuid.UUID, requestID string, approved bool) (*AuthorizeResult, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthServerService.Consent")
+	defer span.End()
+
+	// The request is only consumed by its user, another user can't drop it
+	// by guessing the ID.
+	request, err := s.requestRepository.Get(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.UserID() != userID {
+		return nil, errors.Wrap(repo.ErrObjectNotFound, "authorization request not exists")
+	}
+
+	if _, err := s.requestRepository.Pop(ctx, requestID); err != nil {
+		return nil, err
+	}
+
+	if !approved {
+		return &AuthorizeResult{Request: request}, nil
+	}
+
+	consent, err := s.consentRepository.Get(ctx, userID, request.ClientID())
+	if err != nil {
+		if !errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, err
+		}
+
+		consent = entities.NewOAuthConsent(userID, request.ClientID(), request.Scopes())
+	} else {
+		consent.Extend(request.Scopes())
+	}
+
+	if err := s.consentRepository.Save(ctx, consent); err != nil {
+		return nil, err
+	}
+
+	code, err := s.issueCode(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthorizeResult{Request: request, Code: code}, nil
+}
+
+func (s *OAuthServerService) issueCode(ctx context.Context, request *vobjects.AuthorizationRequest) (*vobjects.AuthorizationCode, error) {
+	code := vobjects.NewAuthorizationCode(*request)
+
+	if err := s.codeRepository.Create(ctx, code, s.cfg.AuthorizationCodeTTL); err != nil {
+		return nil, err
+	}
+
+	return code, nil
+}
+
+// Token implements the token endpoint. Client and grant errors are returned
+// as *OAuthError.
+func (s *OAuthServerService) Token(ctx context.Context, params TokenParams) (*TokenResult, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthServerService.Token")
+	defer span.End()
+
+	switch params.GrantType {
+	case GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials:
+	default:
+		return nil, newOAuthError(OAuthErrorUnsupportedGrantType, "grant type is not supported")
+	}
+
+	client, err := s.authenticateClient(ctx, params.ClientID, params.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.GrantTypeAllowed(params.GrantType) {
+		return nil, newOAuthError(OAuthErrorUnauthorizedClient, "grant type is not allowed for client")
+	}
+
+	switch params.GrantType {
+	case GrantTypeAuthorizationCode:
+		return s.exchangeCode(ctx, client, params)
+	case GrantTypeRefreshToken:
+		return s.refresh(ctx, client, params)
+	default:
+		return s.clientCredentials(client, params)
+	}
+}
+
+// UserInfo returns the user an access token with the openid scope is issued
+// for.
+func (s *OAuthServerService) UserInfo(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthServerService.UserInfo")
+	defer span.End()
+
+	return s.userRepository.GetByID(ctx, userID)
+}
+
+func (s *OAuthServerService) PublicKeys() []PublicKey {
+	return s.signer.PublicKeys()
+}
+
+func (s *OAuthServerService) authenticateClient(ctx context.Context, clientID string, clientSecret string) (*entities.OAuthClient, error) {
+	client, err := s.clientRepository.GetByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, newOAuthError(OAuthErrorInvalidClient, "client authentication failed")
+		}
+
+		return nil, err
+	}
+
+	if !client.CheckSecret(clientSecret) {
+		return nil, newOAuthError(OAuthErrorInvalidClient, "client authentication failed")
+	}
+
+	return client, nil
+}
+
+func (s *OAuthServerService) exchangeCode(ctx context.Context, client *entities.OAuthClient, params TokenParams) (*TokenResult, error) {
+	code, err := s.codeRepository.Pop(ctx, params.Code)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, newOAuthError(OAuthErrorInvalidGrant, "code is expired or already used")
+		}
+
+		return nil, err
+	}
+
+	request := code.Request()
+
+	if request.ClientID() != client.ID() || request.RedirectURI() != params.RedirectURI {
+		return nil, newOAuthError(OAuthErrorInvalidGrant, "code was issued for another client or redirect uri")
+	}
+
+	if !request.VerifyCodeVerifier(params.CodeVerifier) {
+		return nil, newOAuthError(OAuthErrorInvalidGrant, "code verifier does not match code challenge")
+	}
+
+	var result *TokenResult
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByID(ctx, request.UserID())
+		if err != nil {
+			if errors.Is(err, repo.ErrObjectNotFound) {
+				return newOAuthError(OAuthErrorInvalidGrant, "user not exists")
+			}
+
+			return err
+		}
+
+		if err := user.CheckStatus(); err != nil {
+			return newOAuthError(OAuthErrorInvalidGrant, "user is not active")
+		}
+
+		result, err = s.issueTokens(ctx, client, user, request.Scopes(), request.Nonce())
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// refresh rotates the refresh token, optionally narrowing its scopes.
+func (s *OAuthServerService) refresh(ctx context.Context, client *entities.OAuthClient, params TokenParams) (*TokenResult, error) {
+	var result *TokenResult
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		refreshToken, err := s.refreshTokenRepository.Pop(ctx, params.RefreshToken)
+		if err != nil {
+			if errors.Is(err, repo.ErrObjectNotFound) {
+				return newOAuthError(OAuthErrorInvalidGrant, "refresh token is invalid")
+			}
+
+			return err
+		}
+
+		if refreshToken.ClientID() != client.ID() || !refreshToken.Valid() {
+			return newOAuthError(OAuthErrorInvalidGrant, "refresh token is invalid")
+		}
+
+		scopes := refreshToken.Scopes()
+
+		if params.Scope != "" {
+			requested := vobjects.ParseScopes(params.Scope)
+			if !requested.SubsetOf(scopes) {
+				return newOAuthError(OAuthErrorInvalidScope, "scope exceeds the granted scope")
+			}
+
+			scopes = requested
+		}
+
+		user, err := s.userRepository.GetByID(ctx, refreshToken.UserID())
+		if err != nil {
+			if errors.Is(err, repo.ErrObjectNotFound) {
+				return newOAuthError(OAuthErrorInvalidGrant, "user not exists")
+			}
+
+			return err
+		}
+
+		if err := user.CheckStatus(); err != nil {
+			return newOAuthError(OAuthErrorInvalidGrant, "user is not active")
+		}
+
+		result, err = s.issueTokens(ctx, client, user, scopes, "")
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// clientCredentials issues a token to the client itself, without a refresh
+// token. The token gets all allowed scopes of the client unless a narrower
+// scope is requested. A token for a registered API gets only scopes of the
+// API.
+func (s *OAuthServerService) clientCredentials(client *entities.OAuthClient, params TokenParams) (*TokenResult, error) {
+	if !client.Confidential() {
+		return nil, newOAuthError(OAuthErrorUnauthorizedClient, "public client can't use client credentials grant")
+	}
+
+	scopes := client.AllowedScopes()
+
+	if params.Scope != "" {
+		scopes = vobjects.ParseScopes(params.Scope)
+		if !client.ScopesAllowed(scopes) {
+			return nil, newOAuthError(OAuthErrorInvalidScope, "scope is not allowed for client")
+		}
+	}
+
+	grant := s.apiRegistry.DefaultGrant()
+	grant.Scopes = scopes
+
+	if params.Audience != "" {
+		api, ok := s.apiRegistry.Get(params.Audience)
+		if !ok {
+			return nil, newOAuthError(OAuthErrorInvalidTarget, "audience is not a registered api")
+		}
+
+		if params.Scope == "" {
+			scopes = scopes.Intersect(api.Scopes)
+		}
+
+		var err error
+
+		grant, err = s.apiRegistry.Grant(api.Audience, scopes.String())
+		if err != nil {
+			return nil, newOAuthError(OAuthErrorInvalidScope, "scope is not allowed for api")
+		}
+	}
+
+	accessToken, err := vobjects.NewClientAccessToken(s.cfg.Issuer, s.cfg.AccessTokenTTL, s.secretManager.SecretKey().Get(), client.ID(), grant)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResult{
+		AccessToken: accessToken.Token(),
+		ExpiresIn:   s.cfg.AccessTokenTTL,
+		Scopes:      grant.Scopes,
+	}, nil
+}
+
+func (s *OAuthServerService) issueTokens(
+	ctx context.Context,
+	client *entities.OAuthClient,
+	user *entities.User,
+	scopes vobjects.Scopes,
+	nonce string,
+) (*TokenResult, error) {
+	payload := vobjects.AccessTokenPayload{
+		UserID: user.ID(),
+		Email:  user.Email(),
+	}
+
+	grant := s.apiRegistry.DelegatedGrant(scopes)
+
+	accessToken, err := vobjects.NewDelegatedAccessToken(s.cfg.Issuer, s.cfg.AccessTokenTTL, s.secretManager.SecretKey().Get(), payload, client.ID(), grant)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken := vobjects.NewOAuthRefreshToken(client.ID(), user.ID(), scopes, s.cfg.RefreshTokenTTL)
+
+	if err := s.refreshTokenRepository.Create(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+
+	result := &TokenResult{
+		AccessToken:  accessToken.Token(),
+		RefreshToken: refreshToken.Token(),
+		ExpiresIn:    s.cfg.AccessTokenTTL,
+		Scopes:       scopes,
+	}
+
+	if scopes.Has(vobjects.ScopeOpenID) {
+		claims := vobjects.NewIDTokenClaims(s.cfg.Issuer, client.ID(), user.ID(), nonce, s.cfg.IDTokenTTL)
+
+		if scopes.Has(vobjects.ScopeEmail) {
+			claims.SetEmail(user.Email(), user.Confirmed())
+		}
+
+		result.IDToken, err = s.signer.Sign(claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,469 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRedirectURI  = "https://app.example.com/callback"
+	testAPIAudience  = "https://orders.example.com"
+	testCodeVerifier = "test-code-verifier-with-enough-entropy-0123456789"
+	testNonce        = "test-nonce"
+)
+
+type oauthServerServiceTest struct {
+	service        *OAuthServerService
+	clients        *fakeOAuthClientRepository
+	refreshTokens  *fakeOAuthRefreshTokenRepository
+	user           *entities.User
+	webClient      *entities.OAuthClient
+	webSecret      string
+	machineClient  *entities.OAuthClient
+	machineSecret  string
+	userInfoTarget string
+}
+
+func newOAuthServerServiceTest(t *testing.T) *oauthServerServiceTest {
+	t.Helper()
+
+	test := &oauthServerServiceTest{
+		clients:       newFakeOAuthClientRepository(),
+		refreshTokens: newFakeOAuthRefreshTokenRepository(),
+	}
+
+	users := newFakeUserRepository()
+
+	test.user = entities.NewPasswordlessUser(testUserEmail, true)
+	require.NoError(t, users.Create(context.Background(), test.user))
+
+	var err error
+
+	test.webClient, test.webSecret, err = entities.NewOAuthClient(
+		"web",
+		[]string{testRedirectURI},
+		vobjects.Scopes{vobjects.ScopeOpenID, vobjects.ScopeEmail, "orders:read"},
+		[]string{GrantTypeAuthorizationCode, GrantTypeRefreshToken},
+		true,
+	)
+	require.NoError(t, err)
+	require.NoError(t, test.clients.Create(context.Background(), test.webClient))
+
+	test.machineClient, test.machineSecret, err = entities.NewOAuthClient(
+		"machine",
+		nil,
+		vobjects.Scopes{"orders:read", "orders:write"},
+		[]string{GrantTypeClientCredentials},
+		true,
+	)
+	require.NoError(t, err)
+	require.NoError(t, test.clients.Create(context.Background(), test.machineClient))
+
+	apiRegistry := NewAPIRegistry(testIssuer, []API{
+		{Audience: testAPIAudience, Scopes: vobjects.Scopes{"orders:read", "orders:write"}},
+	})
+	test.userInfoTarget = apiRegistry.UserInfoAudience()
+
+	test.service = NewOAuthServerService(
+		test.clients,
+		newFakeOAuthConsentRepository(),
+		test.refreshTokens,
+		newFakeAuthorizationRequestRepository(),
+		newFakeAuthorizationCodeRepository(),
+		users,
+		fakeTxManager{},
+		testSecretManager{},
+		testTokenSigner{},
+		apiRegistry,
+		testLogger,
+		testTracer,
+		OAuthServerServiceConfig{
+			Issuer:                  testIssuer,
+			AuthorizationRequestTTL: time.Minute,
+			AuthorizationCodeTTL:    time.Minute,
+			AccessTokenTTL:          testAccessTokenTTL,
+			RefreshTokenTTL:         testRefreshTokenTTL,
+			IDTokenTTL:              time.Minute,
+		},
+	)
+
+	return test
+}
+
+func codeChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func (tt *oauthServerServiceTest) authorizeParams(scope string) AuthorizeParams {
+	return AuthorizeParams{
+		ClientID:            tt.webClient.ID(),
+		RedirectURI:         testRedirectURI,
+		ResponseType:        responseTypeCode,
+		Scope:               scope,
+		State:               "state",
+		CodeChallenge:       codeChallenge(testCodeVerifier),
+		CodeChallengeMethod: codeChallengeMethodS256,
+		Nonce:               testNonce,
+	}
+}
+
+// authorize returns a code of an authorization request the user consented
+// to.
+func (tt *oauthServerServiceTest) authorize(t *testing.T, scope string) string {
+	t.Helper()
+
+	ctx := context.Background()
+
+	result, err := tt.service.Authorize(ctx, tt.user.ID(), tt.authorizeParams(scope))
+	require.NoError(t, err)
+
+	if result.Code == nil {
+		result, err = tt.service.Consent(ctx, tt.user.ID(), result.Request.ID(), true)
+		require.NoError(t, err)
+	}
+
+	require.NotNil(t, result.Code)
+
+	return result.Code.Code()
+}
+
+func (tt *oauthServerServiceTest) exchangeParams(code string) TokenParams {
+	return TokenParams{
+		GrantType:    GrantTypeAuthorizationCode,
+		ClientID:     tt.webClient.ID(),
+		ClientSecret: tt.webSecret,
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+	}
+}
+
+func (tt *oauthServerServiceTest) refreshParams(refreshToken string, scope string) TokenParams {
+	return TokenParams{
+		GrantType:    GrantTypeRefreshToken,
+		ClientID:     tt.webClient.ID(),
+		ClientSecret: tt.webSecret,
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	}
+}
+
+func (tt *oauthServerServiceTest) clientCredentialsParams(scope string, audience string) TokenParams {
+	return TokenParams{
+		GrantType:    GrantTypeClientCredentials,
+		ClientID:     tt.machineClient.ID(),
+		ClientSecret: tt.machineSecret,
+		Scope:        scope,
+		Audience:     audience,
+	}
+}
+
+func requireOAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+
+	var oauthErr *OAuthError
+	require.True(t, errors.As(err, &oauthErr), "expected an OAuth error, got %v", err)
+	require.Equal(t, code, oauthErr.Code)
+}
+
+func verifyTestAccessToken(t *testing.T, token string) vobjects.AccessTokenClaims {
+	t.Helper()
+
+	claims, err := vobjects.VerifyAccessToken(token, testSecretManager{}.SecretKey().Get())
+	require.NoError(t, err)
+
+	return claims
+}
+
+func parseTestIDToken(t *testing.T, token string) vobjects.IDTokenClaims {
+	t.Helper()
+
+	var claims vobjects.IDTokenClaims
+
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return testSecretManager{}.SecretKey().Get(), nil
+	})
+	require.NoError(t, err)
+
+	return claims
+}
+
+func TestOAuthServerServiceAuthorizationCode(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+
+	result, err := tt.service.Authorize(ctx, tt.user.ID(), tt.authorizeParams("openid email orders:read"))
+	require.NoError(t, err)
+	require.Nil(t, result.Code)
+
+	result, err = tt.service.Consent(ctx, tt.user.ID(), result.Request.ID(), true)
+	require.NoError(t, err)
+	require.NotNil(t, result.Code)
+
+	token, err := tt.service.Token(ctx, tt.exchangeParams(result.Code.Code()))
+	require.NoError(t, err)
+	require.NotEmpty(t, token.RefreshToken)
+	require.Equal(t, vobjects.Scopes{vobjects.ScopeOpenID, vobjects.ScopeEmail, "orders:read"}, token.Scopes)
+
+	// The consent is remembered, the next request gets a code right away.
+	result, err = tt.service.Authorize(ctx, tt.user.ID(), tt.authorizeParams("openid"))
+	require.NoError(t, err)
+	require.NotNil(t, result.Code)
+}
+
+func TestOAuthServerServiceConsentOtherUser(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+
+	result, err := tt.service.Authorize(ctx, tt.user.ID(), tt.authorizeParams("openid"))
+	require.NoError(t, err)
+	require.Nil(t, result.Code)
+
+	other := entities.NewPasswordlessUser("other@example.com", true)
+
+	_, err = tt.service.Consent(ctx, other.ID(), result.Request.ID(), false)
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	// The request is still waiting for the consent of its user.
+	result, err = tt.service.Consent(ctx, tt.user.ID(), result.Request.ID(), true)
+	require.NoError(t, err)
+	require.NotNil(t, result.Code)
+}
+
+func TestOAuthServerServiceDelegatedAccessToken(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+
+	token, err := tt.service.Token(ctx, tt.exchangeParams(tt.authorize(t, "openid orders:read")))
+	require.NoError(t, err)
+
+	claims := verifyTestAccessToken(t, token.AccessToken)
+	require.True(t, claims.IsDelegated())
+	require.Equal(t, tt.webClient.ID(), claims.ClientID())
+	require.Equal(t, tt.user.ID(), claims.UserID)
+	require.Equal(t, vobjects.Scopes{vobjects.ScopeOpenID, "orders:read"}, claims.Scopes())
+	require.ElementsMatch(t, []string{tt.userInfoTarget, testAPIAudience}, []string(claims.Audience))
+
+	// A delegated token is not accepted by the service itself.
+	_, err = vobjects.VerifyAccessTokenFor(token.AccessToken, testSecretManager{}.SecretKey().Get(), vobjects.AccessTokenRequirements{
+		Issuer:   testIssuer,
+		Audience: testIssuer,
+	})
+	require.ErrorIs(t, err, domain.ErrInvalidTokenAudience)
+
+	// A token without API scopes is issued for the userinfo endpoint only.
+	token, err = tt.service.Token(ctx, tt.exchangeParams(tt.authorize(t, "openid")))
+	require.NoError(t, err)
+	require.Equal(t, []string{tt.userInfoTarget}, []string(verifyTestAccessToken(t, token.AccessToken).Audience))
+}
+
+func TestOAuthServerServiceCodeReuse(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+	code := tt.authorize(t, "openid")
+
+	_, err := tt.service.Token(ctx, tt.exchangeParams(code))
+	require.NoError(t, err)
+
+	_, err = tt.service.Token(ctx, tt.exchangeParams(code))
+	requireOAuthError(t, err, OAuthErrorInvalidGrant)
+}
+
+func TestOAuthServerServiceCodeVerifier(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+
+	params := tt.authorizeParams("openid")
+	params.CodeChallengeMethod = "plain"
+
+	_, err := tt.service.Authorize(ctx, tt.user.ID(), params)
+	requireOAuthError(t, err, OAuthErrorInvalidRequest)
+
+	code := tt.authorize(t, "openid")
+
+	exchange := tt.exchangeParams(code)
+	exchange.CodeVerifier = "another-code-verifier"
+
+	_, err = tt.service.Token(ctx, exchange)
+	requireOAuthError(t, err, OAuthErrorInvalidGrant)
+
+	// A failed exchange consumes the code.
+	_, err = tt.service.Token(ctx, tt.exchangeParams(code))
+	requireOAuthError(t, err, OAuthErrorInvalidGrant)
+}
+
+func TestOAuthServerServiceRedirectURI(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+
+	params := tt.authorizeParams("openid")
+	params.RedirectURI = "https://evil.example.com/callback"
+
+	_, err := tt.service.Authorize(ctx, tt.user.ID(), params)
+	require.ErrorIs(t, err, ErrRedirectURINotAllowed)
+
+	exchange := tt.exchangeParams(tt.authorize(t, "openid"))
+	exchange.RedirectURI = "https://app.example.com/other"
+
+	_, err = tt.service.Token(ctx, exchange)
+	requireOAuthError(t, err, OAuthErrorInvalidGrant)
+}
+
+func TestOAuthServerServiceRefresh(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+
+	token, err := tt.service.Token(ctx, tt.exchangeParams(tt.authorize(t, "openid orders:read")))
+	require.NoError(t, err)
+
+	refreshed, err := tt.service.Token(ctx, tt.refreshParams(token.RefreshToken, ""))
+	require.NoError(t, err)
+	require.NotEqual(t, token.RefreshToken, refreshed.RefreshToken)
+	require.Equal(t, token.Scopes, refreshed.Scopes)
+
+	// The refresh token is rotated.
+	_, err = tt.service.Token(ctx, tt.refreshParams(token.RefreshToken, ""))
+	requireOAuthError(t, err, OAuthErrorInvalidGrant)
+
+	refreshed, err = tt.service.Token(ctx, tt.refreshParams(refreshed.RefreshToken, "openid"))
+	require.NoError(t, err)
+	require.Equal(t, vobjects.Scopes{vobjects.ScopeOpenID}, refreshed.Scopes)
+
+	claims := verifyTestAccessToken(t, refreshed.AccessToken)
+	require.True(t, claims.IsDelegated())
+	require.Equal(t, []string{tt.userInfoTarget}, []string(claims.Audience))
+
+	// A narrowed refresh token can't get the dropped scopes back.
+	_, err = tt.service.Token(ctx, tt.refreshParams(refreshed.RefreshToken, "openid orders:read"))
+	requireOAuthError(t, err, OAuthErrorInvalidScope)
+}
+
+func TestOAuthServerServiceRefreshAnotherClient(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+
+	refreshToken := vobjects.NewOAuthRefreshToken(tt.machineClient.ID(), tt.user.ID(), vobjects.Scopes{vobjects.ScopeOpenID}, time.Hour)
+	require.NoError(t, tt.refreshTokens.Create(ctx, refreshToken))
+
+	_, err := tt.service.Token(ctx, tt.refreshParams(refreshToken.Token(), ""))
+	requireOAuthError(t, err, OAuthErrorInvalidGrant)
+}
+
+func TestOAuthServerServiceClientCredentials(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+
+	token, err := tt.service.Token(ctx, tt.clientCredentialsParams("", ""))
+	require.NoError(t, err)
+	require.Empty(t, token.RefreshToken)
+	require.Empty(t, token.IDToken)
+
+	claims := verifyTestAccessToken(t, token.AccessToken)
+	require.True(t, claims.IsClient())
+	require.Equal(t, tt.machineClient.ID(), claims.ClientID())
+	require.Equal(t, []string{testIssuer}, []string(claims.Audience))
+	require.Equal(t, vobjects.Scopes{"orders:read", "orders:write"}, claims.Scopes())
+
+	token, err = tt.service.Token(ctx, tt.clientCredentialsParams("orders:read", testAPIAudience))
+	require.NoError(t, err)
+
+	claims = verifyTestAccessToken(t, token.AccessToken)
+	require.Equal(t, []string{testAPIAudience}, []string(claims.Audience))
+	require.Equal(t, vobjects.Scopes{"orders:read"}, claims.Scopes())
+
+	_, err = tt.service.Token(ctx, tt.clientCredentialsParams("openid", ""))
+	requireOAuthError(t, err, OAuthErrorInvalidScope)
+
+	_, err = tt.service.Token(ctx, tt.clientCredentialsParams("", "https://unknown.example.com"))
+	requireOAuthError(t, err, OAuthErrorInvalidTarget)
+
+	params := tt.clientCredentialsParams("", "")
+	params.ClientSecret = "wrong-secret"
+
+	_, err = tt.service.Token(ctx, params)
+	requireOAuthError(t, err, OAuthErrorInvalidClient)
+
+	public, _, err := entities.NewOAuthClient("public", nil, vobjects.Scopes{"orders:read"}, []string{GrantTypeClientCredentials}, false)
+	require.NoError(t, err)
+	require.NoError(t, tt.clients.Create(ctx, public))
+
+	_, err = tt.service.Token(ctx, TokenParams{
+		GrantType: GrantTypeClientCredentials,
+		ClientID:  public.ID(),
+	})
+	requireOAuthError(t, err, OAuthErrorUnauthorizedClient)
+
+	// The grant is allowed only to clients registered with it.
+	_, err = tt.service.Token(ctx, TokenParams{
+		GrantType:    GrantTypeClientCredentials,
+		ClientID:     tt.webClient.ID(),
+		ClientSecret: tt.webSecret,
+	})
+	requireOAuthError(t, err, OAuthErrorUnauthorizedClient)
+}
+
+func TestOAuthServerServiceIDToken(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+
+	token, err := tt.service.Token(ctx, tt.exchangeParams(tt.authorize(t, "openid email")))
+	require.NoError(t, err)
+
+	claims := parseTestIDToken(t, token.IDToken)
+	require.Equal(t, testIssuer, claims.Issuer)
+	require.Equal(t, tt.user.ID().String(), claims.Subject)
+	require.Equal(t, jwt.ClaimStrings{tt.webClient.ID()}, claims.Audience)
+	require.Equal(t, testNonce, claims.Nonce)
+	require.Equal(t, testUserEmail, claims.Email)
+	require.NotNil(t, claims.EmailVerified)
+	require.True(t, *claims.EmailVerified)
+
+	// Email claims are set only with the email scope.
+	token, err = tt.service.Token(ctx, tt.exchangeParams(tt.authorize(t, "openid")))
+	require.NoError(t, err)
+
+	claims = parseTestIDToken(t, token.IDToken)
+	require.Empty(t, claims.Email)
+	require.Nil(t, claims.EmailVerified)
+
+	// A refreshed ID token has no nonce of the authorization request.
+	token, err = tt.service.Token(ctx, tt.refreshParams(token.RefreshToken, ""))
+	require.NoError(t, err)
+	require.Empty(t, parseTestIDToken(t, token.IDToken).Nonce)
+
+	token, err = tt.service.Token(ctx, tt.exchangeParams(tt.authorize(t, "orders:read")))
+	require.NoError(t, err)
+	require.Empty(t, token.IDToken)
+}
+
+func TestOAuthServerServiceUserInfo(t *testing.T) {
+	ctx := context.Background()
+	tt := newOAuthServerServiceTest(t)
+
+	token, err := tt.service.Token(ctx, tt.exchangeParams(tt.authorize(t, "openid email")))
+	require.NoError(t, err)
+
+	claims, err := vobjects.VerifyAccessTokenFor(token.AccessToken, testSecretManager{}.SecretKey().Get(), vobjects.AccessTokenRequirements{
+		Issuer:   testIssuer,
+		Audience: tt.userInfoTarget,
+		Scopes:   vobjects.Scopes{vobjects.ScopeOpenID},
+	})
+	require.NoError(t, err)
+
+	user, err := tt.service.UserInfo(ctx, claims.UserID)
+	require.NoError(t, err)
+	require.Equal(t, testUserEmail, user.Email())
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type OAuthStateServiceConfig struct {
+	StateTTL          time.Duration
+	RedirectAllowlist []string
+}
+
+type OAuthStateService struct {
+	repository repo.OAuthStateRepository
+	log        *slog.Logger
+	tracer     trace.Tracer
+	cfg        OAuthStateServiceConfig
+}
+
+func NewOAuthStateService(
+	repository repo.OAuthStateRepository,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg OAuthStateServiceConfig,
+) *OAuthStateService {
+	return &OAuthStateService{
+		repository: repository,
+		log:        log,
+		tracer:     tracer,
+		cfg:        cfg,
+	}
+}
+
+// Begin creates a single-use state for a new OAuth login. An empty redirectURI
+// means the callback responds directly instead of redirecting. The referral
+// code, if any, is used when the login signs the user up.
+func (s *OAuthStateService) Begin(ctx context.Context, provider string, redirectURI string, referralCode string) (*vobjects.OAuthState, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthStateService.Begin")
+	defer span.End()
+
+	return s.begin(ctx, provider, redirectURI, uuid.Nil, referralCode)
+}
+
+// BeginLink creates a state for linking a provider account to an already
+// authenticated user.
+func (s *OAuthStateService) BeginLink(ctx context.Context, provider string, userID uuid.UUID, redirectURI string) (*vobjects.OAuthState, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthStateService.BeginLink")
+	defer span.End()
+
+	return s.begin(ctx, provider, redirectURI, userID, "")
+}
+
+func (s *OAuthStateService) begin(ctx context.Context, provider string, redirectURI string, linkUserID uuid.UUID, referralCode string) (*vobjects.OAuthState, error) {
+	if redirectURI != "" && !s.redirectAllowed(redirectURI) {
+		return nil, errors.Wrapf(ErrRedirectURINotAllowed, "redirect uri %s is not allowed", redirectURI)
+	}
+
+	state := vobjects.NewOAuthState(provider, redirectURI, linkUserID, referralCode)
+
+	if err := s.repository.Create(ctx, state, s.cfg.StateTTL); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Complete consumes the state, so a callback can't be replayed.
+func (s *OAuthStateService) Complete(ctx context.Context, provider string, state string) (*vobjects.OAuthState, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthStateService.Complete")
+	defer span.End()
+
+	oauthState, err := s.repository.Pop(ctx, state)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrap(ErrInvalidOAuthState, "state is expired or already used")
+		}
+
+		return nil, err
+	}
+
+	if oauthState.Provider() != provider {
+		return nil, errors.Wrapf(ErrInvalidOAuthState, "state was issued for provider %s", oauthState.Provider())
+	}
+
+	return oauthState, nil
+}
+
+func (s *OAuthStateService) redirectAllowed(redirectURI string) bool {
+	target, err := url.Parse(redirectURI)
+	if err != nil || target.Scheme == "" || target.Host == "" || target.User != nil || target.Fragment != "" {
+		return false
+	}
+
+	// The browser resolves dot segments, which would leave the allowed path.
+	for _, segment := range strings.Split(target.Path, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	for _, entry := range s.cfg.RedirectAllowlist {
+		allowed, err := url.Parse(entry)
+		if err != nil {
+			continue
+		}
+
+		if target.Scheme == allowed.Scheme && target.Host == allowed.Host && pathAllowed(target.Path, allowed.Path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pathAllowed(path string, allowedPath string) bool {
+	if allowedPath == "" || allowedPath == "/" || path == allowedPath {
+		return true
+	}
+
+	return strings.HasPrefix(path, strings.TrimSuffix(allowedPath, "/")+"/")
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOAuthStateService(ttl time.Duration, allowlist ...string) *OAuthStateService {
+	return NewOAuthStateService(newFakeOAuthStateRepository(), testLogger, testTracer, OAuthStateServiceConfig{
+		StateTTL:          ttl,
+		RedirectAllowlist: allowlist,
+	})
+}
+
+func TestOAuthStateServiceRedirectAllowlist(t *testing.T) {
+	service := newTestOAuthStateService(time.Minute,
+		"https://app.example.com/cb",
+		"https://admin.example.com/",
+		"http://localhost:3000/auth",
+	)
+
+	tests := []struct {
+		name        string
+		redirectURI string
+		allowed     bool
+	}{
+		{name: "no redirect", redirectURI: "", allowed: true},
+		{name: "exact path", redirectURI: "https://app.example.com/cb", allowed: true},
+		{name: "sub path", redirectURI: "https://app.example.com/cb/done", allowed: true},
+		{name: "query", redirectURI: "https://app.example.com/cb?next=/orders", allowed: true},
+		{name: "any path of a host", redirectURI: "https://admin.example.com/users", allowed: true},
+		{name: "path sharing a prefix", redirectURI: "https://app.example.com/cbevil", allowed: false},
+		{name: "other path", redirectURI: "https://app.example.com/other", allowed: false},
+		{name: "dot segments", redirectURI: "https://app.example.com/cb/../admin", allowed: false},
+		{name: "encoded dot segments", redirectURI: "https://app.example.com/cb/%2e%2e/admin", allowed: false},
+		{name: "other port", redirectURI: "https://app.example.com:8443/cb", allowed: false},
+		{name: "other port of localhost", redirectURI: "http://localhost:3001/auth", allowed: false},
+		{name: "scheme downgrade", redirectURI: "http://app.example.com/cb", allowed: false},
+		{name: "userinfo", redirectURI: "https://app.example.com@evil.com/cb", allowed: false},
+		{name: "userinfo of the allowed host", redirectURI: "https://user@app.example.com/cb", allowed: false},
+		{name: "host suffix", redirectURI: "https://app.example.com.evil.com/cb", allowed: false},
+		{name: "subdomain", redirectURI: "https://evil.app.example.com/cb", allowed: false},
+		{name: "fragment", redirectURI: "https://app.example.com/cb#token", allowed: false},
+		{name: "relative", redirectURI: "/cb", allowed: false},
+		{name: "scheme relative", redirectURI: "//app.example.com/cb", allowed: false},
+		{name: "javascript", redirectURI: "javascript:alert(1)", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Begin(context.Background(), "github", tt.redirectURI, "")
+			if tt.allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrRedirectURINotAllowed)
+			}
+		})
+	}
+}
+
+func TestOAuthStateServiceComplete(t *testing.T) {
+	ctx := context.Background()
+	service := newTestOAuthStateService(time.Minute, "https://app.example.com/cb")
+	userID := uuid.New()
+
+	state, err := service.BeginLink(ctx, "github", userID, "https://app.example.com/cb")
+	require.NoError(t, err)
+
+	completed, err := service.Complete(ctx, "github", state.State())
+	require.NoError(t, err)
+	require.Equal(t, userID, completed.LinkUserID())
+	require.Equal(t, "https://app.example.com/cb", completed.RedirectURI())
+
+	// A state can't be replayed.
+	_, err = service.Complete(ctx, "github", state.State())
+	require.ErrorIs(t, err, ErrInvalidOAuthState)
+}
+
+func TestOAuthStateServiceCompleteErrors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		provider string
+	}{
+		{name: "expired", ttl: -time.Minute, provider: "github"},
+		{name: "other provider", ttl: time.Minute, provider: "google"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestOAuthStateService(tt.ttl)
+
+			state, err := service.Begin(ctx, "github", "", "")
+			require.NoError(t, err)
+
+			_, err = service.Complete(ctx, tt.provider, state.State())
+			require.ErrorIs(t, err, ErrInvalidOAuthState)
+		})
+	}
+
+	_, err := newTestOAuthStateService(time.Minute).Complete(ctx, "github", "unknown")
+	require.ErrorIs(t, err, ErrInvalidOAuthState)
+}
@@ -0,0 +1,12 @@
+package services
+
+// OAuthUser is the identity asserted by an external OAuth provider. The
+// referral code is passed through the OAuth state and is used only when the
+// login signs the user up.
+type OAuthUser struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	ReferralCode  string
+}
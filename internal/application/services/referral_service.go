@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ReferralServiceConfig struct {
+	// CodeTTL is the time a referral code can be used, a user gets a new code
+	// once it expires.
+	CodeTTL time.Duration
+}
+
+// ReferralService gives users referral codes and records users signed up
+// with them.
+type ReferralService struct {
+	codeRepository     repo.ReferralCodeRepository
+	referralRepository repo.ReferralRepository
+	referralMsgSender  MessageSender
+	log                *slog.Logger
+	tracer             trace.Tracer
+	cfg                ReferralServiceConfig
+}
+
+func NewReferralService(
+	codeRepository repo.ReferralCodeRepository,
+	referralRepository repo.ReferralRepository,
+	referralMsgSender MessageSender,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg ReferralServiceConfig,
+) *ReferralService {
+	return &ReferralService{
+		codeRepository:     codeRepository,
+		referralRepository: referralRepository,
+		referralMsgSender:  referralMsgSender,
+		log:                log,
+		tracer:             tracer,
+		cfg:                cfg,
+	}
+}
+
+// GetCode returns the current referral code of the user, a new code is
+// created when the previous one expired.
+func (s *ReferralService) GetCode(ctx context.Context, userID uuid.UUID) (*vobjects.ReferralCode, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.GetCode")
+	defer span.End()
+
+	code, err := s.codeRepository.GetByUserID(ctx, userID)
+	if err == nil {
+		return code, nil
+	}
+
+	if !errors.Is(err, repo.ErrObjectNotFound) {
+		return nil, err
+	}
+
+	code = vobjects.NewReferralCode(userID, s.cfg.CodeTTL)
+
+	if err := s.codeRepository.Create(ctx, code); err != nil {
+		return nil, err
+	}
+
+	return code, nil
+}
+
+// CountReferrals returns the number of users signed up with the codes of the
+// user.
+func (s *ReferralService) CountReferrals(ctx context.Context, userID uuid.UUID) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.CountReferrals")
+	defer span.End()
+
+	return s.referralRepository.CountByReferrerID(ctx, userID)
+}
+
+// Resolve finds the referral code used at sign-up.
+func (s *ReferralService) Resolve(ctx context.Context, code string) (*vobjects.ReferralCode, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.Resolve")
+	defer span.End()
+
+	referralCode, err := s.codeRepository.GetByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrap(ErrInvalidReferralCode, "referral code is expired or not exists")
+		}
+
+		return nil, err
+	}
+
+	return referralCode, nil
+}
+
+// Record links the new user to the owner of the referral code, it runs in the
+// transaction creating the user.
+func (s *ReferralService) Record(ctx context.Context, code *vobjects.ReferralCode, referee *entities.User) error {
+	ctx, span := s.tracer.Start(ctx, "ReferralService.Record")
+	defer span.End()
+
+	referral := entities.NewReferral(referee.ID(), code.UserID(), code.Code())
+
+	if err := s.referralRepository.Create(ctx, referral); err != nil {
+		return err
+	}
+
+	referralMsg := ReferralRegisteredMessage{
+		ReferrerID:   referral.ReferrerID(),
+		RefereeID:    referral.RefereeID(),
+		RefereeEmail: referee.Email(),
+		Code:         referral.Code(),
+	}
+
+	return s.referralMsgSender.SendMessage(ctx, referralMsg)
+}
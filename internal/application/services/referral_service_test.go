@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferralServiceGetCode(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	userID := uuid.New()
+
+	code, err := tt.referralService.GetCode(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, userID, code.UserID())
+
+	again, err := tt.referralService.GetCode(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, code.Code(), again.Code())
+
+	// An expired code is replaced with a new one.
+	otherID := uuid.New()
+	expired := vobjects.NewReferralCode(otherID, -time.Minute)
+	require.NoError(t, tt.referralCodes.Create(ctx, expired))
+
+	code, err = tt.referralService.GetCode(ctx, otherID)
+	require.NoError(t, err)
+	require.NotEqual(t, expired.Code(), code.Code())
+
+	_, err = tt.referralService.Resolve(ctx, expired.Code())
+	require.ErrorIs(t, err, ErrInvalidReferralCode)
+}
+
+func TestReferralServiceRegister(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	referrerID := uuid.New()
+
+	code, err := tt.referralService.GetCode(ctx, referrerID)
+	require.NoError(t, err)
+
+	_, err = tt.registrationService.Register(ctx, testUserEmail, testUserPassword, "unknown")
+	require.ErrorIs(t, err, ErrInvalidReferralCode)
+
+	_, err = tt.users.GetByEmail(ctx, testUserEmail)
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	user, err := tt.registrationService.Register(ctx, testUserEmail, testUserPassword, code.Code())
+	require.NoError(t, err)
+
+	_, _, err = tt.oauthLogin(ctx, OAuthUser{
+		Provider:      "github",
+		Subject:       "1",
+		Email:         "other@example.com",
+		EmailVerified: true,
+		ReferralCode:  code.Code(),
+	})
+	require.NoError(t, err)
+
+	count, err := tt.referralService.CountReferrals(ctx, referrerID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	messages := tt.referralMessages.Messages()
+	require.Len(t, messages, 2)
+	require.Equal(t, ReferralRegisteredMessage{
+		ReferrerID:   referrerID,
+		RefereeID:    user.ID(),
+		RefereeEmail: testUserEmail,
+		Code:         code.Code(),
+	}, messages[0])
+
+	// A user signed up without a code is not counted.
+	_, err = tt.registrationService.Register(ctx, "third@example.com", testUserPassword, "")
+	require.NoError(t, err)
+
+	count, err = tt.referralService.CountReferrals(ctx, referrerID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+}
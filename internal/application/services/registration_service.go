@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RegistrationService signs up new users, with a password and a confirmation
+// email, or without a password after a login that verified the email. A new
+// user is checked against the email domain policy and the registration mode,
+// a referral code and a pending invitation are used at sign-up.
+type RegistrationService struct {
+	repository         repo.UserRepository
+	identityRepository repo.UserIdentityRepository
+	txManager          repo.TransactionManager
+	registerMsgSender  MessageSender
+	emailPolicy        EmailDomainPolicy
+	emailThrottle      *EmailThrottle
+	emailCodeService   *EmailCodeService
+	referralService    *ReferralService
+	invitationService  *InvitationService
+	log                *slog.Logger
+	tracer             trace.Tracer
+}
+
+func NewRegistrationService(
+	repository repo.UserRepository,
+	identityRepository repo.UserIdentityRepository,
+	txManager repo.TransactionManager,
+	registerMsgSender MessageSender,
+	emailPolicy EmailDomainPolicy,
+	emailThrottle *EmailThrottle,
+	emailCodeService *EmailCodeService,
+	referralService *ReferralService,
+	invitationService *InvitationService,
+	log *slog.Logger,
+	tracer trace.Tracer,
+) *RegistrationService {
+	return &RegistrationService{
+		repository:         repository,
+		identityRepository: identityRepository,
+		txManager:          txManager,
+		registerMsgSender:  registerMsgSender,
+		emailPolicy:        emailPolicy,
+		emailThrottle:      emailThrottle,
+		emailCodeService:   emailCodeService,
+		referralService:    referralService,
+		invitationService:  invitationService,
+		log:                log,
+		tracer:             tracer,
+	}
+}
+
+// Register signs up a user with a password, it is available only when
+// registration is open. A user signed up with a referral code is recorded as
+// referred by its owner, an empty code means no referral.
+func (s *RegistrationService) Register(ctx context.Context, email string, passwordStr string, referralCodeStr string) (*entities.User, error) {
+	ctx, span := s.tracer.Start(ctx, "RegistrationService.Register")
+	defer span.End()
+
+	if err := s.invitationService.CheckOpenSignUp(); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkEmailDomain(email); err != nil {
+		return nil, err
+	}
+
+	referralCode, err := s.resolveReferralCode(ctx, referralCodeStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.emailThrottle.Allow(ctx, email); err != nil {
+		return nil, err
+	}
+
+	var user *entities.User
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		password, err := vobjects.NewPassword(passwordStr)
+		if err != nil {
+			return err
+		}
+
+		user = entities.NewUser(email, password)
+
+		if err := s.repository.Create(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.recordReferral(ctx, referralCode, user); err != nil {
+			return err
+		}
+
+		return s.sendRegisterMessage(ctx, user)
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *RegistrationService) Confirm(ctx context.Context, email string, token string) error {
+	ctx, span := s.tracer.Start(ctx, "RegistrationService.Confirm")
+	defer span.End()
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.repository.GetByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+
+		if err := user.Confirm(); err != nil {
+			return err
+		}
+
+		if err := s.repository.Update(ctx, user); err != nil {
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ConfirmWithCode confirms the registration with the code sent in the
+// registration email, an alternative to the confirmation link.
+func (s *RegistrationService) ConfirmWithCode(ctx context.Context, email string, code string) error {
+	ctx, span := s.tracer.Start(ctx, "RegistrationService.ConfirmWithCode")
+	defer span.End()
+
+	// The code is consumed before the transaction, a retried transaction
+	// would find it already used.
+	if err := s.emailCodeService.Verify(ctx, email, vobjects.EmailCodeConfirm, code); err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.repository.GetByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+
+		user.ForceConfirm()
+
+		return s.repository.Update(ctx, user)
+	})
+}
+
+// SendConfirmCode sends a new confirmation code to an unconfirmed user. It
+// does nothing for an unknown or already confirmed email, so the response
+// doesn't reveal whether an account exists.
+func (s *RegistrationService) SendConfirmCode(ctx context.Context, email string) error {
+	ctx, span := s.tracer.Start(ctx, "RegistrationService.SendConfirmCode")
+	defer span.End()
+
+	user, err := s.repository.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	if user.Confirmed() {
+		return nil
+	}
+
+	return s.emailCodeService.Send(ctx, email, vobjects.EmailCodeConfirm)
+}
+
+// SignUpPasswordless signs up a user without a password after a magic link or
+// a login code proved the user owns the email, the email is confirmed. A
+// pending invitation to the email is accepted.
+func (s *RegistrationService) SignUpPasswordless(ctx context.Context, email string) (*entities.User, error) {
+	ctx, span := s.tracer.Start(ctx, "RegistrationService.SignUpPasswordless")
+	defer span.End()
+
+	if err := s.checkEmailDomain(email); err != nil {
+		return nil, err
+	}
+
+	user := entities.NewPasswordlessUser(email, true)
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		invitation, err := s.invitationService.SignUpInvitation(ctx, email, true)
+		if err != nil {
+			return err
+		}
+
+		if err := s.repository.Create(ctx, user); err != nil {
+			return err
+		}
+
+		return s.acceptInvitation(ctx, invitation, user)
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SignUpOAuth signs up a user with a provider identity, only an email
+// verified by the provider is accepted. A user signed up with a referral code
+// is recorded as referred by its owner, a pending invitation to the email is
+// accepted.
+func (s *RegistrationService) SignUpOAuth(ctx context.Context, oauthUser OAuthUser) (*entities.User, error) {
+	ctx, span := s.tracer.Start(ctx, "RegistrationService.SignUpOAuth")
+	defer span.End()
+
+	if !oauthUser.EmailVerified {
+		return nil, errors.Wrapf(ErrEmailNotVerified, "%s email = %s is not verified", oauthUser.Provider, oauthUser.Email)
+	}
+
+	if err := s.checkEmailDomain(oauthUser.Email); err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.invitationService.SignUpInvitation(ctx, oauthUser.Email, true)
+	if err != nil {
+		return nil, err
+	}
+
+	referralCode, err := s.resolveReferralCode(ctx, oauthUser.ReferralCode)
+	if err != nil {
+		return nil, err
+	}
+
+	user := entities.NewPasswordlessUser(oauthUser.Email, true)
+	identity := entities.NewUserIdentity(user.ID(), oauthUser.Provider, oauthUser.Subject, oauthUser.Email)
+
+	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.Create(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.identityRepository.Create(ctx, identity); err != nil {
+			return err
+		}
+
+		if err := s.recordReferral(ctx, referralCode, user); err != nil {
+			return err
+		}
+
+		return s.acceptInvitation(ctx, invitation, user)
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *RegistrationService) acceptInvitation(ctx context.Context, invitation *entities.Invitation, user *entities.User) error {
+	if invitation == nil {
+		return nil
+	}
+
+	return s.invitationService.Accept(ctx, invitation, user)
+}
+
+func (s *RegistrationService) resolveReferralCode(ctx context.Context, code string) (*vobjects.ReferralCode, error) {
+	if code == "" {
+		return nil, nil
+	}
+
+	return s.referralService.Resolve(ctx, code)
+}
+
+func (s *RegistrationService) recordReferral(ctx context.Context, code *vobjects.ReferralCode, user *entities.User) error {
+	if code == nil {
+		return nil
+	}
+
+	return s.referralService.Record(ctx, code, user)
+}
+
+// sendRegisterMessage sends the registration email with both a confirmation
+// link and a confirmation code.
+func (s *RegistrationService) sendRegisterMessage(ctx context.Context, user *entities.User) error {
+	code, err := s.emailCodeService.Create(ctx, user.Email(), vobjects.EmailCodeConfirm)
+	if err != nil {
+		return err
+	}
+
+	registerMsg := RegisterMessage{
+		Email:       user.Email(),
+		ConfirmLink: createConfirmLink(user.Email(), user.RegisterToken().Token()),
+		ConfirmCode: code.Code(),
+	}
+
+	return s.registerMsgSender.SendMessage(ctx, registerMsg)
+}
+
+func (s *RegistrationService) checkEmailDomain(email string) error {
+	if !s.emailPolicy.Allowed(email) {
+		return errors.Wrapf(ErrEmailDomainNotAllowed, "email domain of %s is not allowed", email)
+	}
+
+	return nil
+}
+
+func createConfirmLink(email string, token string) string {
+	return fmt.Sprintf("http://localhost:8080/auth/confirm?email=%s&register_token=%s", email, token)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistrationServiceConfirmWithCode(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	tt.createUser(t, testUserEmail)
+
+	require.NoError(t, tt.registrationService.SendConfirmCode(ctx, testUserEmail))
+	code := tt.lastCode(t, testUserEmail, vobjects.EmailCodeConfirm)
+
+	err := tt.registrationService.ConfirmWithCode(ctx, testUserEmail, wrongCode(code))
+	require.ErrorIs(t, err, ErrInvalidEmailCode)
+	require.False(t, tt.getUser(t, testUserEmail).Confirmed())
+
+	require.NoError(t, tt.registrationService.ConfirmWithCode(ctx, testUserEmail, code))
+	require.True(t, tt.getUser(t, testUserEmail).Confirmed())
+
+	err = tt.registrationService.ConfirmWithCode(ctx, testUserEmail, code)
+	require.ErrorIs(t, err, ErrInvalidEmailCode)
+}
+
+func TestRegistrationServiceConfirmWithCodeMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	tt.createUser(t, testUserEmail)
+
+	require.NoError(t, tt.registrationService.SendConfirmCode(ctx, testUserEmail))
+	code := tt.lastCode(t, testUserEmail, vobjects.EmailCodeConfirm)
+
+	for range testEmailCodeAttempts {
+		err := tt.registrationService.ConfirmWithCode(ctx, testUserEmail, wrongCode(code))
+		require.ErrorIs(t, err, ErrInvalidEmailCode)
+	}
+
+	err := tt.registrationService.ConfirmWithCode(ctx, testUserEmail, code)
+	require.ErrorIs(t, err, ErrInvalidEmailCode)
+	require.False(t, tt.getUser(t, testUserEmail).Confirmed())
+
+	// A new code starts without attempts.
+	require.NoError(t, tt.registrationService.SendConfirmCode(ctx, testUserEmail))
+	require.NoError(t, tt.registrationService.ConfirmWithCode(ctx, testUserEmail, tt.lastCode(t, testUserEmail, vobjects.EmailCodeConfirm)))
+}
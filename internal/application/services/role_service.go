@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RoleService manages roles and their assignment to users. Changes take
+// effect for a user when a new access token is issued.
+type RoleService struct {
+	roleRepository repo.RoleRepository
+	userRepository repo.UserRepository
+	txManager      repo.TransactionManager
+	log            *slog.Logger
+	tracer         trace.Tracer
+}
+
+func NewRoleService(
+	roleRepository repo.RoleRepository,
+	userRepository repo.UserRepository,
+	txManager repo.TransactionManager,
+	log *slog.Logger,
+	tracer trace.Tracer,
+) *RoleService {
+	return &RoleService{
+		roleRepository: roleRepository,
+		userRepository: userRepository,
+		txManager:      txManager,
+		log:            log,
+		tracer:         tracer,
+	}
+}
+
+func (s *RoleService) ListRoles(ctx context.Context) ([]*entities.Role, error) {
+	ctx, span := s.tracer.Start(ctx, "RoleService.ListRoles")
+	defer span.End()
+
+	return s.roleRepository.List(ctx)
+}
+
+func (s *RoleService) CreateRole(ctx context.Context, name string, description string, permissions []string) (*entities.Role, error) {
+	ctx, span := s.tracer.Start(ctx, "RoleService.CreateRole")
+	defer span.End()
+
+	role := entities.NewRole(name, description, permissions)
+
+	if err := s.roleRepository.Create(ctx, role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (s *RoleService) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]*entities.Role, error) {
+	ctx, span := s.tracer.Start(ctx, "RoleService.ListUserRoles")
+	defer span.End()
+
+	if _, err := s.userRepository.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.roleRepository.ListByUserID(ctx, userID)
+}
+
+func (s *RoleService) AssignRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	ctx, span := s.tracer.Start(ctx, "RoleService.AssignRole")
+	defer span.End()
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepository.GetByID(ctx, userID); err != nil {
+			return err
+		}
+
+		role, err := s.roleRepository.GetByName(ctx, roleName)
+		if err != nil {
+			return err
+		}
+
+		return s.roleRepository.Assign(ctx, userID, role.ID())
+	})
+}
+
+func (s *RoleService) UnassignRole(ctx context.Context, userID uuid.UUID, roleName string) error {
+	ctx, span := s.tracer.Start(ctx, "RoleService.UnassignRole")
+	defer span.End()
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		role, err := s.roleRepository.GetByName(ctx, roleName)
+		if err != nil {
+			return err
+		}
+
+		return s.roleRepository.Unassign(ctx, userID, role.ID())
+	})
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRoleService(tt *authServiceTest) *RoleService {
+	return NewRoleService(tt.roles, tt.users, fakeTxManager{}, testLogger, testTracer)
+}
+
+func TestRoleServiceAssignRole(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	service := newTestRoleService(tt)
+	user := tt.createUser(t, testUserEmail)
+
+	_, err := service.CreateRole(ctx, testRoleName, "", []string{entities.PermissionUsersRead})
+	require.NoError(t, err)
+
+	_, err = service.CreateRole(ctx, testRoleName, "", nil)
+	require.ErrorIs(t, err, repo.ErrDuplicate)
+
+	err = service.AssignRole(ctx, uuid.New(), testRoleName)
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	err = service.AssignRole(ctx, user.ID(), "unknown")
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	require.NoError(t, service.AssignRole(ctx, user.ID(), testRoleName))
+	require.NoError(t, service.AssignRole(ctx, user.ID(), testRoleName))
+
+	roles, err := service.ListUserRoles(ctx, user.ID())
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+
+	require.NoError(t, service.UnassignRole(ctx, user.ID(), testRoleName))
+
+	err = service.UnassignRole(ctx, user.ID(), testRoleName)
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+
+	roles, err = service.ListUserRoles(ctx, user.ID())
+	require.NoError(t, err)
+	require.Empty(t, roles)
+
+	_, err = service.ListUserRoles(ctx, uuid.New())
+	require.ErrorIs(t, err, repo.ErrObjectNotFound)
+}
+
+func TestRoleServicePermissionsInAccessToken(t *testing.T) {
+	ctx := context.Background()
+	tt := newAuthServiceTest(t, RegistrationOpen)
+	service := newTestRoleService(tt)
+	user := tt.createUser(t, testUserEmail)
+
+	_, err := service.CreateRole(ctx, testRoleName, "", []string{entities.PermissionUsersRead})
+	require.NoError(t, err)
+	require.NoError(t, service.AssignRole(ctx, user.ID(), testRoleName))
+
+	result, err := tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+
+	claims, err := vobjects.VerifyAccessToken(result.AccessToken, testSecretManager{}.SecretKey().Get())
+	require.NoError(t, err)
+	require.Equal(t, []string{testRoleName}, claims.Roles)
+	require.True(t, claims.HasPermission(entities.PermissionUsersRead))
+	require.False(t, claims.HasPermission(entities.PermissionUsersWrite))
+
+	// An unassigned role is gone from the next access token.
+	require.NoError(t, service.UnassignRole(ctx, user.ID(), testRoleName))
+
+	result, err = tt.service.Login(ctx, testUserEmail, testUserPassword, AccessTokenRequest{})
+	require.NoError(t, err)
+
+	claims, err = vobjects.VerifyAccessToken(result.AccessToken, testSecretManager{}.SecretKey().Get())
+	require.NoError(t, err)
+	require.Empty(t, claims.Roles)
+	require.False(t, claims.HasPermission(entities.PermissionUsersRead))
+}
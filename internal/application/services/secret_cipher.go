@@ -0,0 +1,7 @@
+package services
+
+// SecretCipher encrypts secrets stored in the database, e.g. TOTP secrets.
+type SecretCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"crypto/rsa"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type PublicKey struct {
+	KeyID string
+	Key   *rsa.PublicKey
+}
+
+// TokenSigner signs tokens issued to OAuth clients with the service keys,
+// which are published so clients can verify tokens.
+type TokenSigner interface {
+	Sign(claims jwt.Claims) (string, error)
+	PublicKeys() []PublicKey
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/pkg/webauthn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type WebAuthnServiceConfig struct {
+	// SessionTTL is the time a started ceremony waits for the response of the
+	// authenticator.
+	SessionTTL time.Duration
+}
+
+// WebAuthnAssertion is an assertion with a valid signature made with a
+// registered credential.
+type WebAuthnAssertion struct {
+	UserID       uuid.UUID
+	CredentialID []byte
+	SignCount    uint32
+}
+
+// WebAuthnService registers passkeys and security keys and checks assertions
+// made with them, either for a passwordless login or as a second factor.
+type WebAuthnService struct {
+	userRepository       repo.UserRepository
+	credentialRepository repo.WebAuthnCredentialRepository
+	sessionRepository    repo.WebAuthnSessionRepository
+	relyingParty         *webauthn.RelyingParty
+	clock                domain.Clock
+	log                  *slog.Logger
+	tracer               trace.Tracer
+	cfg                  WebAuthnServiceConfig
+}
+
+func NewWebAuthnService(
+	userRepository repo.UserRepository,
+	credentialRepository repo.WebAuthnCredentialRepository,
+	sessionRepository repo.WebAuthnSessionRepository,
+	relyingParty *webauthn.RelyingParty,
+	clock domain.Clock,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg WebAuthnServiceConfig,
+) *WebAuthnService {
+	return &WebAuthnService{
+		userRepository:       userRepository,
+		credentialRepository: credentialRepository,
+		sessionRepository:    sessionRepository,
+		relyingParty:         relyingParty,
+		clock:                clock,
+		log:                  log,
+		tracer:               tracer,
+		cfg:                  cfg,
+	}
+}
+
+// BeginRegistration starts the registration of a new credential for the user.
+// The user ID is the user handle, so a discoverable credential identifies the
+// user at login.
+func (s *WebAuthnService) BeginRegistration(ctx context.Context, userID uuid.UUID) (webauthn.CreationOptions, error) {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.BeginRegistration")
+	defer span.End()
+
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return webauthn.CreationOptions{}, err
+	}
+
+	credentials, err := s.credentialRepository.ListByUserID(ctx, userID)
+	if err != nil {
+		return webauthn.CreationOptions{}, err
+	}
+
+	challenge, err := s.createSession(ctx, vobjects.WebAuthnRegistration, userID)
+	if err != nil {
+		return webauthn.CreationOptions{}, err
+	}
+
+	userEntity := webauthn.UserEntity{
+		ID:          user.WebAuthnHandle(),
+		Name:        user.Email(),
+		DisplayName: user.Email(),
+	}
+
+	return s.relyingParty.CreationOptions(challenge, userEntity, credentialDescriptors(credentials)), nil
+}
+
+// FinishRegistration checks the response of the authenticator and saves the
+// new credential under the given name.
+func (s *WebAuthnService) FinishRegistration(ctx context.Context, userID uuid.UUID, name string, response webauthn.AttestationResponse) (*entities.WebAuthnCredential, error) {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.FinishRegistration")
+	defer span.End()
+
+	session, err := s.popSession(ctx, response.Response.ClientDataJSON, vobjects.WebAuthnRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID() != userID {
+		return nil, errors.Wrap(ErrInvalidWebAuthnSession, "webauthn session of other user")
+	}
+
+	verified, err := s.relyingParty.VerifyRegistration(response, session.Challenge(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	credential := entities.NewWebAuthnCredential(
+		verified.ID,
+		userID,
+		verified.PublicKey,
+		verified.SignCount,
+		verified.Transports,
+		name,
+		s.clock.Now(),
+	)
+
+	if err := s.credentialRepository.Create(ctx, credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// BeginLogin starts a passwordless login. No credentials are listed, the
+// authenticator offers the passkeys it has for the relying party, so the
+// response doesn't reveal whether an account exists.
+func (s *WebAuthnService) BeginLogin(ctx context.Context) (webauthn.RequestOptions, error) {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.BeginLogin")
+	defer span.End()
+
+	challenge, err := s.createSession(ctx, vobjects.WebAuthnLogin, uuid.Nil)
+	if err != nil {
+		return webauthn.RequestOptions{}, err
+	}
+
+	return s.relyingParty.RequestOptions(challenge, nil, webauthn.UserVerificationRequired), nil
+}
+
+// FinishLogin checks the assertion of a passwordless login, the returned
+// assertion names the user owning the credential. User verification is
+// required, so the passkey stands for both factors. The challenge is used up,
+// so it is called before the transaction that records the assertion with
+// UseCredential.
+func (s *WebAuthnService) FinishLogin(ctx context.Context, response webauthn.AssertionResponse) (*WebAuthnAssertion, error) {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.FinishLogin")
+	defer span.End()
+
+	session, err := s.popSession(ctx, response.Response.ClientDataJSON, vobjects.WebAuthnLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, err := s.verifyAssertion(ctx, session, response, true)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetByID(ctx, assertion.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Response.UserHandle) != 0 && !bytes.Equal(response.Response.UserHandle, user.WebAuthnHandle()) {
+		return nil, errors.Wrap(ErrInvalidWebAuthnCredential, "user handle mismatch")
+	}
+
+	return assertion, nil
+}
+
+// BeginAssertion starts an assertion with the credentials of the user, it is
+// used as a second factor after the password.
+func (s *WebAuthnService) BeginAssertion(ctx context.Context, userID uuid.UUID) (webauthn.RequestOptions, error) {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.BeginAssertion")
+	defer span.End()
+
+	credentials, err := s.credentialRepository.ListByUserID(ctx, userID)
+	if err != nil {
+		return webauthn.RequestOptions{}, err
+	}
+
+	if len(credentials) == 0 {
+		return webauthn.RequestOptions{}, errors.Wrap(ErrMFANotSetUp, "user has no webauthn credentials")
+	}
+
+	challenge, err := s.createSession(ctx, vobjects.WebAuthnMFA, userID)
+	if err != nil {
+		return webauthn.RequestOptions{}, err
+	}
+
+	return s.relyingParty.RequestOptions(challenge, credentialDescriptors(credentials), webauthn.UserVerificationPreferred), nil
+}
+
+// VerifyAssertion checks an assertion started with BeginAssertion for the
+// user. Like FinishLogin it uses up the challenge, the assertion is recorded
+// with UseCredential.
+func (s *WebAuthnService) VerifyAssertion(ctx context.Context, userID uuid.UUID, response webauthn.AssertionResponse) (*WebAuthnAssertion, error) {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.VerifyAssertion")
+	defer span.End()
+
+	session, err := s.popSession(ctx, response.Response.ClientDataJSON, vobjects.WebAuthnMFA)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.UserID() != userID {
+		return nil, errors.Wrap(ErrInvalidWebAuthnSession, "webauthn session of other user")
+	}
+
+	return s.verifyAssertion(ctx, session, response, false)
+}
+
+// UseCredential saves the signature counter of a verified assertion. It fails
+// with domain.ErrInvalidSignCount if the counter did not grow, so it runs in
+// the transaction of the login to reject concurrent assertions with the same
+// counter.
+func (s *WebAuthnService) UseCredential(ctx context.Context, assertion *WebAuthnAssertion) error {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.UseCredential")
+	defer span.End()
+
+	credential, err := s.credentialRepository.GetByID(ctx, assertion.CredentialID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return errors.Wrap(ErrInvalidWebAuthnCredential, "credential is not registered")
+		}
+
+		return err
+	}
+
+	if err := credential.Use(assertion.SignCount, s.clock.Now()); err != nil {
+		return err
+	}
+
+	return s.credentialRepository.UpdateUsage(ctx, credential)
+}
+
+// HasCredentials reports whether the user registered any credential.
+func (s *WebAuthnService) HasCredentials(ctx context.Context, userID uuid.UUID) (bool, error) {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.HasCredentials")
+	defer span.End()
+
+	credentials, err := s.credentialRepository.ListByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return len(credentials) != 0, nil
+}
+
+func (s *WebAuthnService) ListCredentials(ctx context.Context, userID uuid.UUID) ([]*entities.WebAuthnCredential, error) {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.ListCredentials")
+	defer span.End()
+
+	return s.credentialRepository.ListByUserID(ctx, userID)
+}
+
+func (s *WebAuthnService) DeleteCredential(ctx context.Context, userID uuid.UUID, id []byte) error {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.DeleteCredential")
+	defer span.End()
+
+	return s.credentialRepository.Delete(ctx, userID, id)
+}
+
+// DeleteCredentials deletes all credentials of the user.
+func (s *WebAuthnService) DeleteCredentials(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnService.DeleteCredentials")
+	defer span.End()
+
+	credentials, err := s.credentialRepository.ListByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, credential := range credentials {
+		if err := s.credentialRepository.Delete(ctx, userID, credential.ID()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *WebAuthnService) createSession(ctx context.Context, ceremony vobjects.WebAuthnCeremony, userID uuid.UUID) ([]byte, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+
+	session := vobjects.NewWebAuthnSession(challenge, ceremony, userID)
+
+	if err := s.sessionRepository.Create(ctx, session, s.cfg.SessionTTL); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// popSession finds the ceremony by the challenge signed by the
+// authenticator, the challenge can't be used again.
+func (s *WebAuthnService) popSession(ctx context.Context, clientDataJSON []byte, ceremony vobjects.WebAuthnCeremony) (*vobjects.WebAuthnSession, error) {
+	challenge, err := webauthn.ClientDataChallenge(clientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepository.Pop(ctx, challenge)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrap(ErrInvalidWebAuthnSession, "webauthn session is expired or already used")
+		}
+
+		return nil, err
+	}
+
+	if session.Ceremony() != ceremony {
+		return nil, errors.Wrapf(ErrInvalidWebAuthnSession, "webauthn session is for %s", session.Ceremony())
+	}
+
+	return session, nil
+}
+
+// verifyAssertion checks the signature with the stored public key.
+func (s *WebAuthnService) verifyAssertion(ctx context.Context, session *vobjects.WebAuthnSession, response webauthn.AssertionResponse, requireUserVerification bool) (*WebAuthnAssertion, error) {
+	credential, err := s.credentialRepository.GetByID(ctx, response.RawID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			return nil, errors.Wrap(ErrInvalidWebAuthnCredential, "credential is not registered")
+		}
+
+		return nil, err
+	}
+
+	if session.UserID() != uuid.Nil && credential.UserID() != session.UserID() {
+		return nil, errors.Wrap(ErrInvalidWebAuthnCredential, "credential of other user")
+	}
+
+	signCount, err := s.relyingParty.VerifyAssertion(response, session.Challenge(), credential.PublicKey(), requireUserVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion := &WebAuthnAssertion{
+		UserID:       credential.UserID(),
+		CredentialID: credential.ID(),
+		SignCount:    signCount,
+	}
+
+	return assertion, nil
+}
+
+func credentialDescriptors(credentials []*entities.WebAuthnCredential) []webauthn.CredentialDescriptor {
+	descriptors := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for _, credential := range credentials {
+		descriptors = append(descriptors, webauthn.NewCredentialDescriptor(credential.ID(), credential.Transports()))
+	}
+
+	return descriptors
+}
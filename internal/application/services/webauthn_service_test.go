@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/pkg/webauthn"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://login.example.com"
+)
+
+type webauthnServiceTest struct {
+	service     *WebAuthnService
+	users       *fakeUserRepository
+	credentials *fakeWebAuthnCredentialRepository
+	sessions    *fakeWebAuthnSessionRepository
+	user        *entities.User
+}
+
+func newWebAuthnServiceTest(t *testing.T) *webauthnServiceTest {
+	t.Helper()
+
+	test := &webauthnServiceTest{
+		users:       newFakeUserRepository(),
+		credentials: &fakeWebAuthnCredentialRepository{},
+		sessions:    newFakeWebAuthnSessionRepository(),
+	}
+
+	password, err := vobjects.NewPassword(testUserPassword)
+	require.NoError(t, err)
+
+	test.user = entities.NewUser(testUserEmail, password)
+	require.NoError(t, test.users.Create(context.Background(), test.user))
+
+	relyingParty := webauthn.NewRelyingParty(webauthn.Config{
+		RPID:    testRPID,
+		RPName:  "Example",
+		Origins: []string{testOrigin},
+		Timeout: time.Minute,
+	})
+
+	test.service = NewWebAuthnService(
+		test.users,
+		test.credentials,
+		test.sessions,
+		relyingParty,
+		domain.SystemClock{},
+		testLogger,
+		testTracer,
+		WebAuthnServiceConfig{
+			SessionTTL: time.Minute,
+		},
+	)
+
+	return test
+}
+
+// register runs the registration ceremony with the authenticator.
+func (tt *webauthnServiceTest) register(t *testing.T, authenticator *testAuthenticator) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	options, err := tt.service.BeginRegistration(ctx, tt.user.ID())
+	require.NoError(t, err)
+
+	_, err = tt.service.FinishRegistration(ctx, tt.user.ID(), "laptop", authenticator.create(t, options.Challenge))
+	require.NoError(t, err)
+}
+
+// login finishes a passwordless login and records the assertion, like
+// AuthService.WebAuthnLogin does.
+func (tt *webauthnServiceTest) login(ctx context.Context, response webauthn.AssertionResponse) (uuid.UUID, error) {
+	assertion, err := tt.service.FinishLogin(ctx, response)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tt.service.UseCredential(ctx, assertion); err != nil {
+		return uuid.Nil, err
+	}
+
+	return assertion.UserID, nil
+}
+
+// testAuthenticator emulates a platform authenticator holding a passkey with
+// an ES256 key and giving no attestation.
+type testAuthenticator struct {
+	origin       string
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newTestAuthenticator(t *testing.T, userHandle []byte) *testAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	return &testAuthenticator{
+		origin:       testOrigin,
+		key:          key,
+		credentialID: credentialID,
+		userHandle:   userHandle,
+	}
+}
+
+func (a *testAuthenticator) authenticatorData(t *testing.T, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+
+	// User present and user verified.
+	flags := byte(0x01 | 0x04)
+	if attested {
+		flags |= 0x40
+	}
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+
+	if attested {
+		publicKey, err := cbor.Marshal(map[int64]any{
+			1:  2,
+			3:  -7,
+			-1: 1,
+			-2: a.key.X.FillBytes(make([]byte, 32)),
+			-3: a.key.Y.FillBytes(make([]byte, 32)),
+		})
+		require.NoError(t, err)
+
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, publicKey...)
+	}
+
+	return data
+}
+
+func (a *testAuthenticator) clientDataJSON(t *testing.T, ceremonyType string, challenge []byte) []byte {
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremonyType,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    a.origin,
+	})
+	require.NoError(t, err)
+
+	return data
+}
+
+func (a *testAuthenticator) create(t *testing.T, challenge []byte) webauthn.AttestationResponse {
+	attestation, err := cbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": a.authenticatorData(t, true),
+	})
+	require.NoError(t, err)
+
+	return webauthn.AttestationResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		RawID: a.credentialID,
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAttestationResponse{
+			ClientDataJSON:    a.clientDataJSON(t, "webauthn.create", challenge),
+			AttestationObject: attestation,
+		},
+	}
+}
+
+// get signs an assertion, every assertion increments the signature counter.
+func (a *testAuthenticator) get(t *testing.T, challenge []byte) webauthn.AssertionResponse {
+	a.signCount++
+
+	authData := a.authenticatorData(t, false)
+	clientDataJSON := a.clientDataJSON(t, "webauthn.get", challenge)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	hash := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	signature, err := a.key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	require.NoError(t, err)
+
+	return webauthn.AssertionResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		RawID: a.credentialID,
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAssertionResponse{
+			ClientDataJSON:    clientDataJSON,
+			AuthenticatorData: authData,
+			Signature:         signature,
+			UserHandle:        a.userHandle,
+		},
+	}
+}
+
+func TestWebAuthnServiceLogin(t *testing.T) {
+	ctx := context.Background()
+	tt := newWebAuthnServiceTest(t)
+	authenticator := newTestAuthenticator(t, tt.user.WebAuthnHandle())
+
+	tt.register(t, authenticator)
+
+	credentials, err := tt.service.ListCredentials(ctx, tt.user.ID())
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	require.Equal(t, "laptop", credentials[0].Name())
+
+	options, err := tt.service.BeginLogin(ctx)
+	require.NoError(t, err)
+
+	assertion := authenticator.get(t, options.Challenge)
+
+	userID, err := tt.login(ctx, assertion)
+	require.NoError(t, err)
+	require.Equal(t, tt.user.ID(), userID)
+	require.Equal(t, uint32(1), credentials[0].SignCount())
+
+	// The challenge is used up by the first login.
+	_, err = tt.login(ctx, assertion)
+	require.ErrorIs(t, err, ErrInvalidWebAuthnSession)
+}
+
+func TestWebAuthnServiceLoginSignCountRegression(t *testing.T) {
+	ctx := context.Background()
+	tt := newWebAuthnServiceTest(t)
+	authenticator := newTestAuthenticator(t, tt.user.WebAuthnHandle())
+
+	tt.register(t, authenticator)
+
+	for range 2 {
+		options, err := tt.service.BeginLogin(ctx)
+		require.NoError(t, err)
+
+		_, err = tt.login(ctx, authenticator.get(t, options.Challenge))
+		require.NoError(t, err)
+	}
+
+	// A clone of the authenticator lags behind the original one.
+	authenticator.signCount = 0
+
+	options, err := tt.service.BeginLogin(ctx)
+	require.NoError(t, err)
+
+	_, err = tt.login(ctx, authenticator.get(t, options.Challenge))
+	require.ErrorIs(t, err, domain.ErrInvalidSignCount)
+}
+
+func TestWebAuthnServiceLoginErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(t *testing.T, tt *webauthnServiceTest, authenticator *testAuthenticator) webauthn.AssertionResponse
+		err     error
+	}{
+		{
+			name: "unknown challenge",
+			respond: func(t *testing.T, _ *webauthnServiceTest, authenticator *testAuthenticator) webauthn.AssertionResponse {
+				challenge, err := webauthn.NewChallenge()
+				require.NoError(t, err)
+
+				return authenticator.get(t, challenge)
+			},
+			err: ErrInvalidWebAuthnSession,
+		},
+		{
+			name: "challenge of registration",
+			respond: func(t *testing.T, tt *webauthnServiceTest, authenticator *testAuthenticator) webauthn.AssertionResponse {
+				options, err := tt.service.BeginRegistration(context.Background(), tt.user.ID())
+				require.NoError(t, err)
+
+				return authenticator.get(t, options.Challenge)
+			},
+			err: ErrInvalidWebAuthnSession,
+		},
+		{
+			name: "other origin",
+			respond: func(t *testing.T, tt *webauthnServiceTest, authenticator *testAuthenticator) webauthn.AssertionResponse {
+				options, err := tt.service.BeginLogin(context.Background())
+				require.NoError(t, err)
+
+				authenticator.origin = "https://login.example.com.evil.com"
+
+				return authenticator.get(t, options.Challenge)
+			},
+			err: webauthn.ErrInvalidResponse,
+		},
+		{
+			name: "user handle of other user",
+			respond: func(t *testing.T, tt *webauthnServiceTest, authenticator *testAuthenticator) webauthn.AssertionResponse {
+				options, err := tt.service.BeginLogin(context.Background())
+				require.NoError(t, err)
+
+				authenticator.userHandle = []byte("other-user-handle")
+
+				return authenticator.get(t, options.Challenge)
+			},
+			err: ErrInvalidWebAuthnCredential,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tt := newWebAuthnServiceTest(t)
+			authenticator := newTestAuthenticator(t, tt.user.WebAuthnHandle())
+
+			tt.register(t, authenticator)
+
+			_, err := tt.login(context.Background(), test.respond(t, tt, authenticator))
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestWebAuthnServiceFinishRegistrationErrors(t *testing.T) {
+	ctx := context.Background()
+	tt := newWebAuthnServiceTest(t)
+	authenticator := newTestAuthenticator(t, tt.user.WebAuthnHandle())
+
+	options, err := tt.service.BeginRegistration(ctx, tt.user.ID())
+	require.NoError(t, err)
+
+	// The session belongs to the user who started the ceremony.
+	_, err = tt.service.FinishRegistration(ctx, uuid.New(), "laptop", authenticator.create(t, options.Challenge))
+	require.ErrorIs(t, err, ErrInvalidWebAuthnSession)
+
+	options, err = tt.service.BeginRegistration(ctx, tt.user.ID())
+	require.NoError(t, err)
+
+	authenticator.origin = "https://evil.example.com"
+
+	_, err = tt.service.FinishRegistration(ctx, tt.user.ID(), "laptop", authenticator.create(t, options.Challenge))
+	require.ErrorIs(t, err, webauthn.ErrInvalidResponse)
+
+	credentials, err := tt.service.ListCredentials(ctx, tt.user.ID())
+	require.NoError(t, err)
+	require.Empty(t, credentials)
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Clock is the source of the current time for time-based codes, so they can
+// be checked against a fixed time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
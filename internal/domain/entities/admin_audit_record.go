@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AdminActionConfirmUser = "user.confirm"
+	AdminActionSuspendUser = "user.suspend"
+	AdminActionDisableUser = "user.disable"
+	AdminActionEnableUser  = "user.enable"
+	AdminActionLogoutUser  = "user.logout"
+	AdminActionDeleteUser  = "user.delete"
+)
+
+// AdminAuditRecord records an action an admin performed on a user.
+type AdminAuditRecord struct {
+	actorID      uuid.UUID
+	action       string
+	targetUserID uuid.UUID
+	createdAt    time.Time
+}
+
+func NewAdminAuditRecord(actorID uuid.UUID, action string, targetUserID uuid.UUID) *AdminAuditRecord {
+	return &AdminAuditRecord{
+		actorID:      actorID,
+		action:       action,
+		targetUserID: targetUserID,
+		createdAt:    time.Now(),
+	}
+}
+
+func NewExistingAdminAuditRecord(actorID uuid.UUID, action string, targetUserID uuid.UUID, createdAt time.Time) *AdminAuditRecord {
+	return &AdminAuditRecord{
+		actorID:      actorID,
+		action:       action,
+		targetUserID: targetUserID,
+		createdAt:    createdAt,
+	}
+}
+
+func (r *AdminAuditRecord) ActorID() uuid.UUID {
+	return r.actorID
+}
+
+func (r *AdminAuditRecord) Action() string {
+	return r.action
+}
+
+func (r *AdminAuditRecord) TargetUserID() uuid.UUID {
+	return r.targetUserID
+}
+
+func (r *AdminAuditRecord) CreatedAt() time.Time {
+	return r.createdAt
+}
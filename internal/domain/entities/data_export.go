@@ -0,0 +1,112 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DataExportStatusPending  = "pending"
+	DataExportStatusReady    = "ready"
+	DataExportStatusCanceled = "canceled"
+)
+
+// DataExport is an archive of personal data of a user. It is requested in the
+// pending status and generated in the background.
+type DataExport struct {
+	id          uuid.UUID
+	userID      uuid.UUID
+	status      string
+	data        []byte
+	requestedAt time.Time
+	completedAt *time.Time
+	expiresAt   *time.Time
+}
+
+func NewDataExport(userID uuid.UUID) *DataExport {
+	return &DataExport{
+		id:          uuid.New(),
+		userID:      userID,
+		status:      DataExportStatusPending,
+		requestedAt: time.Now(),
+	}
+}
+
+func NewExistingDataExport(
+	id uuid.UUID,
+	userID uuid.UUID,
+	status string,
+	data []byte,
+	requestedAt time.Time,
+	completedAt *time.Time,
+	expiresAt *time.Time,
+) *DataExport {
+	return &DataExport{
+		id:          id,
+		userID:      userID,
+		status:      status,
+		data:        data,
+		requestedAt: requestedAt,
+		completedAt: completedAt,
+		expiresAt:   expiresAt,
+	}
+}
+
+func (e *DataExport) ID() uuid.UUID {
+	return e.id
+}
+
+func (e *DataExport) UserID() uuid.UUID {
+	return e.userID
+}
+
+func (e *DataExport) Status() string {
+	return e.status
+}
+
+// Data returns the JSON archive, nil until the export is ready.
+func (e *DataExport) Data() []byte {
+	return e.data
+}
+
+func (e *DataExport) RequestedAt() time.Time {
+	return e.requestedAt
+}
+
+func (e *DataExport) CompletedAt() *time.Time {
+	return e.completedAt
+}
+
+func (e *DataExport) ExpiresAt() *time.Time {
+	return e.expiresAt
+}
+
+func (e *DataExport) Pending() bool {
+	return e.status == DataExportStatusPending
+}
+
+// Ready reports whether the archive is generated and can still be downloaded.
+func (e *DataExport) Ready() bool {
+	return e.status == DataExportStatusReady && e.expiresAt != nil && e.expiresAt.After(time.Now())
+}
+
+// Complete stores the generated archive, it can be downloaded for ttl.
+func (e *DataExport) Complete(data []byte, ttl time.Duration) {
+	completedAt := time.Now()
+	expiresAt := completedAt.Add(ttl)
+
+	e.status = DataExportStatusReady
+	e.data = data
+	e.completedAt = &completedAt
+	e.expiresAt = &expiresAt
+}
+
+// Cancel drops a pending export that can not be generated, e.g. of a deleted
+// user.
+func (e *DataExport) Cancel() {
+	completedAt := time.Now()
+
+	e.status = DataExportStatusCanceled
+	e.completedAt = &completedAt
+}
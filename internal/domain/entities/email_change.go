@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+const (
+	emailChangeTokenLength = 25
+	emailChangeTokenTTL    = time.Hour * 24
+)
+
+// EmailChange is a pending change of the user email. The current email stays
+// in use until the new one is confirmed with the token sent to it.
+type EmailChange struct {
+	userID    uuid.UUID
+	newEmail  string
+	token     string
+	expiredAt time.Time
+}
+
+func NewEmailChange(userID uuid.UUID, newEmail string) *EmailChange {
+	return &EmailChange{
+		userID:    userID,
+		newEmail:  newEmail,
+		token:     domain.GenerateRandomString(emailChangeTokenLength),
+		expiredAt: time.Now().Add(emailChangeTokenTTL),
+	}
+}
+
+func NewExistingEmailChange(userID uuid.UUID, newEmail string, token string, expiredAt time.Time) *EmailChange {
+	return &EmailChange{
+		userID:    userID,
+		newEmail:  newEmail,
+		token:     token,
+		expiredAt: expiredAt,
+	}
+}
+
+func (c *EmailChange) UserID() uuid.UUID {
+	return c.userID
+}
+
+func (c *EmailChange) NewEmail() string {
+	return c.newEmail
+}
+
+func (c *EmailChange) Token() string {
+	return c.token
+}
+
+func (c *EmailChange) ExpiredAt() time.Time {
+	return c.expiredAt
+}
+
+func (c *EmailChange) Valid() bool {
+	return c.expiredAt.After(time.Now())
+}
@@ -0,0 +1,89 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+const invitationTokenLength = 25
+
+// Invitation lets the invited email sign up when registration is
+// invite-only. The invited user gets the role of the invitation, an empty role
+// means no role.
+type Invitation struct {
+	id        uuid.UUID
+	inviterID uuid.UUID
+	email     string
+	role      string
+	token     string
+	expiredAt time.Time
+	createdAt time.Time
+}
+
+func NewInvitation(inviterID uuid.UUID, email string, role string, ttl time.Duration) *Invitation {
+	now := time.Now()
+
+	return &Invitation{
+		id:        uuid.New(),
+		inviterID: inviterID,
+		email:     email,
+		role:      role,
+		token:     domain.GenerateRandomString(invitationTokenLength),
+		expiredAt: now.Add(ttl),
+		createdAt: now,
+	}
+}
+
+func NewExistingInvitation(
+	id uuid.UUID,
+	inviterID uuid.UUID,
+	email string,
+	role string,
+	token string,
+	expiredAt time.Time,
+	createdAt time.Time,
+) *Invitation {
+	return &Invitation{
+		id:        id,
+		inviterID: inviterID,
+		email:     email,
+		role:      role,
+		token:     token,
+		expiredAt: expiredAt,
+		createdAt: createdAt,
+	}
+}
+
+func (i *Invitation) ID() uuid.UUID {
+	return i.id
+}
+
+func (i *Invitation) InviterID() uuid.UUID {
+	return i.inviterID
+}
+
+func (i *Invitation) Email() string {
+	return i.email
+}
+
+func (i *Invitation) Role() string {
+	return i.role
+}
+
+func (i *Invitation) Token() string {
+	return i.token
+}
+
+func (i *Invitation) ExpiredAt() time.Time {
+	return i.expiredAt
+}
+
+func (i *Invitation) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+func (i *Invitation) Valid() bool {
+	return i.expiredAt.After(time.Now())
+}
@@ -0,0 +1,135 @@
+package entities
+
+import (
+	"slices"
+	"time"
+
+	"github.com/rozhnof/auth-service/internal/domain"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+const (
+	oauthClientIDLength     = 32
+	oauthClientSecretLength = 48
+)
+
+// OAuthClient is an application registered to log users in through this
+// service, or a machine client getting tokens for itself. A public client,
+// e.g. a SPA or a mobile app, has no secret and relies on PKCE only.
+type OAuthClient struct {
+	id            string
+	name          string
+	secret        *vobjects.Password
+	redirectURIs  []string
+	allowedScopes vobjects.Scopes
+	grantTypes    []string
+	createdAt     time.Time
+}
+
+// NewOAuthClient registers a client and returns it with the plain secret,
+// which is not stored and can't be shown again.
+func NewOAuthClient(
+	name string,
+	redirectURIs []string,
+	allowedScopes vobjects.Scopes,
+	grantTypes []string,
+	confidential bool,
+) (*OAuthClient, string, error) {
+	client := &OAuthClient{
+		id:            domain.GenerateRandomString(oauthClientIDLength),
+		name:          name,
+		redirectURIs:  redirectURIs,
+		allowedScopes: allowedScopes,
+		grantTypes:    grantTypes,
+		createdAt:     time.Now(),
+	}
+
+	if !confidential {
+		return client, "", nil
+	}
+
+	secretStr := domain.GenerateRandomString(oauthClientSecretLength)
+
+	secret, err := vobjects.NewPassword(secretStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client.secret = &secret
+
+	return client, secretStr, nil
+}
+
+func NewExistingOAuthClient(
+	id string,
+	name string,
+	secret *vobjects.Password,
+	redirectURIs []string,
+	allowedScopes vobjects.Scopes,
+	grantTypes []string,
+	createdAt time.Time,
+) *OAuthClient {
+	return &OAuthClient{
+		id:            id,
+		name:          name,
+		secret:        secret,
+		redirectURIs:  redirectURIs,
+		allowedScopes: allowedScopes,
+		grantTypes:    grantTypes,
+		createdAt:     createdAt,
+	}
+}
+
+func (c *OAuthClient) ID() string {
+	return c.id
+}
+
+func (c *OAuthClient) Name() string {
+	return c.name
+}
+
+// Secret returns nil for a public client.
+func (c *OAuthClient) Secret() *vobjects.Password {
+	return c.secret
+}
+
+func (c *OAuthClient) RedirectURIs() []string {
+	return c.redirectURIs
+}
+
+func (c *OAuthClient) AllowedScopes() vobjects.Scopes {
+	return c.allowedScopes
+}
+
+func (c *OAuthClient) GrantTypes() []string {
+	return c.grantTypes
+}
+
+func (c *OAuthClient) CreatedAt() time.Time {
+	return c.createdAt
+}
+
+func (c *OAuthClient) Confidential() bool {
+	return c.secret != nil
+}
+
+func (c *OAuthClient) CheckSecret(secret string) bool {
+	if !c.Confidential() {
+		return secret == ""
+	}
+
+	return c.secret.Compare(secret)
+}
+
+// RedirectURIAllowed requires an exact match with a registered redirect uri.
+func (c *OAuthClient) RedirectURIAllowed(redirectURI string) bool {
+	return slices.Contains(c.redirectURIs, redirectURI)
+}
+
+func (c *OAuthClient) ScopesAllowed(scopes vobjects.Scopes) bool {
+	return scopes.SubsetOf(c.allowedScopes)
+}
+
+func (c *OAuthClient) GrantTypeAllowed(grantType string) bool {
+	return slices.Contains(c.grantTypes, grantType)
+}
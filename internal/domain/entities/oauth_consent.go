@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+// OAuthConsent records scopes a user granted to an OAuth client, so the user
+// is asked again only when the client requests more.
+type OAuthConsent struct {
+	userID    uuid.UUID
+	clientID  string
+	scopes    vobjects.Scopes
+	grantedAt time.Time
+}
+
+func NewOAuthConsent(userID uuid.UUID, clientID string, scopes vobjects.Scopes) *OAuthConsent {
+	return &OAuthConsent{
+		userID:    userID,
+		clientID:  clientID,
+		scopes:    scopes,
+		grantedAt: time.Now(),
+	}
+}
+
+func NewExistingOAuthConsent(userID uuid.UUID, clientID string, scopes vobjects.Scopes, grantedAt time.Time) *OAuthConsent {
+	return &OAuthConsent{
+		userID:    userID,
+		clientID:  clientID,
+		scopes:    scopes,
+		grantedAt: grantedAt,
+	}
+}
+
+func (c *OAuthConsent) UserID() uuid.UUID {
+	return c.userID
+}
+
+func (c *OAuthConsent) ClientID() string {
+	return c.clientID
+}
+
+func (c *OAuthConsent) Scopes() vobjects.Scopes {
+	return c.scopes
+}
+
+func (c *OAuthConsent) GrantedAt() time.Time {
+	return c.grantedAt
+}
+
+func (c *OAuthConsent) Covers(scopes vobjects.Scopes) bool {
+	return scopes.SubsetOf(c.scopes)
+}
+
+// Extend grants scopes in addition to the already granted ones.
+func (c *OAuthConsent) Extend(scopes vobjects.Scopes) {
+	for _, scope := range scopes {
+		if !c.scopes.Has(scope) {
+			c.scopes = append(c.scopes, scope)
+		}
+	}
+
+	c.grantedAt = time.Now()
+}
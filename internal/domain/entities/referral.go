@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Referral links a user to the user whose referral code was used at sign-up.
+type Referral struct {
+	refereeID  uuid.UUID
+	referrerID uuid.UUID
+	code       string
+	createdAt  time.Time
+}
+
+func NewReferral(refereeID uuid.UUID, referrerID uuid.UUID, code string) *Referral {
+	return &Referral{
+		refereeID:  refereeID,
+		referrerID: referrerID,
+		code:       code,
+		createdAt:  time.Now(),
+	}
+}
+
+func (r *Referral) RefereeID() uuid.UUID {
+	return r.refereeID
+}
+
+func (r *Referral) ReferrerID() uuid.UUID {
+	return r.referrerID
+}
+
+func (r *Referral) Code() string {
+	return r.code
+}
+
+func (r *Referral) CreatedAt() time.Time {
+	return r.createdAt
+}
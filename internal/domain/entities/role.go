@@ -0,0 +1,88 @@
+package entities
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RoleAdmin = "admin"
+
+	PermissionUsersRead  = "users:read"
+	PermissionUsersWrite = "users:write"
+	PermissionRolesRead  = "roles:read"
+	PermissionRolesWrite = "roles:write"
+)
+
+// Role is a named set of permissions assigned to users.
+type Role struct {
+	id          uuid.UUID
+	name        string
+	description string
+	permissions []string
+	createdAt   time.Time
+}
+
+func NewRole(name string, description string, permissions []string) *Role {
+	return &Role{
+		id:          uuid.New(),
+		name:        name,
+		description: description,
+		permissions: permissions,
+		createdAt:   time.Now(),
+	}
+}
+
+func NewExistingRole(id uuid.UUID, name string, description string, permissions []string, createdAt time.Time) *Role {
+	return &Role{
+		id:          id,
+		name:        name,
+		description: description,
+		permissions: permissions,
+		createdAt:   createdAt,
+	}
+}
+
+func (r *Role) ID() uuid.UUID {
+	return r.id
+}
+
+func (r *Role) Name() string {
+	return r.name
+}
+
+func (r *Role) Description() string {
+	return r.description
+}
+
+func (r *Role) Permissions() []string {
+	return r.permissions
+}
+
+func (r *Role) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+// RoleNames returns names of the roles.
+func RoleNames(roles []*Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name())
+	}
+
+	return names
+}
+
+// RolePermissions returns the sorted union of permissions of the roles.
+func RolePermissions(roles []*Role) []string {
+	var permissions []string
+	for _, role := range roles {
+		permissions = append(permissions, role.Permissions()...)
+	}
+
+	slices.Sort(permissions)
+
+	return slices.Compact(permissions)
+}
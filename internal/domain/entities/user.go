@@ -9,11 +9,26 @@ import (
 	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
 )
 
+type UserStatus string
+
+const (
+	UserStatusActive    UserStatus = "active"
+	UserStatusSuspended UserStatus = "suspended"
+	UserStatusDisabled  UserStatus = "disabled"
+)
+
 type User struct {
-	id        uuid.UUID
-	email     string
-	password  vobjects.Password
-	confirmed bool
+	id           uuid.UUID
+	email        string
+	password     *vobjects.Password
+	confirmed    bool
+	status       UserStatus
+	statusReason string
+	statusUntil  *time.Time
+	createdAt    time.Time
+	roles        []*Role
+
+	deletionScheduledAt *time.Time
 
 	accessToken   *vobjects.AccessToken
 	refreshToken  *vobjects.RefreshToken
@@ -24,27 +39,58 @@ func NewUser(email string, password vobjects.Password) *User {
 	return &User{
 		id:            uuid.New(),
 		email:         email,
-		password:      password,
+		password:      &password,
 		confirmed:     false,
+		status:        UserStatusActive,
+		createdAt:     time.Now(),
 		registerToken: vobjects.NewRegisterToken(),
 	}
 }
 
+// NewPasswordlessUser creates a user that logs in with an external identity
+// only. A user whose email is already verified by the identity provider is
+// confirmed right away and gets no register token.
+func NewPasswordlessUser(email string, emailVerified bool) *User {
+	user := &User{
+		id:        uuid.New(),
+		email:     email,
+		confirmed: emailVerified,
+		status:    UserStatusActive,
+		createdAt: time.Now(),
+	}
+
+	if !emailVerified {
+		user.registerToken = vobjects.NewRegisterToken()
+	}
+
+	return user
+}
+
 func NewExistingUser(
 	id uuid.UUID,
 	email string,
-	password vobjects.Password,
+	password *vobjects.Password,
 	confirmed bool,
+	status UserStatus,
+	statusReason string,
+	statusUntil *time.Time,
+	createdAt time.Time,
+	deletionScheduledAt *time.Time,
 	refreshToken *vobjects.RefreshToken,
 	registerToken *vobjects.RegisterToken,
 ) *User {
 	return &User{
-		id:            id,
-		email:         email,
-		password:      password,
-		confirmed:     confirmed,
-		refreshToken:  refreshToken,
-		registerToken: registerToken,
+		id:                  id,
+		email:               email,
+		password:            password,
+		confirmed:           confirmed,
+		status:              status,
+		statusReason:        statusReason,
+		statusUntil:         statusUntil,
+		createdAt:           createdAt,
+		refreshToken:        refreshToken,
+		registerToken:       registerToken,
+		deletionScheduledAt: deletionScheduledAt,
 	}
 }
 
@@ -52,14 +98,46 @@ func (u *User) ID() uuid.UUID {
 	return u.id
 }
 
+// WebAuthnHandle is the user handle stored by WebAuthn authenticators, it
+// identifies the user when logging in with a discoverable credential.
+func (u *User) WebAuthnHandle() []byte {
+	return u.id[:]
+}
+
 func (u *User) Email() string {
 	return u.email
 }
 
-func (u *User) Password() vobjects.Password {
+// Password returns nil for a passwordless user.
+func (u *User) Password() *vobjects.Password {
 	return u.password
 }
 
+func (u *User) HasPassword() bool {
+	return u.password != nil
+}
+
+// SetPassword sets a password for a passwordless user, changing an existing
+// password is not allowed.
+func (u *User) SetPassword(password vobjects.Password) error {
+	if u.HasPassword() {
+		return errors.Wrap(domain.ErrPasswordAlreadySet, "user already has a password")
+	}
+
+	u.password = &password
+
+	return nil
+}
+
+// ChangeEmail switches the user to a new email verified by the user, and
+// revokes the refresh token.
+func (u *User) ChangeEmail(email string) {
+	u.email = email
+	u.confirmed = true
+	u.registerToken = nil
+	u.RevokeRefreshToken()
+}
+
 func (u *User) Confirmed() bool {
 	return u.confirmed
 }
@@ -78,6 +156,126 @@ func (u *User) Confirm() error {
 	return nil
 }
 
+// Roles returns roles set with SetRoles, they are not loaded with the user.
+func (u *User) Roles() []*Role {
+	return u.roles
+}
+
+// SetRoles sets roles and permissions included in access tokens issued to
+// the user.
+func (u *User) SetRoles(roles []*Role) {
+	u.roles = roles
+}
+
+// ForceConfirm confirms the user without a register token, e.g. by an
+// admin.
+func (u *User) ForceConfirm() {
+	u.confirmed = true
+	u.registerToken = nil
+}
+
+func (u *User) Status() UserStatus {
+	return u.status
+}
+
+func (u *User) StatusReason() string {
+	return u.statusReason
+}
+
+// StatusUntil returns the end of a suspension, nil for other statuses.
+func (u *User) StatusUntil() *time.Time {
+	return u.statusUntil
+}
+
+// Suspend blocks logins of the user until the given time and revokes the
+// refresh token.
+func (u *User) Suspend(reason string, until time.Time) error {
+	if !until.After(time.Now()) {
+		return errors.Wrap(domain.ErrInvalidSuspension, "suspension must end in the future")
+	}
+
+	u.status = UserStatusSuspended
+	u.statusReason = reason
+	u.statusUntil = &until
+	u.RevokeRefreshToken()
+
+	return nil
+}
+
+// Disable blocks logins of the user until it is enabled and revokes the
+// refresh token.
+func (u *User) Disable(reason string) {
+	u.status = UserStatusDisabled
+	u.statusReason = reason
+	u.statusUntil = nil
+	u.RevokeRefreshToken()
+}
+
+// Enable makes the user active again, lifting a suspension or a disable.
+func (u *User) Enable() {
+	u.status = UserStatusActive
+	u.statusReason = ""
+	u.statusUntil = nil
+}
+
+// CheckStatus returns an error if the user is not allowed to log in. An
+// expired suspension does not block logins.
+func (u *User) CheckStatus() error {
+	switch u.status {
+	case UserStatusDisabled:
+		return errors.Wrapf(domain.ErrUserDisabled, "user with email = %s is disabled", u.email)
+	case UserStatusSuspended:
+		if u.statusUntil != nil && u.statusUntil.After(time.Now()) {
+			return errors.Wrapf(domain.ErrUserSuspended, "user with email = %s is suspended until %s", u.email, u.statusUntil.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+func (u *User) CreatedAt() time.Time {
+	return u.createdAt
+}
+
+// DeletionScheduledAt returns when the account is going to be purged, nil if
+// the deletion is not scheduled.
+func (u *User) DeletionScheduledAt() *time.Time {
+	return u.deletionScheduledAt
+}
+
+// ScheduleDeletion schedules the account to be purged after the grace period,
+// scheduling it again keeps the first date.
+func (u *User) ScheduleDeletion(gracePeriod time.Duration) {
+	if u.deletionScheduledAt != nil {
+		return
+	}
+
+	deletionScheduledAt := time.Now().Add(gracePeriod)
+	u.deletionScheduledAt = &deletionScheduledAt
+}
+
+func (u *User) CancelDeletion() error {
+	if u.deletionScheduledAt == nil {
+		return errors.Wrap(domain.ErrDeletionNotScheduled, "account deletion is not scheduled")
+	}
+
+	u.deletionScheduledAt = nil
+
+	return nil
+}
+
+// DeletionDue reports whether the grace period of a scheduled deletion is
+// over.
+func (u *User) DeletionDue() bool {
+	return u.deletionScheduledAt != nil && !u.deletionScheduledAt.After(time.Now())
+}
+
+// RevokeRefreshToken logs the user out, access tokens already issued stay
+// valid until they expire.
+func (u *User) RevokeRefreshToken() {
+	u.refreshToken = nil
+}
+
 func (u *User) RefreshToken() *vobjects.RefreshToken {
 	return u.refreshToken
 }
@@ -90,19 +288,36 @@ func (u *User) AccessToken() *vobjects.AccessToken {
 	return u.accessToken
 }
 
+// Reclaim confirms the email of a user who proved to own it by a login link
+// or code. Until then the account may have been signed up by someone else
+// with the email, so the password and the session are dropped.
+func (u *User) Reclaim() {
+	u.password = nil
+	u.ForceConfirm()
+	u.RevokeRefreshToken()
+}
+
 func (u *User) UpdateRegisterToken() error {
 	u.registerToken = vobjects.NewRegisterToken()
 
 	return nil
 }
 
-func (u *User) RefreshTokens(accessTokenTTL time.Duration, refreshTokenTTL time.Duration, secretKey []byte) error {
+func (u *User) RefreshTokens(
+	issuer string,
+	accessTokenTTL time.Duration,
+	refreshTokenTTL time.Duration,
+	secretKey []byte,
+	grant vobjects.AccessTokenGrant,
+) error {
 	payload := vobjects.AccessTokenPayload{
-		UserID: u.id,
-		Email:  u.email,
+		UserID:      u.id,
+		Email:       u.email,
+		Roles:       RoleNames(u.roles),
+		Permissions: RolePermissions(u.roles),
 	}
 
-	at, err := vobjects.NewAccessToken(accessTokenTTL, secretKey, payload)
+	at, err := vobjects.NewAccessToken(issuer, accessTokenTTL, secretKey, payload, grant)
 	if err != nil {
 		return err
 	}
@@ -114,5 +329,9 @@ func (u *User) RefreshTokens(accessTokenTTL time.Duration, refreshTokenTTL time.
 }
 
 func (u *User) CheckPassword(password string) bool {
+	if !u.HasPassword() {
+		return false
+	}
+
 	return u.password.Compare(password)
 }
@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to an account at an external OAuth provider.
+type UserIdentity struct {
+	userID   uuid.UUID
+	provider string
+	subject  string
+	email    string
+	linkedAt time.Time
+}
+
+func NewUserIdentity(userID uuid.UUID, provider string, subject string, email string) *UserIdentity {
+	return &UserIdentity{
+		userID:   userID,
+		provider: provider,
+		subject:  subject,
+		email:    email,
+		linkedAt: time.Now(),
+	}
+}
+
+func NewExistingUserIdentity(userID uuid.UUID, provider string, subject string, email string, linkedAt time.Time) *UserIdentity {
+	return &UserIdentity{
+		userID:   userID,
+		provider: provider,
+		subject:  subject,
+		email:    email,
+		linkedAt: linkedAt,
+	}
+}
+
+func (i *UserIdentity) UserID() uuid.UUID {
+	return i.userID
+}
+
+func (i *UserIdentity) Provider() string {
+	return i.provider
+}
+
+func (i *UserIdentity) Subject() string {
+	return i.subject
+}
+
+func (i *UserIdentity) Email() string {
+	return i.email
+}
+
+func (i *UserIdentity) LinkedAt() time.Time {
+	return i.linkedAt
+}
@@ -0,0 +1,93 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+// UserTOTP is a TOTP authenticator of the user. The secret is kept encrypted,
+// it is decrypted only to check a code. The authenticator is used at login
+// once it is activated with a valid code.
+type UserTOTP struct {
+	userID       uuid.UUID
+	secret       []byte
+	lastUsedStep int64
+	createdAt    time.Time
+	activatedAt  *time.Time
+}
+
+func NewUserTOTP(userID uuid.UUID, encryptedSecret []byte, now time.Time) *UserTOTP {
+	return &UserTOTP{
+		userID:    userID,
+		secret:    encryptedSecret,
+		createdAt: now,
+	}
+}
+
+func NewExistingUserTOTP(
+	userID uuid.UUID,
+	encryptedSecret []byte,
+	lastUsedStep int64,
+	createdAt time.Time,
+	activatedAt *time.Time,
+) *UserTOTP {
+	return &UserTOTP{
+		userID:       userID,
+		secret:       encryptedSecret,
+		lastUsedStep: lastUsedStep,
+		createdAt:    createdAt,
+		activatedAt:  activatedAt,
+	}
+}
+
+func (t *UserTOTP) UserID() uuid.UUID {
+	return t.userID
+}
+
+// EncryptedSecret returns the secret encrypted with the service key.
+func (t *UserTOTP) EncryptedSecret() []byte {
+	return t.secret
+}
+
+func (t *UserTOTP) LastUsedStep() int64 {
+	return t.lastUsedStep
+}
+
+func (t *UserTOTP) CreatedAt() time.Time {
+	return t.createdAt
+}
+
+// ActivatedAt returns nil until the enrollment is verified.
+func (t *UserTOTP) ActivatedAt() *time.Time {
+	return t.activatedAt
+}
+
+func (t *UserTOTP) Active() bool {
+	return t.activatedAt != nil
+}
+
+// Activate turns the authenticator on after the user entered a valid code.
+func (t *UserTOTP) Activate(now time.Time) error {
+	if t.Active() {
+		return errors.Wrap(domain.ErrMFAAlreadyEnabled, "totp is already activated")
+	}
+
+	t.activatedAt = &now
+
+	return nil
+}
+
+// UseStep marks the period of a valid code as used, so the same code can't be
+// replayed.
+func (t *UserTOTP) UseStep(step int64) error {
+	if step <= t.lastUsedStep {
+		return errors.Wrap(domain.ErrInvalidMFACode, "totp code is already used")
+	}
+
+	t.lastUsedStep = step
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+// WebAuthnCredential is a passkey or a security key registered by the user.
+// The public key is stored in the COSE_Key format.
+type WebAuthnCredential struct {
+	id         []byte
+	userID     uuid.UUID
+	publicKey  []byte
+	signCount  uint32
+	transports []string
+	name       string
+	createdAt  time.Time
+	lastUsedAt *time.Time
+}
+
+func NewWebAuthnCredential(
+	id []byte,
+	userID uuid.UUID,
+	publicKey []byte,
+	signCount uint32,
+	transports []string,
+	name string,
+	now time.Time,
+) *WebAuthnCredential {
+	return &WebAuthnCredential{
+		id:         id,
+		userID:     userID,
+		publicKey:  publicKey,
+		signCount:  signCount,
+		transports: transports,
+		name:       name,
+		createdAt:  now,
+	}
+}
+
+func NewExistingWebAuthnCredential(
+	id []byte,
+	userID uuid.UUID,
+	publicKey []byte,
+	signCount uint32,
+	transports []string,
+	name string,
+	createdAt time.Time,
+	lastUsedAt *time.Time,
+) *WebAuthnCredential {
+	return &WebAuthnCredential{
+		id:         id,
+		userID:     userID,
+		publicKey:  publicKey,
+		signCount:  signCount,
+		transports: transports,
+		name:       name,
+		createdAt:  createdAt,
+		lastUsedAt: lastUsedAt,
+	}
+}
+
+func (c *WebAuthnCredential) ID() []byte {
+	return c.id
+}
+
+func (c *WebAuthnCredential) UserID() uuid.UUID {
+	return c.userID
+}
+
+func (c *WebAuthnCredential) PublicKey() []byte {
+	return c.publicKey
+}
+
+func (c *WebAuthnCredential) SignCount() uint32 {
+	return c.signCount
+}
+
+func (c *WebAuthnCredential) Transports() []string {
+	return c.transports
+}
+
+func (c *WebAuthnCredential) Name() string {
+	return c.name
+}
+
+func (c *WebAuthnCredential) CreatedAt() time.Time {
+	return c.createdAt
+}
+
+func (c *WebAuthnCredential) LastUsedAt() *time.Time {
+	return c.lastUsedAt
+}
+
+// Use records an assertion made with the credential. The signature counter
+// must grow, unless the authenticator doesn't keep one, otherwise the
+// credential may have been cloned.
+func (c *WebAuthnCredential) Use(signCount uint32, now time.Time) error {
+	if (signCount != 0 || c.signCount != 0) && signCount <= c.signCount {
+		return errors.Wrap(domain.ErrInvalidSignCount, "signature counter of the credential did not grow")
+	}
+
+	c.signCount = signCount
+	c.lastUsedAt = &now
+
+	return nil
+}
@@ -4,4 +4,15 @@ import "errors"
 
 var (
 	ErrInvalidRegisterToken = errors.New("invalid register token")
+	ErrPasswordAlreadySet   = errors.New("password already set")
+	ErrInvalidTokenIssuer   = errors.New("invalid token issuer")
+	ErrInvalidTokenAudience = errors.New("invalid token audience")
+	ErrInsufficientScope    = errors.New("insufficient scope")
+	ErrUserSuspended        = errors.New("user suspended")
+	ErrUserDisabled         = errors.New("user disabled")
+	ErrInvalidSuspension    = errors.New("invalid suspension")
+	ErrDeletionNotScheduled = errors.New("deletion not scheduled")
+	ErrMFAAlreadyEnabled    = errors.New("mfa already enabled")
+	ErrInvalidMFACode       = errors.New("invalid mfa code")
+	ErrInvalidSignCount     = errors.New("invalid sign count")
 )
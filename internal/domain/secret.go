@@ -1,6 +1,9 @@
 package domain
 
-import "golang.org/x/exp/rand"
+import (
+	"crypto/rand"
+	"math/big"
+)
 
 type Secret []byte
 
@@ -15,9 +18,16 @@ func (s Secret) Get() []byte {
 const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ123456789"
 
 func GenerateRandomString(length int) string {
+	max := big.NewInt(int64(len(letters)))
+
 	bytes := make([]byte, length)
 	for i := range bytes {
-		bytes[i] = letters[rand.Intn(len(letters))]
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+
+		bytes[i] = letters[n.Int64()]
 	}
 
 	return string(bytes)
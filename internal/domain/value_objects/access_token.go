@@ -2,42 +2,146 @@ package vobjects
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
 )
 
-const Issuer = "auth-service"
-
 type AccessTokenPayload struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
+	UserID      uuid.UUID `json:"user_id"`
+	Email       string    `json:"email"`
+	Roles       []string  `json:"roles,omitempty"`
+	Permissions []string  `json:"permissions,omitempty"`
 }
 
+// PrincipalType tells whom an access token is issued to: a user, or a
+// machine client acting on its own behalf.
+type PrincipalType string
+
+const (
+	PrincipalUser   PrincipalType = "user"
+	PrincipalClient PrincipalType = "client"
+)
+
+// AccessTokenClaims are claims of both user and client tokens. A client token
+// has the client id as subject and no user payload. A user token issued to a
+// client on behalf of the user names the client in the azp claim.
 type AccessTokenClaims struct {
 	jwt.RegisteredClaims
 	AccessTokenPayload
+	Principal       PrincipalType `json:"principal,omitempty"`
+	AuthorizedParty string        `json:"azp,omitempty"`
+	Scope           string        `json:"scope,omitempty"`
+}
+
+// IsUser reports whether the token is issued to a user, tokens issued
+// before the principal claim was added are user tokens.
+func (c AccessTokenClaims) IsUser() bool {
+	return c.Principal != PrincipalClient
+}
+
+func (c AccessTokenClaims) IsClient() bool {
+	return c.Principal == PrincipalClient
+}
+
+// IsDelegated reports whether the token is issued to a client on behalf of
+// the user.
+func (c AccessTokenClaims) IsDelegated() bool {
+	return c.IsUser() && c.AuthorizedParty != ""
+}
+
+// ClientID returns the client a client or delegated token is issued to.
+func (c AccessTokenClaims) ClientID() string {
+	if c.IsClient() {
+		return c.Subject
+	}
+
+	return c.AuthorizedParty
+}
+
+func (c AccessTokenClaims) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
+func (c AccessTokenClaims) Scopes() Scopes {
+	return ParseScopes(c.Scope)
+}
+
+// AccessTokenGrant is the audience and the scopes an access token is issued
+// for.
+type AccessTokenGrant struct {
+	Audience []string
+	Scopes   Scopes
 }
 
 type AccessToken struct {
 	token string
 }
 
-func NewAccessToken(ttl time.Duration, secretKey []byte, payload AccessTokenPayload) (*AccessToken, error) {
+// NewAccessToken issues a token to a user with the user id as subject.
+func NewAccessToken(issuer string, ttl time.Duration, secretKey []byte, payload AccessTokenPayload, grant AccessTokenGrant) (*AccessToken, error) {
 	claims := AccessTokenClaims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer: Issuer,
-			ExpiresAt: &jwt.NumericDate{
-				Time: time.Now().Add(ttl),
-			},
-			IssuedAt: &jwt.NumericDate{
-				Time: time.Now(),
-			},
-		},
+		RegisteredClaims:   newRegisteredClaims(issuer, payload.UserID.String(), ttl, grant),
+		AccessTokenPayload: payload,
+		Principal:          PrincipalUser,
+		Scope:              grant.Scopes.String(),
+	}
+
+	return signAccessToken(claims, secretKey)
+}
+
+// NewDelegatedAccessToken issues a token to a client on behalf of a user with
+// the user id as subject and the client id as authorized party.
+func NewDelegatedAccessToken(
+	issuer string,
+	ttl time.Duration,
+	secretKey []byte,
+	payload AccessTokenPayload,
+	clientID string,
+	grant AccessTokenGrant,
+) (*AccessToken, error) {
+	claims := AccessTokenClaims{
+		RegisteredClaims:   newRegisteredClaims(issuer, payload.UserID.String(), ttl, grant),
 		AccessTokenPayload: payload,
+		Principal:          PrincipalUser,
+		AuthorizedParty:    clientID,
+		Scope:              grant.Scopes.String(),
 	}
 
+	return signAccessToken(claims, secretKey)
+}
+
+// NewClientAccessToken issues a token to a machine client with the client id
+// as subject.
+func NewClientAccessToken(issuer string, ttl time.Duration, secretKey []byte, clientID string, grant AccessTokenGrant) (*AccessToken, error) {
+	claims := AccessTokenClaims{
+		RegisteredClaims: newRegisteredClaims(issuer, clientID, ttl, grant),
+		Principal:        PrincipalClient,
+		Scope:            grant.Scopes.String(),
+	}
+
+	return signAccessToken(claims, secretKey)
+}
+
+func newRegisteredClaims(issuer string, subject string, ttl time.Duration, grant AccessTokenGrant) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		ID:       uuid.NewString(),
+		Issuer:   issuer,
+		Subject:  subject,
+		Audience: grant.Audience,
+		ExpiresAt: &jwt.NumericDate{
+			Time: time.Now().Add(ttl),
+		},
+		IssuedAt: &jwt.NumericDate{
+			Time: time.Now(),
+		},
+	}
+}
+
+func signAccessToken(claims AccessTokenClaims, secretKey []byte) (*AccessToken, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	signedToken, err := token.SignedString(secretKey)
@@ -69,3 +173,34 @@ func VerifyAccessToken(tokenString string, secretKey []byte) (AccessTokenClaims,
 
 	return claims, nil
 }
+
+// AccessTokenRequirements are what a resource server expects from an access
+// token besides a valid signature and expiration time.
+type AccessTokenRequirements struct {
+	Issuer   string
+	Audience string
+	Scopes   Scopes
+}
+
+// VerifyAccessTokenFor verifies a token and checks it is issued by the
+// expected issuer for the audience and has all required scopes.
+func VerifyAccessTokenFor(tokenString string, secretKey []byte, requirements AccessTokenRequirements) (AccessTokenClaims, error) {
+	claims, err := VerifyAccessToken(tokenString, secretKey)
+	if err != nil {
+		return AccessTokenClaims{}, err
+	}
+
+	if !claims.VerifyIssuer(requirements.Issuer, true) {
+		return AccessTokenClaims{}, fmt.Errorf("%w: unexpected issuer %s", domain.ErrInvalidTokenIssuer, claims.Issuer)
+	}
+
+	if !claims.VerifyAudience(requirements.Audience, true) {
+		return AccessTokenClaims{}, fmt.Errorf("%w: token is not issued for %s", domain.ErrInvalidTokenAudience, requirements.Audience)
+	}
+
+	if !requirements.Scopes.SubsetOf(claims.Scopes()) {
+		return AccessTokenClaims{}, fmt.Errorf("%w: %s required", domain.ErrInsufficientScope, requirements.Scopes)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,125 @@
+package vobjects
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://auth.example.com"
+	testAudience = "https://orders.example.com"
+)
+
+var testSecretKey = []byte("test-secret-key")
+
+func newTestAccessToken(t *testing.T, grant AccessTokenGrant) string {
+	payload := AccessTokenPayload{
+		UserID: uuid.New(),
+		Email:  "test.email@gmail.com",
+	}
+
+	token, err := NewAccessToken(testIssuer, time.Minute, testSecretKey, payload, grant)
+	require.NoError(t, err)
+
+	return token.Token()
+}
+
+func TestNewAccessTokenClaims(t *testing.T) {
+	grant := AccessTokenGrant{
+		Audience: []string{testAudience},
+		Scopes:   Scopes{"orders:read"},
+	}
+
+	claims, err := VerifyAccessToken(newTestAccessToken(t, grant), testSecretKey)
+	require.NoError(t, err)
+	require.Equal(t, claims.UserID.String(), claims.Subject)
+	require.Equal(t, testIssuer, claims.Issuer)
+	require.Equal(t, []string{testAudience}, []string(claims.Audience))
+	require.Equal(t, "orders:read", claims.Scope)
+	require.NotEmpty(t, claims.ID)
+	require.True(t, claims.IsUser())
+}
+
+func TestVerifyAccessTokenFor(t *testing.T) {
+	grant := AccessTokenGrant{
+		Audience: []string{testAudience},
+		Scopes:   Scopes{"orders:read"},
+	}
+
+	tests := []struct {
+		name         string
+		requirements AccessTokenRequirements
+		err          error
+	}{
+		{
+			name:         "valid",
+			requirements: AccessTokenRequirements{Issuer: testIssuer, Audience: testAudience, Scopes: Scopes{"orders:read"}},
+		},
+		{
+			name:         "other issuer",
+			requirements: AccessTokenRequirements{Issuer: "https://other.example.com", Audience: testAudience},
+			err:          domain.ErrInvalidTokenIssuer,
+		},
+		{
+			name:         "other audience",
+			requirements: AccessTokenRequirements{Issuer: testIssuer, Audience: "https://billing.example.com"},
+			err:          domain.ErrInvalidTokenAudience,
+		},
+		{
+			name:         "missing scope",
+			requirements: AccessTokenRequirements{Issuer: testIssuer, Audience: testAudience, Scopes: Scopes{"orders:write"}},
+			err:          domain.ErrInsufficientScope,
+		},
+	}
+
+	token := newTestAccessToken(t, grant)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyAccessTokenFor(token, testSecretKey, tt.requirements)
+			if tt.err == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewDelegatedAccessTokenClaims(t *testing.T) {
+	payload := AccessTokenPayload{
+		UserID: uuid.New(),
+		Email:  "test.email@gmail.com",
+	}
+
+	grant := AccessTokenGrant{
+		Audience: []string{testIssuer + "/oauth/userinfo", testAudience},
+		Scopes:   Scopes{ScopeOpenID, "orders:read"},
+	}
+
+	token, err := NewDelegatedAccessToken(testIssuer, time.Minute, testSecretKey, payload, "client-id", grant)
+	require.NoError(t, err)
+
+	claims, err := VerifyAccessToken(token.Token(), testSecretKey)
+	require.NoError(t, err)
+	require.Equal(t, payload.UserID.String(), claims.Subject)
+	require.Equal(t, "client-id", claims.AuthorizedParty)
+	require.Equal(t, "client-id", claims.ClientID())
+	require.Equal(t, grant.Audience, []string(claims.Audience))
+	require.Equal(t, "openid orders:read", claims.Scope)
+	require.True(t, claims.IsUser())
+	require.True(t, claims.IsDelegated())
+	require.False(t, claims.IsClient())
+}
+
+func TestNewAccessTokenIsNotDelegated(t *testing.T) {
+	claims, err := VerifyAccessToken(newTestAccessToken(t, AccessTokenGrant{Audience: []string{testIssuer}}), testSecretKey)
+	require.NoError(t, err)
+	require.False(t, claims.IsDelegated())
+	require.Empty(t, claims.ClientID())
+}
@@ -0,0 +1,36 @@
+package vobjects
+
+import "github.com/rozhnof/auth-service/internal/domain"
+
+const (
+	authorizationCodeLength = 32
+)
+
+// AuthorizationCode is a single-use code issued to an OAuth client for an
+// approved authorization request.
+type AuthorizationCode struct {
+	code    string
+	request AuthorizationRequest
+}
+
+func NewAuthorizationCode(request AuthorizationRequest) *AuthorizationCode {
+	return &AuthorizationCode{
+		code:    domain.GenerateRandomString(authorizationCodeLength),
+		request: request,
+	}
+}
+
+func NewExistingAuthorizationCode(code string, request AuthorizationRequest) *AuthorizationCode {
+	return &AuthorizationCode{
+		code:    code,
+		request: request,
+	}
+}
+
+func (c AuthorizationCode) Code() string {
+	return c.code
+}
+
+func (c AuthorizationCode) Request() AuthorizationRequest {
+	return c.request
+}
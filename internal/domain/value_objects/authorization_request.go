@@ -0,0 +1,111 @@
+package vobjects
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+const (
+	authorizationRequestIDLength = 32
+)
+
+// AuthorizationRequest is a validated /oauth/authorize request of a logged in
+// user, kept while the user decides on consent and bound to the issued code.
+type AuthorizationRequest struct {
+	id            string
+	clientID      string
+	userID        uuid.UUID
+	redirectURI   string
+	scopes        Scopes
+	state         string
+	codeChallenge string
+	nonce         string
+}
+
+func NewAuthorizationRequest(
+	clientID string,
+	userID uuid.UUID,
+	redirectURI string,
+	scopes Scopes,
+	state string,
+	codeChallenge string,
+	nonce string,
+) *AuthorizationRequest {
+	return &AuthorizationRequest{
+		id:            domain.GenerateRandomString(authorizationRequestIDLength),
+		clientID:      clientID,
+		userID:        userID,
+		redirectURI:   redirectURI,
+		scopes:        scopes,
+		state:         state,
+		codeChallenge: codeChallenge,
+		nonce:         nonce,
+	}
+}
+
+func NewExistingAuthorizationRequest(
+	id string,
+	clientID string,
+	userID uuid.UUID,
+	redirectURI string,
+	scopes Scopes,
+	state string,
+	codeChallenge string,
+	nonce string,
+) *AuthorizationRequest {
+	return &AuthorizationRequest{
+		id:            id,
+		clientID:      clientID,
+		userID:        userID,
+		redirectURI:   redirectURI,
+		scopes:        scopes,
+		state:         state,
+		codeChallenge: codeChallenge,
+		nonce:         nonce,
+	}
+}
+
+func (r AuthorizationRequest) ID() string {
+	return r.id
+}
+
+func (r AuthorizationRequest) ClientID() string {
+	return r.clientID
+}
+
+func (r AuthorizationRequest) UserID() uuid.UUID {
+	return r.userID
+}
+
+func (r AuthorizationRequest) RedirectURI() string {
+	return r.redirectURI
+}
+
+func (r AuthorizationRequest) Scopes() Scopes {
+	return r.scopes
+}
+
+func (r AuthorizationRequest) State() string {
+	return r.state
+}
+
+func (r AuthorizationRequest) CodeChallenge() string {
+	return r.codeChallenge
+}
+
+func (r AuthorizationRequest) Nonce() string {
+	return r.nonce
+}
+
+// VerifyCodeVerifier checks the PKCE code verifier against the S256 code
+// challenge of the request.
+func (r AuthorizationRequest) VerifyCodeVerifier(codeVerifier string) bool {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(r.codeChallenge)) == 1
+}
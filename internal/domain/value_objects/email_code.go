@@ -0,0 +1,100 @@
+package vobjects
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	emailCodeMinLength = 4
+)
+
+type EmailCodePurpose string
+
+const (
+	EmailCodeConfirm EmailCodePurpose = "confirm"
+	EmailCodeLogin   EmailCodePurpose = "login"
+)
+
+// EmailCode is a short numeric code sent by email, an alternative to a link
+// for clients that can't open links. Only the last code sent for an email and
+// purpose is valid.
+type EmailCode struct {
+	code      string
+	email     string
+	purpose   EmailCodePurpose
+	expiredAt time.Time
+}
+
+// NewEmailCode generates a code of the given number of digits, a code shorter
+// than emailCodeMinLength would be too easy to guess, so the length is raised
+// to it.
+func NewEmailCode(email string, purpose EmailCodePurpose, length int, ttl time.Duration) (*EmailCode, error) {
+	code, err := randomDigits(max(length, emailCodeMinLength))
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailCode{
+		code:      code,
+		email:     email,
+		purpose:   purpose,
+		expiredAt: time.Now().Add(ttl),
+	}, nil
+}
+
+func NewExistingEmailCode(code string, email string, purpose EmailCodePurpose, expiredAt time.Time) *EmailCode {
+	return &EmailCode{
+		code:      code,
+		email:     email,
+		purpose:   purpose,
+		expiredAt: expiredAt,
+	}
+}
+
+func (c EmailCode) Code() string {
+	return c.code
+}
+
+func (c EmailCode) Email() string {
+	return c.email
+}
+
+func (c EmailCode) Purpose() EmailCodePurpose {
+	return c.purpose
+}
+
+func (c EmailCode) ExpiredAt() time.Time {
+	return c.expiredAt
+}
+
+func (c EmailCode) Valid() bool {
+	return c.expiredAt.After(time.Now())
+}
+
+// Check compares the entered code in constant time.
+func (c EmailCode) Check(code string) bool {
+	code = strings.TrimSpace(code)
+
+	return subtle.ConstantTimeCompare([]byte(c.code), []byte(code)) == 1
+}
+
+func randomDigits(length int) (string, error) {
+	var sb strings.Builder
+
+	sb.Grow(length)
+
+	for range length {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteByte(byte('0' + digit.Int64()))
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,41 @@
+package vobjects
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEmailCode(t *testing.T) {
+	code, err := NewEmailCode("user@example.com", EmailCodeLogin, 6, time.Minute)
+	require.NoError(t, err)
+
+	require.Regexp(t, regexp.MustCompile(`^[0-9]{6}$`), code.Code())
+	require.Equal(t, "user@example.com", code.Email())
+	require.Equal(t, EmailCodeLogin, code.Purpose())
+	require.True(t, code.Valid())
+
+	code, err = NewEmailCode("user@example.com", EmailCodeConfirm, 8, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, code.Code(), 8)
+
+	code, err = NewEmailCode("user@example.com", EmailCodeConfirm, 0, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, code.Code(), emailCodeMinLength)
+}
+
+func TestEmailCodeCheck(t *testing.T) {
+	code := NewExistingEmailCode("123456", "user@example.com", EmailCodeConfirm, time.Now().Add(time.Minute))
+
+	require.False(t, code.Check("654321"))
+	require.False(t, code.Check("12345"))
+	require.True(t, code.Check(" 123456 "))
+}
+
+func TestEmailCodeExpired(t *testing.T) {
+	code := NewExistingEmailCode("123456", "user@example.com", EmailCodeConfirm, time.Now().Add(-time.Second))
+
+	require.False(t, code.Valid())
+}
@@ -0,0 +1,37 @@
+package vobjects
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// IDTokenClaims are claims of an OpenID Connect ID token issued to an OAuth
+// client. Email claims are set only when the email scope was granted.
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+}
+
+func NewIDTokenClaims(issuer string, clientID string, userID uuid.UUID, nonce string, ttl time.Duration) IDTokenClaims {
+	now := time.Now()
+
+	return IDTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Nonce: nonce,
+	}
+}
+
+func (c *IDTokenClaims) SetEmail(email string, verified bool) {
+	c.Email = email
+	c.EmailVerified = &verified
+}
@@ -0,0 +1,61 @@
+package vobjects
+
+import "github.com/rozhnof/auth-service/internal/domain"
+
+const (
+	loginCodeLength = 32
+)
+
+// LoginCode is a short-lived single-use code that a frontend exchanges for the
+// tokens issued by an OAuth login, so tokens never appear in a browser URL.
+// When the user has MFA enabled it holds the MFA challenge token instead.
+type LoginCode struct {
+	code         string
+	accessToken  string
+	refreshToken string
+	mfaToken     string
+}
+
+func NewLoginCode(accessToken string, refreshToken string) *LoginCode {
+	return &LoginCode{
+		code:         domain.GenerateRandomString(loginCodeLength),
+		accessToken:  accessToken,
+		refreshToken: refreshToken,
+	}
+}
+
+func NewMFALoginCode(mfaToken string) *LoginCode {
+	return &LoginCode{
+		code:     domain.GenerateRandomString(loginCodeLength),
+		mfaToken: mfaToken,
+	}
+}
+
+func NewExistingLoginCode(code string, accessToken string, refreshToken string, mfaToken string) *LoginCode {
+	return &LoginCode{
+		code:         code,
+		accessToken:  accessToken,
+		refreshToken: refreshToken,
+		mfaToken:     mfaToken,
+	}
+}
+
+func (c LoginCode) Code() string {
+	return c.code
+}
+
+func (c LoginCode) AccessToken() string {
+	return c.accessToken
+}
+
+func (c LoginCode) RefreshToken() string {
+	return c.refreshToken
+}
+
+func (c LoginCode) MFAToken() string {
+	return c.mfaToken
+}
+
+func (c LoginCode) MFARequired() bool {
+	return c.mfaToken != ""
+}
@@ -0,0 +1,51 @@
+package vobjects
+
+import (
+	"time"
+
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+const (
+	magicLinkTokenLength = 32
+)
+
+// MagicLinkToken is a single-use token sent by email to log in without a
+// password.
+type MagicLinkToken struct {
+	token     string
+	email     string
+	expiredAt time.Time
+}
+
+func NewMagicLinkToken(email string, ttl time.Duration) *MagicLinkToken {
+	return &MagicLinkToken{
+		token:     domain.GenerateRandomString(magicLinkTokenLength),
+		email:     email,
+		expiredAt: time.Now().Add(ttl),
+	}
+}
+
+func NewExistingMagicLinkToken(token string, email string, expiredAt time.Time) *MagicLinkToken {
+	return &MagicLinkToken{
+		token:     token,
+		email:     email,
+		expiredAt: expiredAt,
+	}
+}
+
+func (t MagicLinkToken) Token() string {
+	return t.token
+}
+
+func (t MagicLinkToken) Email() string {
+	return t.email
+}
+
+func (t MagicLinkToken) ExpiredAt() time.Time {
+	return t.expiredAt
+}
+
+func (t MagicLinkToken) Valid() bool {
+	return t.expiredAt.After(time.Now())
+}
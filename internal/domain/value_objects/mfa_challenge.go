@@ -0,0 +1,56 @@
+package vobjects
+
+import (
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+const (
+	mfaChallengeTokenLength = 32
+)
+
+// MFAChallenge is issued by a password login of a user with MFA enabled
+// instead of tokens. The login is completed by sending the challenge token
+// together with a code of the second factor.
+type MFAChallenge struct {
+	token    string
+	userID   uuid.UUID
+	audience string
+	scope    string
+}
+
+func NewMFAChallenge(userID uuid.UUID, audience string, scope string) *MFAChallenge {
+	return &MFAChallenge{
+		token:    domain.GenerateRandomString(mfaChallengeTokenLength),
+		userID:   userID,
+		audience: audience,
+		scope:    scope,
+	}
+}
+
+func NewExistingMFAChallenge(token string, userID uuid.UUID, audience string, scope string) *MFAChallenge {
+	return &MFAChallenge{
+		token:    token,
+		userID:   userID,
+		audience: audience,
+		scope:    scope,
+	}
+}
+
+func (c MFAChallenge) Token() string {
+	return c.token
+}
+
+func (c MFAChallenge) UserID() uuid.UUID {
+	return c.userID
+}
+
+// Audience and Scope are the access token request of the login, tokens are
+// issued for them once the challenge is passed.
+func (c MFAChallenge) Audience() string {
+	return c.audience
+}
+
+func (c MFAChallenge) Scope() string {
+	return c.scope
+}
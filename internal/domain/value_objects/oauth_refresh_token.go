@@ -0,0 +1,46 @@
+package vobjects
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthRefreshToken is a refresh token issued to an OAuth client on behalf of
+// a user, limited to the scopes the user granted.
+type OAuthRefreshToken struct {
+	RefreshToken
+	clientID string
+	userID   uuid.UUID
+	scopes   Scopes
+}
+
+func NewOAuthRefreshToken(clientID string, userID uuid.UUID, scopes Scopes, ttl time.Duration) *OAuthRefreshToken {
+	return &OAuthRefreshToken{
+		RefreshToken: *NewRefreshToken(ttl),
+		clientID:     clientID,
+		userID:       userID,
+		scopes:       scopes,
+	}
+}
+
+func NewExistingOAuthRefreshToken(token string, clientID string, userID uuid.UUID, scopes Scopes, expiredAt time.Time) *OAuthRefreshToken {
+	return &OAuthRefreshToken{
+		RefreshToken: *NewExistingRefreshToken(token, expiredAt),
+		clientID:     clientID,
+		userID:       userID,
+		scopes:       scopes,
+	}
+}
+
+func (t OAuthRefreshToken) ClientID() string {
+	return t.clientID
+}
+
+func (t OAuthRefreshToken) UserID() uuid.UUID {
+	return t.userID
+}
+
+func (t OAuthRefreshToken) Scopes() Scopes {
+	return t.scopes
+}
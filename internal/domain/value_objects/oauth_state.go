@@ -0,0 +1,90 @@
+package vobjects
+
+import (
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+const (
+	oauthStateLength   = 32
+	oauthNonceLength   = 32
+	codeVerifierLength = 64
+)
+
+// OAuthState ties an OAuth callback to the login request that started it and
+// carries the PKCE code verifier and ID token nonce for that request. A state
+// with a link user id links the provider account to that user instead of
+// logging in. A referral code is used when the login signs the user up.
+type OAuthState struct {
+	state        string
+	provider     string
+	codeVerifier string
+	nonce        string
+	redirectURI  string
+	linkUserID   uuid.UUID
+	referralCode string
+}
+
+func NewOAuthState(provider string, redirectURI string, linkUserID uuid.UUID, referralCode string) *OAuthState {
+	return &OAuthState{
+		state:        domain.GenerateRandomString(oauthStateLength),
+		provider:     provider,
+		codeVerifier: domain.GenerateRandomString(codeVerifierLength),
+		nonce:        domain.GenerateRandomString(oauthNonceLength),
+		redirectURI:  redirectURI,
+		linkUserID:   linkUserID,
+		referralCode: referralCode,
+	}
+}
+
+func NewExistingOAuthState(
+	state string,
+	provider string,
+	codeVerifier string,
+	nonce string,
+	redirectURI string,
+	linkUserID uuid.UUID,
+	referralCode string,
+) *OAuthState {
+	return &OAuthState{
+		state:        state,
+		provider:     provider,
+		codeVerifier: codeVerifier,
+		nonce:        nonce,
+		redirectURI:  redirectURI,
+		linkUserID:   linkUserID,
+		referralCode: referralCode,
+	}
+}
+
+func (s OAuthState) State() string {
+	return s.state
+}
+
+func (s OAuthState) Provider() string {
+	return s.provider
+}
+
+func (s OAuthState) CodeVerifier() string {
+	return s.codeVerifier
+}
+
+func (s OAuthState) Nonce() string {
+	return s.nonce
+}
+
+func (s OAuthState) RedirectURI() string {
+	return s.redirectURI
+}
+
+func (s OAuthState) LinkUserID() uuid.UUID {
+	return s.linkUserID
+}
+
+func (s OAuthState) ReferralCode() string {
+	return s.referralCode
+}
+
+func (s OAuthState) IsLink() bool {
+	return s.linkUserID != uuid.Nil
+}
@@ -0,0 +1,75 @@
+package vobjects
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+)
+
+const (
+	recoveryCodeAlphabet   = "abcdefghjkmnpqrstuvwxyz23456789"
+	recoveryCodeGroupSize  = 5
+	recoveryCodeGroupCount = 2
+)
+
+// RecoveryCode is a single-use code that passes the MFA challenge instead of
+// a TOTP code, e.g. when the user lost the phone. Only the hash is stored.
+type RecoveryCode struct {
+	code string
+}
+
+func NewRecoveryCodes(count int) ([]RecoveryCode, error) {
+	codes := make([]RecoveryCode, 0, count)
+
+	for range count {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+func newRecoveryCode() (RecoveryCode, error) {
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	groups := make([]string, 0, recoveryCodeGroupCount)
+
+	for range recoveryCodeGroupCount {
+		group := make([]byte, recoveryCodeGroupSize)
+		for i := range group {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return RecoveryCode{}, err
+			}
+
+			group[i] = recoveryCodeAlphabet[n.Int64()]
+		}
+
+		groups = append(groups, string(group))
+	}
+
+	return RecoveryCode{code: strings.Join(groups, "-")}, nil
+}
+
+// Code returns the code shown to the user, e.g. abcde-23456.
+func (c RecoveryCode) Code() string {
+	return c.code
+}
+
+func (c RecoveryCode) Hash() string {
+	return HashRecoveryCode(c.code)
+}
+
+// HashRecoveryCode hashes a code entered by the user, case and separators
+// are ignored. Codes are random enough for a fast hash.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:])
+}
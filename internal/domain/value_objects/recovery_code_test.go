@@ -0,0 +1,33 @@
+package vobjects
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	format := regexp.MustCompile(`^[a-z2-9]{5}-[a-z2-9]{5}$`)
+	hashes := make(map[string]struct{}, len(codes))
+
+	for _, code := range codes {
+		require.Regexp(t, format, code.Code())
+		hashes[code.Hash()] = struct{}{}
+	}
+
+	require.Len(t, hashes, len(codes))
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	hash := HashRecoveryCode("abcde-23456")
+
+	require.Equal(t, hash, HashRecoveryCode("ABCDE-23456"))
+	require.Equal(t, hash, HashRecoveryCode("abcde 23456"))
+	require.Equal(t, hash, HashRecoveryCode("abcde23456"))
+	require.NotEqual(t, hash, HashRecoveryCode("abcde-23457"))
+}
@@ -0,0 +1,49 @@
+package vobjects
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+const (
+	referralCodeLength = 10
+)
+
+// ReferralCode is shared by a user to invite others, a user signed up with it
+// is recorded as referred by its owner.
+type ReferralCode struct {
+	code      string
+	userID    uuid.UUID
+	expiredAt time.Time
+}
+
+func NewReferralCode(userID uuid.UUID, ttl time.Duration) *ReferralCode {
+	return &ReferralCode{
+		code:      domain.GenerateRandomString(referralCodeLength),
+		userID:    userID,
+		expiredAt: time.Now().Add(ttl),
+	}
+}
+
+func NewExistingReferralCode(code string, userID uuid.UUID, expiredAt time.Time) *ReferralCode {
+	return &ReferralCode{
+		code:      code,
+		userID:    userID,
+		expiredAt: expiredAt,
+	}
+}
+
+func (c ReferralCode) Code() string {
+	return c.code
+}
+
+// UserID returns the referrer.
+func (c ReferralCode) UserID() uuid.UUID {
+	return c.userID
+}
+
+func (c ReferralCode) ExpiredAt() time.Time {
+	return c.expiredAt
+}
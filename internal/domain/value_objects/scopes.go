@@ -0,0 +1,58 @@
+package vobjects
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	ScopeOpenID = "openid"
+	ScopeEmail  = "email"
+)
+
+// Scopes is a set of OAuth scopes in the order they were requested.
+type Scopes []string
+
+// ParseScopes parses a space-delimited scope parameter, dropping duplicates.
+func ParseScopes(scope string) Scopes {
+	fields := strings.Fields(scope)
+
+	scopes := make(Scopes, 0, len(fields))
+	for _, field := range fields {
+		if !slices.Contains(scopes, field) {
+			scopes = append(scopes, field)
+		}
+	}
+
+	return scopes
+}
+
+func (s Scopes) String() string {
+	return strings.Join(s, " ")
+}
+
+func (s Scopes) Has(scope string) bool {
+	return slices.Contains(s, scope)
+}
+
+func (s Scopes) SubsetOf(other Scopes) bool {
+	for _, scope := range s {
+		if !other.Has(scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Intersect returns scopes of s that are also in other.
+func (s Scopes) Intersect(other Scopes) Scopes {
+	scopes := make(Scopes, 0, len(s))
+	for _, scope := range s {
+		if other.Has(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
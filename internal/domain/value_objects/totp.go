@@ -0,0 +1,107 @@
+package vobjects
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	totpSecretLength = 20
+	totpDigits       = 6
+	totpPeriod       = 30 * time.Second
+
+	// totpSkew is the number of periods before and after the current one in
+	// which a code is still accepted, to tolerate clock drift.
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPKey is a shared secret of the RFC 6238 time-based one-time password
+// algorithm with SHA-1, 6 digits and a 30 seconds period, which is what
+// authenticator apps support.
+type TOTPKey struct {
+	secret []byte
+}
+
+func NewTOTPKey() (TOTPKey, error) {
+	secret := make([]byte, totpSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return TOTPKey{}, err
+	}
+
+	return TOTPKey{secret: secret}, nil
+}
+
+func NewExistingTOTPKey(secret []byte) TOTPKey {
+	return TOTPKey{secret: secret}
+}
+
+func (k TOTPKey) Secret() []byte {
+	return k.secret
+}
+
+// Base32 returns the secret in the form entered into authenticator apps by
+// hand.
+func (k TOTPKey) Base32() string {
+	return totpEncoding.EncodeToString(k.secret)
+}
+
+// URI returns the otpauth URI shown to the user as a QR code.
+func (k TOTPKey) URI(issuer string, account string) string {
+	query := url.Values{}
+	query.Set("secret", k.Base32())
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	label := url.PathEscape(issuer + ":" + account)
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// Code returns the code for the given time.
+func (k TOTPKey) Code(t time.Time) string {
+	return k.stepCode(TOTPStep(t))
+}
+
+// Validate checks the code against the periods around the given time and
+// returns the step the code belongs to, so a used code can be rejected.
+func (k TOTPKey) Validate(code string, t time.Time) (step int64, ok bool) {
+	current := TOTPStep(t)
+
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(k.stepCode(step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+func (k TOTPKey) stepCode(step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, k.secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000)
+}
+
+// TOTPStep returns the number of the period the given time belongs to.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(totpPeriod.Seconds())
+}
@@ -0,0 +1,70 @@
+package vobjects
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA-1 secret of the RFC 6238 test vectors.
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestTOTPKeyCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	key := NewExistingTOTPKey(rfc6238Secret)
+
+	for _, tt := range tests {
+		clock := domain.FixedClock(time.Unix(tt.unix, 0))
+		require.Equal(t, tt.code, key.Code(clock.Now()), "time %d", tt.unix)
+	}
+}
+
+func TestTOTPKeyValidate(t *testing.T) {
+	key := NewExistingTOTPKey(rfc6238Secret)
+	clock := domain.FixedClock(time.Unix(1111111111, 0))
+
+	step, ok := key.Validate("050471", clock.Now())
+	require.True(t, ok)
+	require.Equal(t, TOTPStep(clock.Now()), step)
+
+	previous := clock.Now().Add(-totpPeriod)
+
+	step, ok = key.Validate(key.Code(previous), clock.Now())
+	require.True(t, ok)
+	require.Equal(t, TOTPStep(previous), step)
+
+	_, ok = key.Validate(key.Code(clock.Now().Add(-2*totpPeriod)), clock.Now())
+	require.False(t, ok)
+
+	_, ok = key.Validate("000000", clock.Now())
+	require.False(t, ok)
+}
+
+func TestTOTPKeyURI(t *testing.T) {
+	key := NewExistingTOTPKey(rfc6238Secret)
+
+	uri, err := url.Parse(key.URI("Auth Service", "user@example.com"))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Auth Service:user@example.com", uri.Path)
+	require.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", uri.Query().Get("secret"))
+	require.Equal(t, "Auth Service", uri.Query().Get("issuer"))
+	require.Equal(t, "6", uri.Query().Get("digits"))
+	require.Equal(t, "30", uri.Query().Get("period"))
+}
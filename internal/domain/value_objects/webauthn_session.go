@@ -0,0 +1,41 @@
+package vobjects
+
+import "github.com/google/uuid"
+
+type WebAuthnCeremony string
+
+const (
+	WebAuthnRegistration WebAuthnCeremony = "registration"
+	WebAuthnLogin        WebAuthnCeremony = "login"
+	WebAuthnMFA          WebAuthnCeremony = "mfa"
+)
+
+// WebAuthnSession is a started WebAuthn ceremony, it is found by the
+// challenge signed by the authenticator. The user is not known for a login
+// with a discoverable credential.
+type WebAuthnSession struct {
+	challenge []byte
+	ceremony  WebAuthnCeremony
+	userID    uuid.UUID
+}
+
+func NewWebAuthnSession(challenge []byte, ceremony WebAuthnCeremony, userID uuid.UUID) *WebAuthnSession {
+	return &WebAuthnSession{
+		challenge: challenge,
+		ceremony:  ceremony,
+		userID:    userID,
+	}
+}
+
+func (s WebAuthnSession) Challenge() []byte {
+	return s.challenge
+}
+
+func (s WebAuthnSession) Ceremony() WebAuthnCeremony {
+	return s.ceremony
+}
+
+// UserID returns uuid.Nil for a login with a discoverable credential.
+func (s WebAuthnSession) UserID() uuid.UUID {
+	return s.userID
+}
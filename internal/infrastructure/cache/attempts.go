@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"context"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+)
+
+// addAttemptScript counts an attempt in KEYS[2] while KEYS[1] exists, the
+// counter expires together with it. It returns 0 when KEYS[1] does not exist.
+var addAttemptScript = goredis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+	return 0
+end
+
+local attempts = redis.call('INCR', KEYS[2])
+redis.call('PEXPIRE', KEYS[2], ttl)
+
+return attempts
+`)
+
+// addAttempt atomically counts an attempt for the key, so concurrent
+// attempts can't exceed a limit checked against the result.
+func addAttempt(ctx context.Context, db redis.Database, key string, attemptsKey string) (int, error) {
+	return addAttemptScript.Run(ctx, db, []string{key, attemptsKey}).Int()
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	authorizationCodeKeyPrefix = "authorization_code:"
+)
+
+type AuthorizationCodeRepository struct {
+	db     redis.Database
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewAuthorizationCodeRepository(db redis.Database, log *slog.Logger, tracer trace.Tracer) *AuthorizationCodeRepository {
+	return &AuthorizationCodeRepository{
+		db:     db,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+func (r *AuthorizationCodeRepository) Create(ctx context.Context, code *vobjects.AuthorizationCode, ttl time.Duration) error {
+	ctx, span := r.tracer.Start(ctx, "AuthorizationCodeRepository.Create")
+	defer span.End()
+
+	request := code.Request()
+
+	data, err := json.Marshal(authorizationRequestToDTO(&request))
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.db.SetNX(ctx, authorizationCodeKeyPrefix+code.Code(), data, ttl).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.Wrap(repo.ErrDuplicate, "authorization code already exists")
+	}
+
+	return nil
+}
+
+func (r *AuthorizationCodeRepository) Pop(ctx context.Context, code string) (*vobjects.AuthorizationCode, error) {
+	ctx, span := r.tracer.Start(ctx, "AuthorizationCodeRepository.Pop")
+	defer span.End()
+
+	data, err := r.db.GetDel(ctx, authorizationCodeKeyPrefix+code).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "authorization code not exists")
+		}
+
+		return nil, err
+	}
+
+	var dto authorizationRequestDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, err
+	}
+
+	return vobjects.NewExistingAuthorizationCode(code, *dtoToAuthorizationRequest(dto)), nil
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	authorizationRequestKeyPrefix = "authorization_request:"
+)
+
+type authorizationRequestDTO struct {
+	ID            string    `json:"id"`
+	ClientID      string    `json:"client_id"`
+	UserID        uuid.UUID `json:"user_id"`
+	RedirectURI   string    `json:"redirect_uri"`
+	Scopes        []string  `json:"scopes"`
+	State         string    `json:"state"`
+	CodeChallenge string    `json:"code_challenge"`
+	Nonce         string    `json:"nonce"`
+}
+
+func authorizationRequestToDTO(request *vobjects.AuthorizationRequest) authorizationRequestDTO {
+	return authorizationRequestDTO{
+		ID:            request.ID(),
+		ClientID:      request.ClientID(),
+		UserID:        request.UserID(),
+		RedirectURI:   request.RedirectURI(),
+		Scopes:        request.Scopes(),
+		State:         request.State(),
+		CodeChallenge: request.CodeChallenge(),
+		Nonce:         request.Nonce(),
+	}
+}
+
+func dtoToAuthorizationRequest(dto authorizationRequestDTO) *vobjects.AuthorizationRequest {
+	return vobjects.NewExistingAuthorizationRequest(
+		dto.ID,
+		dto.ClientID,
+		dto.UserID,
+		dto.RedirectURI,
+		dto.Scopes,
+		dto.State,
+		dto.CodeChallenge,
+		dto.Nonce,
+	)
+}
+
+type AuthorizationRequestRepository struct {
+	db     redis.Database
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewAuthorizationRequestRepository(db redis.Database, log *slog.Logger, tracer trace.Tracer) *AuthorizationRequestRepository {
+	return &AuthorizationRequestRepository{
+		db:     db,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+func (r *AuthorizationRequestRepository) Create(ctx context.Context, request *vobjects.AuthorizationRequest, ttl time.Duration) error {
+	ctx, span := r.tracer.Start(ctx, "AuthorizationRequestRepository.Create")
+	defer span.End()
+
+	data, err := json.Marshal(authorizationRequestToDTO(request))
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.db.SetNX(ctx, authorizationRequestKeyPrefix+request.ID(), data, ttl).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.Wrap(repo.ErrDuplicate, "authorization request already exists")
+	}
+
+	return nil
+}
+
+func (r *AuthorizationRequestRepository) Get(ctx context.Context, id string) (*vobjects.AuthorizationRequest, error) {
+	ctx, span := r.tracer.Start(ctx, "AuthorizationRequestRepository.Get")
+	defer span.End()
+
+	return r.read(r.db.Get(ctx, authorizationRequestKeyPrefix+id))
+}
+
+func (r *AuthorizationRequestRepository) Pop(ctx context.Context, id string) (*vobjects.AuthorizationRequest, error) {
+	ctx, span := r.tracer.Start(ctx, "AuthorizationRequestRepository.Pop")
+	defer span.End()
+
+	return r.read(r.db.GetDel(ctx, authorizationRequestKeyPrefix+id))
+}
+
+func (r *AuthorizationRequestRepository) read(cmd *goredis.StringCmd) (*vobjects.AuthorizationRequest, error) {
+	data, err := cmd.Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "authorization request not exists")
+		}
+
+		return nil, err
+	}
+
+	var dto authorizationRequestDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, err
+	}
+
+	return dtoToAuthorizationRequest(dto), nil
+}
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	emailCodeKeyPrefix         = "email_code:"
+	emailCodeAttemptsKeyPrefix = "email_code_attempts:"
+)
+
+// consumeEmailCodeScript deletes the code in KEYS[1] and its attempts in
+// KEYS[2] if the stored code is ARGV[1], so a code replaced in the meantime
+// is kept. It returns 0 when the code is not stored.
+var consumeEmailCodeScript = goredis.NewScript(`
+local data = redis.call('GET', KEYS[1])
+if not data or cjson.decode(data).code ~= ARGV[1] then
+	return 0
+end
+
+return redis.call('DEL', KEYS[1], KEYS[2])
+`)
+
+type emailCodeDTO struct {
+	Code      string    `json:"code"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type EmailCodeRepository struct {
+	db     redis.Database
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewEmailCodeRepository(db redis.Database, log *slog.Logger, tracer trace.Tracer) *EmailCodeRepository {
+	return &EmailCodeRepository{
+		db:     db,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+func (r *EmailCodeRepository) Save(ctx context.Context, code *vobjects.EmailCode) error {
+	ctx, span := r.tracer.Start(ctx, "EmailCodeRepository.Save")
+	defer span.End()
+
+	data, err := marshalEmailCode(code)
+	if err != nil {
+		return err
+	}
+
+	// A new code starts without attempts.
+	_, err = r.db.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, emailCodeKey(code.Email(), code.Purpose()), data, time.Until(code.ExpiredAt()))
+		pipe.Del(ctx, emailCodeAttemptsKey(code.Email(), code.Purpose()))
+
+		return nil
+	})
+
+	return err
+}
+
+func (r *EmailCodeRepository) Get(ctx context.Context, email string, purpose vobjects.EmailCodePurpose) (*vobjects.EmailCode, error) {
+	ctx, span := r.tracer.Start(ctx, "EmailCodeRepository.Get")
+	defer span.End()
+
+	data, err := r.db.Get(ctx, emailCodeKey(email, purpose)).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "email code not exists")
+		}
+
+		return nil, err
+	}
+
+	var dto emailCodeDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, err
+	}
+
+	return vobjects.NewExistingEmailCode(dto.Code, email, purpose, dto.ExpiredAt), nil
+}
+
+func (r *EmailCodeRepository) AddAttempt(ctx context.Context, email string, purpose vobjects.EmailCodePurpose) (int, error) {
+	ctx, span := r.tracer.Start(ctx, "EmailCodeRepository.AddAttempt")
+	defer span.End()
+
+	attempts, err := addAttempt(ctx, r.db, emailCodeKey(email, purpose), emailCodeAttemptsKey(email, purpose))
+	if err != nil {
+		return 0, err
+	}
+
+	if attempts == 0 {
+		return 0, errors.Wrap(repo.ErrObjectNotFound, "email code not exists")
+	}
+
+	return attempts, nil
+}
+
+func (r *EmailCodeRepository) Consume(ctx context.Context, code *vobjects.EmailCode) error {
+	ctx, span := r.tracer.Start(ctx, "EmailCodeRepository.Consume")
+	defer span.End()
+
+	keys := []string{
+		emailCodeKey(code.Email(), code.Purpose()),
+		emailCodeAttemptsKey(code.Email(), code.Purpose()),
+	}
+
+	deleted, err := consumeEmailCodeScript.Run(ctx, r.db, keys, code.Code()).Int()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return errors.Wrap(repo.ErrObjectNotFound, "email code not exists")
+	}
+
+	return nil
+}
+
+func (r *EmailCodeRepository) Delete(ctx context.Context, email string, purpose vobjects.EmailCodePurpose) error {
+	ctx, span := r.tracer.Start(ctx, "EmailCodeRepository.Delete")
+	defer span.End()
+
+	return r.db.Del(ctx, emailCodeKey(email, purpose), emailCodeAttemptsKey(email, purpose)).Err()
+}
+
+func emailCodeKey(email string, purpose vobjects.EmailCodePurpose) string {
+	return emailCodeKeyPrefix + string(purpose) + ":" + strings.ToLower(email)
+}
+
+func emailCodeAttemptsKey(email string, purpose vobjects.EmailCodePurpose) string {
+	return emailCodeAttemptsKeyPrefix + string(purpose) + ":" + strings.ToLower(email)
+}
+
+func marshalEmailCode(code *vobjects.EmailCode) ([]byte, error) {
+	dto := emailCodeDTO{
+		Code:      code.Code(),
+		ExpiredAt: code.ExpiredAt(),
+	}
+
+	return json.Marshal(dto)
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	emailThrottleKeyPrefix = "email_throttle:"
+)
+
+type EmailThrottleRepository struct {
+	db     redis.Database
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewEmailThrottleRepository(db redis.Database, log *slog.Logger, tracer trace.Tracer) *EmailThrottleRepository {
+	return &EmailThrottleRepository{
+		db:     db,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+// Hit counts emails in a fixed window started by the first email to the
+// address.
+func (r *EmailThrottleRepository) Hit(ctx context.Context, email string, window time.Duration) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "EmailThrottleRepository.Hit")
+	defer span.End()
+
+	key := emailThrottleKeyPrefix + strings.ToLower(email)
+
+	count, err := r.db.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 1 {
+		if err := r.db.Expire(ctx, key, window).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	loginCodeKeyPrefix = "login_code:"
+)
+
+type loginCodeDTO struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	MFAToken     string `json:"mfa_token,omitempty"`
+}
+
+type LoginCodeRepository struct {
+	db     redis.Database
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewLoginCodeRepository(db redis.Database, log *slog.Logger, tracer trace.Tracer) *LoginCodeRepository {
+	return &LoginCodeRepository{
+		db:     db,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+func (r *LoginCodeRepository) Create(ctx context.Context, code *vobjects.LoginCode, ttl time.Duration) error {
+	ctx, span := r.tracer.Start(ctx, "LoginCodeRepository.Create")
+	defer span.End()
+
+	dto := loginCodeDTO{
+		AccessToken:  code.AccessToken(),
+		RefreshToken: code.RefreshToken(),
+		MFAToken:     code.MFAToken(),
+	}
+
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.db.SetNX(ctx, loginCodeKeyPrefix+code.Code(), data, ttl).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.Wrap(repo.ErrDuplicate, "login code already exists")
+	}
+
+	return nil
+}
+
+func (r *LoginCodeRepository) Pop(ctx context.Context, code string) (*vobjects.LoginCode, error) {
+	ctx, span := r.tracer.Start(ctx, "LoginCodeRepository.Pop")
+	defer span.End()
+
+	data, err := r.db.GetDel(ctx, loginCodeKeyPrefix+code).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "login code not exists")
+		}
+
+		return nil, err
+	}
+
+	var dto loginCodeDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, err
+	}
+
+	return vobjects.NewExistingLoginCode(code, dto.AccessToken, dto.RefreshToken, dto.MFAToken), nil
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	magicLinkKeyPrefix = "magic_link:"
+)
+
+type magicLinkDTO struct {
+	Email     string    `json:"email"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type MagicLinkRepository struct {
+	db     redis.Database
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewMagicLinkRepository(db redis.Database, log *slog.Logger, tracer trace.Tracer) *MagicLinkRepository {
+	return &MagicLinkRepository{
+		db:     db,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+// Create stores the token until it expires.
+func (r *MagicLinkRepository) Create(ctx context.Context, token *vobjects.MagicLinkToken) error {
+	ctx, span := r.tracer.Start(ctx, "MagicLinkRepository.Create")
+	defer span.End()
+
+	dto := magicLinkDTO{
+		Email:     token.Email(),
+		ExpiredAt: token.ExpiredAt(),
+	}
+
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.db.SetNX(ctx, magicLinkKeyPrefix+token.Token(), data, time.Until(token.ExpiredAt())).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.Wrap(repo.ErrDuplicate, "magic link token already exists")
+	}
+
+	return nil
+}
+
+// Pop deletes the token, so a link can be used only once.
+func (r *MagicLinkRepository) Pop(ctx context.Context, token string) (*vobjects.MagicLinkToken, error) {
+	ctx, span := r.tracer.Start(ctx, "MagicLinkRepository.Pop")
+	defer span.End()
+
+	data, err := r.db.GetDel(ctx, magicLinkKeyPrefix+token).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "magic link token not exists")
+		}
+
+		return nil, err
+	}
+
+	var dto magicLinkDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, err
+	}
+
+	return vobjects.NewExistingMagicLinkToken(token, dto.Email, dto.ExpiredAt), nil
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	mfaChallengeKeyPrefix         = "mfa_challenge:"
+	mfaChallengeAttemptsKeyPrefix = "mfa_challenge_attempts:"
+)
+
+type mfaChallengeDTO struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Audience string    `json:"audience"`
+	Scope    string    `json:"scope"`
+}
+
+type MFAChallengeRepository struct {
+	db     redis.Database
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewMFAChallengeRepository(db redis.Database, log *slog.Logger, tracer trace.Tracer) *MFAChallengeRepository {
+	return &MFAChallengeRepository{
+		db:     db,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+func (r *MFAChallengeRepository) Create(ctx context.Context, challenge *vobjects.MFAChallenge, ttl time.Duration) error {
+	ctx, span := r.tracer.Start(ctx, "MFAChallengeRepository.Create")
+	defer span.End()
+
+	data, err := marshalMFAChallenge(challenge)
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.db.SetNX(ctx, mfaChallengeKeyPrefix+challenge.Token(), data, ttl).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.Wrap(repo.ErrDuplicate, "mfa challenge already exists")
+	}
+
+	return nil
+}
+
+func (r *MFAChallengeRepository) Get(ctx context.Context, token string) (*vobjects.MFAChallenge, error) {
+	ctx, span := r.tracer.Start(ctx, "MFAChallengeRepository.Get")
+	defer span.End()
+
+	data, err := r.db.Get(ctx, mfaChallengeKeyPrefix+token).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "mfa challenge not exists")
+		}
+
+		return nil, err
+	}
+
+	return unmarshalMFAChallenge(token, data)
+}
+
+func (r *MFAChallengeRepository) AddAttempt(ctx context.Context, token string) (int, error) {
+	ctx, span := r.tracer.Start(ctx, "MFAChallengeRepository.AddAttempt")
+	defer span.End()
+
+	attempts, err := addAttempt(ctx, r.db, mfaChallengeKeyPrefix+token, mfaChallengeAttemptsKeyPrefix+token)
+	if err != nil {
+		return 0, err
+	}
+
+	if attempts == 0 {
+		return 0, errors.Wrap(repo.ErrObjectNotFound, "mfa challenge not exists")
+	}
+
+	return attempts, nil
+}
+
+func (r *MFAChallengeRepository) Pop(ctx context.Context, token string) (*vobjects.MFAChallenge, error) {
+	ctx, span := r.tracer.Start(ctx, "MFAChallengeRepository.Pop")
+	defer span.End()
+
+	data, err := r.db.GetDel(ctx, mfaChallengeKeyPrefix+token).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "mfa challenge not exists")
+		}
+
+		return nil, err
+	}
+
+	if err := r.db.Del(ctx, mfaChallengeAttemptsKeyPrefix+token).Err(); err != nil {
+		return nil, err
+	}
+
+	return unmarshalMFAChallenge(token, data)
+}
+
+func (r *MFAChallengeRepository) Delete(ctx context.Context, token string) error {
+	ctx, span := r.tracer.Start(ctx, "MFAChallengeRepository.Delete")
+	defer span.End()
+
+	return r.db.Del(ctx, mfaChallengeKeyPrefix+token, mfaChallengeAttemptsKeyPrefix+token).Err()
+}
+
+func marshalMFAChallenge(challenge *vobjects.MFAChallenge) ([]byte, error) {
+	dto := mfaChallengeDTO{
+		UserID:   challenge.UserID(),
+		Audience: challenge.Audience(),
+		Scope:    challenge.Scope(),
+	}
+
+	return json.Marshal(dto)
+}
+
+func unmarshalMFAChallenge(token string, data []byte) (*vobjects.MFAChallenge, error) {
+	var dto mfaChallengeDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, err
+	}
+
+	return vobjects.NewExistingMFAChallenge(token, dto.UserID, dto.Audience, dto.Scope), nil
+}
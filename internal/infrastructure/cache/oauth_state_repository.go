@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	oauthStateKeyPrefix = "oauth_state:"
+)
+
+type oauthStateDTO struct {
+	Provider     string    `json:"provider"`
+	CodeVerifier string    `json:"code_verifier"`
+	Nonce        string    `json:"nonce"`
+	RedirectURI  string    `json:"redirect_uri"`
+	LinkUserID   uuid.UUID `json:"link_user_id"`
+	ReferralCode string    `json:"referral_code,omitempty"`
+}
+
+type OAuthStateRepository struct {
+	db     redis.Database
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewOAuthStateRepository(db redis.Database, log *slog.Logger, tracer trace.Tracer) *OAuthStateRepository {
+	return &OAuthStateRepository{
+		db:     db,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+func (r *OAuthStateRepository) Create(ctx context.Context, state *vobjects.OAuthState, ttl time.Duration) error {
+	ctx, span := r.tracer.Start(ctx, "OAuthStateRepository.Create")
+	defer span.End()
+
+	dto := oauthStateDTO{
+		Provider:     state.Provider(),
+		CodeVerifier: state.CodeVerifier(),
+		Nonce:        state.Nonce(),
+		RedirectURI:  state.RedirectURI(),
+		LinkUserID:   state.LinkUserID(),
+		ReferralCode: state.ReferralCode(),
+	}
+
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.db.SetNX(ctx, oauthStateKeyPrefix+state.State(), data, ttl).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.Wrap(repo.ErrDuplicate, "oauth state already exists")
+	}
+
+	return nil
+}
+
+func (r *OAuthStateRepository) Pop(ctx context.Context, state string) (*vobjects.OAuthState, error) {
+	ctx, span := r.tracer.Start(ctx, "OAuthStateRepository.Pop")
+	defer span.End()
+
+	data, err := r.db.GetDel(ctx, oauthStateKeyPrefix+state).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "oauth state not exists")
+		}
+
+		return nil, err
+	}
+
+	var dto oauthStateDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, err
+	}
+
+	return vobjects.NewExistingOAuthState(state, dto.Provider, dto.CodeVerifier, dto.Nonce, dto.RedirectURI, dto.LinkUserID, dto.ReferralCode), nil
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	referralCodeKeyPrefix     = "referral_code:"
+	userReferralCodeKeyPrefix = "user_referral_code:"
+)
+
+type referralCodeDTO struct {
+	Code      string    `json:"code"`
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+// ReferralCodeRepository keeps a referral code under the code, to find the
+// referrer at sign-up, and under the user, to give the user the same code
+// until it expires.
+type ReferralCodeRepository struct {
+	db     redis.Database
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewReferralCodeRepository(db redis.Database, log *slog.Logger, tracer trace.Tracer) *ReferralCodeRepository {
+	return &ReferralCodeRepository{
+		db:     db,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+func (r *ReferralCodeRepository) Create(ctx context.Context, code *vobjects.ReferralCode) error {
+	ctx, span := r.tracer.Start(ctx, "ReferralCodeRepository.Create")
+	defer span.End()
+
+	dto := referralCodeDTO{
+		Code:      code.Code(),
+		UserID:    code.UserID(),
+		ExpiredAt: code.ExpiredAt(),
+	}
+
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Until(code.ExpiredAt())
+
+	ok, err := r.db.SetNX(ctx, referralCodeKeyPrefix+code.Code(), data, ttl).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.Wrap(repo.ErrDuplicate, "referral code already exists")
+	}
+
+	return r.db.Set(ctx, userReferralCodeKeyPrefix+code.UserID().String(), data, ttl).Err()
+}
+
+func (r *ReferralCodeRepository) GetByCode(ctx context.Context, code string) (*vobjects.ReferralCode, error) {
+	ctx, span := r.tracer.Start(ctx, "ReferralCodeRepository.GetByCode")
+	defer span.End()
+
+	return r.get(ctx, referralCodeKeyPrefix+code)
+}
+
+func (r *ReferralCodeRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*vobjects.ReferralCode, error) {
+	ctx, span := r.tracer.Start(ctx, "ReferralCodeRepository.GetByUserID")
+	defer span.End()
+
+	return r.get(ctx, userReferralCodeKeyPrefix+userID.String())
+}
+
+func (r *ReferralCodeRepository) get(ctx context.Context, key string) (*vobjects.ReferralCode, error) {
+	data, err := r.db.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "referral code not exists")
+		}
+
+		return nil, err
+	}
+
+	var dto referralCodeDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, err
+	}
+
+	return vobjects.NewExistingReferralCode(dto.Code, dto.UserID, dto.ExpiredAt), nil
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"github.com/rozhnof/auth-service/internal/infrastructure/database/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	webauthnSessionKeyPrefix = "webauthn_session:"
+)
+
+type webauthnSessionDTO struct {
+	Ceremony string    `json:"ceremony"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+type WebAuthnSessionRepository struct {
+	db     redis.Database
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewWebAuthnSessionRepository(db redis.Database, log *slog.Logger, tracer trace.Tracer) *WebAuthnSessionRepository {
+	return &WebAuthnSessionRepository{
+		db:     db,
+		log:    log,
+		tracer: tracer,
+	}
+}
+
+func (r *WebAuthnSessionRepository) Create(ctx context.Context, session *vobjects.WebAuthnSession, ttl time.Duration) error {
+	ctx, span := r.tracer.Start(ctx, "WebAuthnSessionRepository.Create")
+	defer span.End()
+
+	dto := webauthnSessionDTO{
+		Ceremony: string(session.Ceremony()),
+		UserID:   session.UserID(),
+	}
+
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.db.SetNX(ctx, webauthnSessionKey(session.Challenge()), data, ttl).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.Wrap(repo.ErrDuplicate, "webauthn session already exists")
+	}
+
+	return nil
+}
+
+// Pop deletes the session, so a challenge can be used only once.
+func (r *WebAuthnSessionRepository) Pop(ctx context.Context, challenge []byte) (*vobjects.WebAuthnSession, error) {
+	ctx, span := r.tracer.Start(ctx, "WebAuthnSessionRepository.Pop")
+	defer span.End()
+
+	data, err := r.db.GetDel(ctx, webauthnSessionKey(challenge)).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "webauthn session not exists")
+		}
+
+		return nil, err
+	}
+
+	var dto webauthnSessionDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, err
+	}
+
+	return vobjects.NewWebAuthnSession(challenge, vobjects.WebAuthnCeremony(dto.Ceremony), dto.UserID), nil
+}
+
+func webauthnSessionKey(challenge []byte) string {
+	return webauthnSessionKeyPrefix + base64.RawURLEncoding.EncodeToString(challenge)
+}
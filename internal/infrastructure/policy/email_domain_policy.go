@@ -0,0 +1,154 @@
+package policy
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type EmailDomainPolicyConfig struct {
+	AllowListPath string
+	DenyListPath  string
+	AllowedOnly   bool
+}
+
+type EmailDomainPolicy struct {
+	cfg EmailDomainPolicyConfig
+	log *slog.Logger
+
+	mu    sync.RWMutex
+	allow map[string]struct{}
+	deny  map[string]struct{}
+}
+
+func NewEmailDomainPolicy(cfg EmailDomainPolicyConfig, log *slog.Logger) (*EmailDomainPolicy, error) {
+	p := &EmailDomainPolicy{
+		cfg: cfg,
+		log: log,
+	}
+
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Reload rereads allow and deny lists from disk. On failure the previously
+// loaded lists stay in effect.
+func (p *EmailDomainPolicy) Reload() error {
+	allow, err := readDomainList(p.cfg.AllowListPath)
+	if err != nil {
+		return errors.Wrap(err, "read allow list")
+	}
+
+	deny, err := readDomainList(p.cfg.DenyListPath)
+	if err != nil {
+		return errors.Wrap(err, "read deny list")
+	}
+
+	p.mu.Lock()
+	p.allow = allow
+	p.deny = deny
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Run reloads the lists every interval until ctx is done.
+func (p *EmailDomainPolicy) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Reload(); err != nil {
+				p.log.Warn("failed reload email domain lists", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// Allowed reports whether the email domain passes the policy. The most specific
+// listed domain wins, so "corp.example.com" in the allow list overrides
+// "example.com" in the deny list.
+func (p *EmailDomainPolicy) Allowed(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return false
+	}
+
+	domain := normalizeDomain(email[at+1:])
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for {
+		if _, ok := p.allow[domain]; ok {
+			return true
+		}
+
+		if _, ok := p.deny[domain]; ok {
+			return false
+		}
+
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			break
+		}
+
+		domain = domain[dot+1:]
+	}
+
+	return !p.cfg.AllowedOnly
+}
+
+func readDomainList(path string) (map[string]struct{}, error) {
+	domains := make(map[string]struct{})
+
+	if path == "" {
+		return domains, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		domains[normalizeDomain(line)] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return domains, nil
+}
+
+// normalizeDomain lowercases the domain and drops the trailing dot of a fully
+// qualified name, "Example.COM." is the same domain as "example.com".
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(domain), ".")
+}
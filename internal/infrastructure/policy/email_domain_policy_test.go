@@ -0,0 +1,154 @@
+package policy
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func writeDomainList(t *testing.T, path string, domains ...string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(domains, "\n")), 0o600))
+}
+
+func newTestEmailDomainPolicy(t *testing.T, allow []string, deny []string, allowedOnly bool) (*EmailDomainPolicy, EmailDomainPolicyConfig) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	cfg := EmailDomainPolicyConfig{
+		AllowListPath: filepath.Join(dir, "allow.txt"),
+		DenyListPath:  filepath.Join(dir, "deny.txt"),
+		AllowedOnly:   allowedOnly,
+	}
+
+	writeDomainList(t, cfg.AllowListPath, allow...)
+	writeDomainList(t, cfg.DenyListPath, deny...)
+
+	policy, err := NewEmailDomainPolicy(cfg, testLogger)
+	require.NoError(t, err)
+
+	return policy, cfg
+}
+
+func TestEmailDomainPolicyAllowed(t *testing.T) {
+	policy, _ := newTestEmailDomainPolicy(t,
+		[]string{"# partners", "corp.example.com", "", "  Partner.ORG  ", "both.net"},
+		[]string{"example.com", "mail.corp.example.com", "spam.", "both.net"},
+		false,
+	)
+
+	tests := []struct {
+		email   string
+		allowed bool
+	}{
+		{email: "user@other.com", allowed: true},
+		{email: "user@example.com", allowed: false},
+		{email: "user@sub.example.com", allowed: false},
+		// The most specific listed domain wins.
+		{email: "user@corp.example.com", allowed: true},
+		{email: "user@team.corp.example.com", allowed: true},
+		{email: "user@mail.corp.example.com", allowed: false},
+		{email: "user@x.mail.corp.example.com", allowed: false},
+		// A domain in both lists is allowed.
+		{email: "user@both.net", allowed: true},
+		// Case and the trailing dot don't matter.
+		{email: "user@EXAMPLE.com", allowed: false},
+		{email: "user@example.com.", allowed: false},
+		{email: "user@Sub.Example.Com.", allowed: false},
+		{email: "user@partner.org", allowed: true},
+		{email: "user@domain.spam", allowed: false},
+		// Only whole labels match.
+		{email: "user@notexample.com", allowed: true},
+		{email: "user@example.com.evil.org", allowed: true},
+		// The domain is taken after the last @.
+		{email: "user@example.com@other.com", allowed: true},
+		{email: "user@other.com@example.com", allowed: false},
+		{email: "user", allowed: false},
+		{email: "user@", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			require.Equal(t, tt.allowed, policy.Allowed(tt.email))
+		})
+	}
+}
+
+func TestEmailDomainPolicyAllowedOnly(t *testing.T) {
+	policy, _ := newTestEmailDomainPolicy(t, []string{"example.com"}, []string{"guest.example.com"}, true)
+
+	require.True(t, policy.Allowed("user@example.com"))
+	require.True(t, policy.Allowed("user@team.example.com"))
+	require.False(t, policy.Allowed("user@guest.example.com"))
+	require.False(t, policy.Allowed("user@other.com"))
+}
+
+func TestEmailDomainPolicyWithoutLists(t *testing.T) {
+	policy, err := NewEmailDomainPolicy(EmailDomainPolicyConfig{}, testLogger)
+	require.NoError(t, err)
+	require.True(t, policy.Allowed("user@example.com"))
+
+	_, err = NewEmailDomainPolicy(EmailDomainPolicyConfig{
+		DenyListPath: filepath.Join(t.TempDir(), "missing.txt"),
+	}, testLogger)
+	require.Error(t, err)
+}
+
+func TestEmailDomainPolicyReload(t *testing.T) {
+	policy, cfg := newTestEmailDomainPolicy(t, nil, []string{"example.com"}, false)
+	require.False(t, policy.Allowed("user@example.com"))
+
+	writeDomainList(t, cfg.DenyListPath, "other.com")
+	require.NoError(t, policy.Reload())
+	require.True(t, policy.Allowed("user@example.com"))
+	require.False(t, policy.Allowed("user@other.com"))
+
+	// A failed reload keeps the lists loaded before.
+	require.NoError(t, os.Remove(cfg.AllowListPath))
+	require.Error(t, policy.Reload())
+	require.False(t, policy.Allowed("user@other.com"))
+}
+
+func TestEmailDomainPolicyRun(t *testing.T) {
+	policy, cfg := newTestEmailDomainPolicy(t, nil, []string{"example.com"}, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		policy.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	writeDomainList(t, cfg.DenyListPath, "other.com")
+
+	require.Eventually(t, func() bool {
+		return policy.Allowed("user@example.com") && !policy.Allowed("user@other.com")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Reloads fail while the list is missing, the last lists stay in effect.
+	require.NoError(t, os.Remove(cfg.DenyListPath))
+	time.Sleep(50 * time.Millisecond)
+	require.False(t, policy.Allowed("user@other.com"))
+
+	writeDomainList(t, cfg.DenyListPath, "third.com")
+
+	require.Eventually(t, func() bool {
+		return policy.Allowed("user@other.com") && !policy.Allowed("user@third.com")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,68 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AdminAuditLogRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewAdminAuditLogRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *AdminAuditLogRepository {
+	return &AdminAuditLogRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *AdminAuditLogRepository) Create(ctx context.Context, record *entities.AdminAuditRecord) error {
+	ctx, span := s.tracer.Start(ctx, "AdminAuditLogRepository.Create")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.CreateAdminAuditLogParams{
+		ActorID:      record.ActorID(),
+		Action:       record.Action(),
+		TargetUserID: record.TargetUserID(),
+		CreatedAt:    record.CreatedAt(),
+	}
+
+	return querier.CreateAdminAuditLog(ctx, args)
+}
+
+func (s *AdminAuditLogRepository) ListByTargetUserID(ctx context.Context, userID uuid.UUID) ([]*entities.AdminAuditRecord, error) {
+	ctx, span := s.tracer.Start(ctx, "AdminAuditLogRepository.ListByTargetUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.ListAdminAuditLogByTargetUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*entities.AdminAuditRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, entities.NewExistingAdminAuditRecord(
+			row.AdminAuditLog.ActorID,
+			row.AdminAuditLog.Action,
+			row.AdminAuditLog.TargetUserID,
+			row.AdminAuditLog.CreatedAt,
+		))
+	}
+
+	return records, nil
+}
@@ -0,0 +1,105 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type DataExportRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewDataExportRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *DataExportRepository {
+	return &DataExportRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *DataExportRepository) Create(ctx context.Context, export *entities.DataExport) error {
+	ctx, span := s.tracer.Start(ctx, "DataExportRepository.Create")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.CreateDataExportParams{
+		ID:          export.ID(),
+		UserID:      export.UserID(),
+		Status:      export.Status(),
+		RequestedAt: export.RequestedAt(),
+	}
+
+	return querier.CreateDataExport(ctx, args)
+}
+
+func (s *DataExportRepository) Update(ctx context.Context, export *entities.DataExport) error {
+	ctx, span := s.tracer.Start(ctx, "DataExportRepository.Update")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.UpdateDataExportParams{
+		ID:          export.ID(),
+		Status:      export.Status(),
+		Data:        export.Data(),
+		CompletedAt: export.CompletedAt(),
+		ExpiresAt:   export.ExpiresAt(),
+	}
+
+	return querier.UpdateDataExport(ctx, args)
+}
+
+func (s *DataExportRepository) GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*entities.DataExport, error) {
+	ctx, span := s.tracer.Start(ctx, "DataExportRepository.GetLatestByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	row, err := querier.GetLatestDataExportByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repo.ErrObjectNotFound, "data export of user with id = %s not exists", userID.String())
+		}
+
+		return nil, err
+	}
+
+	return dtoToDataExport(row.DataExport), nil
+}
+
+// ListPending locks the returned exports until the end of the transaction, so
+// concurrent workers pick different exports.
+func (s *DataExportRepository) ListPending(ctx context.Context, limit int32) ([]*entities.DataExport, error) {
+	ctx, span := s.tracer.Start(ctx, "DataExportRepository.ListPending")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.ListPendingDataExports(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make([]*entities.DataExport, 0, len(rows))
+	for _, row := range rows {
+		exports = append(exports, dtoToDataExport(row.DataExport))
+	}
+
+	return exports, nil
+}
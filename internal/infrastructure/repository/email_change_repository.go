@@ -0,0 +1,81 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type EmailChangeRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewEmailChangeRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *EmailChangeRepository {
+	return &EmailChangeRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+// Save replaces a pending change of the user, if any.
+func (s *EmailChangeRepository) Save(ctx context.Context, change *entities.EmailChange) error {
+	ctx, span := s.tracer.Start(ctx, "EmailChangeRepository.Save")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.SaveEmailChangeParams{
+		UserID:    change.UserID(),
+		NewEmail:  change.NewEmail(),
+		Token:     change.Token(),
+		ExpiredAt: change.ExpiredAt(),
+	}
+
+	return querier.SaveEmailChange(ctx, args)
+}
+
+func (s *EmailChangeRepository) GetByToken(ctx context.Context, token string) (*entities.EmailChange, error) {
+	ctx, span := s.tracer.Start(ctx, "EmailChangeRepository.GetByToken")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	row, err := querier.GetEmailChangeByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "email change not exists")
+		}
+
+		return nil, err
+	}
+
+	return entities.NewExistingEmailChange(
+		row.EmailChange.UserID,
+		row.EmailChange.NewEmail,
+		row.EmailChange.Token,
+		row.EmailChange.ExpiredAt,
+	), nil
+}
+
+func (s *EmailChangeRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "EmailChangeRepository.Delete")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	return querier.DeleteEmailChange(ctx, userID)
+}
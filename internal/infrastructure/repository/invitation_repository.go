@@ -0,0 +1,179 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type InvitationRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewInvitationRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *InvitationRepository {
+	return &InvitationRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *InvitationRepository) Create(ctx context.Context, invitation *entities.Invitation) error {
+	ctx, span := s.tracer.Start(ctx, "InvitationRepository.Create")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.CreateInvitationParams{
+		ID:        invitation.ID(),
+		InviterID: invitation.InviterID(),
+		Email:     invitation.Email(),
+		Role:      stringToDTO(invitation.Role()),
+		Token:     invitation.Token(),
+		ExpiredAt: invitation.ExpiredAt(),
+		CreatedAt: invitation.CreatedAt(),
+	}
+
+	if err := querier.CreateInvitation(ctx, args); err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				return errors.Wrapf(repo.ErrDuplicate, "invitation for email = %s already exists", invitation.Email())
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *InvitationRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Invitation, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationRepository.GetByID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	row, err := querier.GetInvitationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "invitation not exists")
+		}
+
+		return nil, err
+	}
+
+	return dtoToInvitation(row.Invitation), nil
+}
+
+func (s *InvitationRepository) GetByEmail(ctx context.Context, email string) (*entities.Invitation, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationRepository.GetByEmail")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	row, err := querier.GetInvitationByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "invitation not exists")
+		}
+
+		return nil, err
+	}
+
+	return dtoToInvitation(row.Invitation), nil
+}
+
+func (s *InvitationRepository) GetByToken(ctx context.Context, token string) (*entities.Invitation, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationRepository.GetByToken")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	row, err := querier.GetInvitationByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "invitation not exists")
+		}
+
+		return nil, err
+	}
+
+	return dtoToInvitation(row.Invitation), nil
+}
+
+func (s *InvitationRepository) List(ctx context.Context) ([]*entities.Invitation, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationRepository.List")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.ListInvitations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]*entities.Invitation, 0, len(rows))
+	for _, row := range rows {
+		invitations = append(invitations, dtoToInvitation(row.Invitation))
+	}
+
+	return invitations, nil
+}
+
+func (s *InvitationRepository) ListByInviterID(ctx context.Context, inviterID uuid.UUID) ([]*entities.Invitation, error) {
+	ctx, span := s.tracer.Start(ctx, "InvitationRepository.ListByInviterID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.ListInvitationsByInviterID(ctx, inviterID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]*entities.Invitation, 0, len(rows))
+	for _, row := range rows {
+		invitations = append(invitations, dtoToInvitation(row.Invitation))
+	}
+
+	return invitations, nil
+}
+
+func (s *InvitationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "InvitationRepository.Delete")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.DeleteInvitation(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.Wrap(repo.ErrObjectNotFound, "invitation not exists")
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type MFARecoveryCodeRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewMFARecoveryCodeRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *MFARecoveryCodeRepository {
+	return &MFARecoveryCodeRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *MFARecoveryCodeRepository) Replace(ctx context.Context, userID uuid.UUID, codeHashes []string, createdAt time.Time) error {
+	ctx, span := s.tracer.Start(ctx, "MFARecoveryCodeRepository.Replace")
+	defer span.End()
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		db := s.txManager.TxOrDB(ctx)
+		querier := db_queries.New(db)
+
+		if err := querier.DeleteMFARecoveryCodesByUserID(ctx, userID); err != nil {
+			return err
+		}
+
+		args := make([]db_queries.CreateMFARecoveryCodesParams, 0, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			args = append(args, db_queries.CreateMFARecoveryCodesParams{
+				UserID:    userID,
+				CodeHash:  codeHash,
+				CreatedAt: createdAt,
+			})
+		}
+
+		if _, err := querier.CreateMFARecoveryCodes(ctx, args); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (s *MFARecoveryCodeRepository) Use(ctx context.Context, userID uuid.UUID, codeHash string, usedAt time.Time) error {
+	ctx, span := s.tracer.Start(ctx, "MFARecoveryCodeRepository.Use")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.UseMFARecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: codeHash,
+		UsedAt:   &usedAt,
+	}
+
+	rows, err := querier.UseMFARecoveryCode(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.Wrap(repo.ErrObjectNotFound, "unused recovery code not exists")
+	}
+
+	return nil
+}
+
+func (s *MFARecoveryCodeRepository) CountUnused(ctx context.Context, userID uuid.UUID) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "MFARecoveryCodeRepository.CountUnused")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	return querier.CountUnusedMFARecoveryCodes(ctx, userID)
+}
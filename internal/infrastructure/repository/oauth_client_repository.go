@@ -0,0 +1,81 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type OAuthClientRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewOAuthClientRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *OAuthClientRepository {
+	return &OAuthClientRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *OAuthClientRepository) Create(ctx context.Context, client *entities.OAuthClient) error {
+	ctx, span := s.tracer.Start(ctx, "OAuthClientRepository.Create")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.CreateOAuthClientParams{
+		ID:            client.ID(),
+		Name:          client.Name(),
+		SecretHash:    passwordToDTO(client.Secret()),
+		RedirectUris:  client.RedirectURIs(),
+		AllowedScopes: client.AllowedScopes(),
+		GrantTypes:    client.GrantTypes(),
+		CreatedAt:     client.CreatedAt(),
+	}
+
+	if err := querier.CreateOAuthClient(ctx, args); err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				return errors.Wrapf(repo.ErrDuplicate, "oauth client with id = %s already exists", client.ID())
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *OAuthClientRepository) GetByID(ctx context.Context, clientID string) (*entities.OAuthClient, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthClientRepository.GetByID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	row, err := querier.GetOAuthClientByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repo.ErrObjectNotFound, "oauth client with id = %s not exists", clientID)
+		}
+
+		return nil, err
+	}
+
+	return dtoToOAuthClient(row.OauthClient), nil
+}
@@ -0,0 +1,90 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type OAuthConsentRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewOAuthConsentRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *OAuthConsentRepository {
+	return &OAuthConsentRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *OAuthConsentRepository) Save(ctx context.Context, consent *entities.OAuthConsent) error {
+	ctx, span := s.tracer.Start(ctx, "OAuthConsentRepository.Save")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.SaveOAuthConsentParams{
+		UserID:    consent.UserID(),
+		ClientID:  consent.ClientID(),
+		Scopes:    consent.Scopes(),
+		GrantedAt: consent.GrantedAt(),
+	}
+
+	return querier.SaveOAuthConsent(ctx, args)
+}
+
+func (s *OAuthConsentRepository) Get(ctx context.Context, userID uuid.UUID, clientID string) (*entities.OAuthConsent, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthConsentRepository.Get")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.GetOAuthConsentParams{
+		UserID:   userID,
+		ClientID: clientID,
+	}
+
+	row, err := querier.GetOAuthConsent(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repo.ErrObjectNotFound, "consent of user with id = %s for client %s not exists", userID.String(), clientID)
+		}
+
+		return nil, err
+	}
+
+	return dtoToOAuthConsent(row.OauthConsent), nil
+}
+
+func (s *OAuthConsentRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.OAuthConsent, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthConsentRepository.ListByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.ListOAuthConsentsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	consents := make([]*entities.OAuthConsent, 0, len(rows))
+	for _, row := range rows {
+		consents = append(consents, dtoToOAuthConsent(row.OauthConsent))
+	}
+
+	return consents, nil
+}
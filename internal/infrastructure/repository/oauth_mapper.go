@@ -0,0 +1,38 @@
+package pgrepo
+
+import (
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+)
+
+func dtoToOAuthClient(client db_queries.OauthClient) *entities.OAuthClient {
+	return entities.NewExistingOAuthClient(
+		client.ID,
+		client.Name,
+		dtoToPassword(client.SecretHash),
+		client.RedirectUris,
+		client.AllowedScopes,
+		client.GrantTypes,
+		client.CreatedAt,
+	)
+}
+
+func dtoToOAuthConsent(consent db_queries.OauthConsent) *entities.OAuthConsent {
+	return entities.NewExistingOAuthConsent(
+		consent.UserID,
+		consent.ClientID,
+		consent.Scopes,
+		consent.GrantedAt,
+	)
+}
+
+func dtoToOAuthRefreshToken(token db_queries.OauthRefreshToken) *vobjects.OAuthRefreshToken {
+	return vobjects.NewExistingOAuthRefreshToken(
+		token.Token,
+		token.ClientID,
+		token.UserID,
+		token.Scopes,
+		token.ExpiredAt,
+	)
+}
@@ -0,0 +1,100 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type OAuthRefreshTokenRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewOAuthRefreshTokenRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *OAuthRefreshTokenRepository {
+	return &OAuthRefreshTokenRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *OAuthRefreshTokenRepository) Create(ctx context.Context, token *vobjects.OAuthRefreshToken) error {
+	ctx, span := s.tracer.Start(ctx, "OAuthRefreshTokenRepository.Create")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.CreateOAuthRefreshTokenParams{
+		Token:     token.Token(),
+		ClientID:  token.ClientID(),
+		UserID:    token.UserID(),
+		Scopes:    token.Scopes(),
+		ExpiredAt: token.ExpiredAt(),
+	}
+
+	return querier.CreateOAuthRefreshToken(ctx, args)
+}
+
+// Pop deletes the token, so a refresh token can be used only once.
+func (s *OAuthRefreshTokenRepository) Pop(ctx context.Context, token string) (*vobjects.OAuthRefreshToken, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthRefreshTokenRepository.Pop")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	row, err := querier.PopOAuthRefreshToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "oauth refresh token not exists")
+		}
+
+		return nil, err
+	}
+
+	return dtoToOAuthRefreshToken(row), nil
+}
+
+// DeleteByUserID revokes refresh tokens issued to all clients for the user.
+func (s *OAuthRefreshTokenRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "OAuthRefreshTokenRepository.DeleteByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	return querier.DeleteOAuthRefreshTokensByUserID(ctx, userID)
+}
+
+// ListByUserID returns unexpired refresh tokens issued to all clients for the
+// user.
+func (s *OAuthRefreshTokenRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*vobjects.OAuthRefreshToken, error) {
+	ctx, span := s.tracer.Start(ctx, "OAuthRefreshTokenRepository.ListByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.ListOAuthRefreshTokensByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*vobjects.OAuthRefreshToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, dtoToOAuthRefreshToken(row.OauthRefreshToken))
+	}
+
+	return tokens, nil
+}
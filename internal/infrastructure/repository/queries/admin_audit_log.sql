@@ -0,0 +1,22 @@
+-- admin_audit_log.sql
+
+
+-- name: CreateAdminAuditLog :exec
+INSERT INTO admin_audit_log (
+    actor_id,
+    action,
+    target_user_id,
+    created_at
+) VALUES (
+    $1, $2, $3, $4
+);
+
+
+-- name: ListAdminAuditLogByTargetUserID :many
+SELECT 
+    sqlc.embed(al)
+FROM 
+    admin_audit_log al
+WHERE 
+    al.target_user_id = $1
+ORDER BY al.created_at;
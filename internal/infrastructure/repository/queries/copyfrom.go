@@ -9,6 +9,40 @@ import (
 	"context"
 )
 
+// iteratorForCreateMFARecoveryCodes implements pgx.CopyFromSource.
+type iteratorForCreateMFARecoveryCodes struct {
+	rows                 []CreateMFARecoveryCodesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCreateMFARecoveryCodes) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCreateMFARecoveryCodes) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].UserID,
+		r.rows[0].CodeHash,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForCreateMFARecoveryCodes) Err() error {
+	return nil
+}
+
+func (q *Queries) CreateMFARecoveryCodes(ctx context.Context, arg []CreateMFARecoveryCodesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"mfa_recovery_codes"}, []string{"user_id", "code_hash", "created_at"}, &iteratorForCreateMFARecoveryCodes{rows: arg})
+}
+
 // iteratorForCreateOutboxMessages implements pgx.CopyFromSource.
 type iteratorForCreateOutboxMessages struct {
 	rows                 []CreateOutboxMessagesParams
@@ -0,0 +1,54 @@
+-- data_export.sql
+
+
+-- name: CreateDataExport :exec
+INSERT INTO data_exports (
+    id,
+    user_id,
+    status,
+    requested_at
+) VALUES (
+    $1, $2, $3, $4
+);
+
+
+-- name: GetLatestDataExportByUserID :one
+SELECT 
+    sqlc.embed(de)
+FROM 
+    data_exports de
+WHERE 
+    de.user_id = $1
+ORDER BY de.requested_at DESC
+LIMIT 1;
+
+
+-- name: ListPendingDataExports :many
+SELECT 
+    sqlc.embed(de)
+FROM 
+    data_exports de
+WHERE 
+    de.status = 'pending'
+ORDER BY de.requested_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+
+-- name: UpdateDataExport :exec
+UPDATE 
+    data_exports
+SET 
+    status = $2,
+    data = $3,
+    completed_at = $4,
+    expires_at = $5
+WHERE 
+    id = $1;
+
+
+-- name: DeleteDataExportsByUserID :exec
+DELETE FROM 
+    data_exports
+WHERE 
+    user_id = $1;
@@ -0,0 +1,32 @@
+-- email_change.sql
+
+
+-- name: SaveEmailChange :exec
+INSERT INTO email_changes (
+    user_id,
+    new_email,
+    token,
+    expired_at
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    new_email = EXCLUDED.new_email,
+    token = EXCLUDED.token,
+    expired_at = EXCLUDED.expired_at;
+
+
+-- name: GetEmailChangeByToken :one
+SELECT 
+    sqlc.embed(ec)
+FROM 
+    email_changes ec
+WHERE 
+    ec.token = $1;
+
+
+-- name: DeleteEmailChange :exec
+DELETE FROM 
+    email_changes
+WHERE 
+    user_id = $1;
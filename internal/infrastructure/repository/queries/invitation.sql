@@ -0,0 +1,74 @@
+-- invitation.sql
+
+
+-- name: CreateInvitation :exec
+INSERT INTO invitations (
+    id,
+    inviter_id,
+    email,
+    role,
+    token,
+    expired_at,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+
+-- name: GetInvitationByID :one
+SELECT 
+    sqlc.embed(i)
+FROM 
+    invitations i
+WHERE 
+    i.id = $1;
+
+
+-- name: GetInvitationByEmail :one
+SELECT 
+    sqlc.embed(i)
+FROM 
+    invitations i
+WHERE 
+    i.email = $1;
+
+
+-- name: GetInvitationByToken :one
+SELECT 
+    sqlc.embed(i)
+FROM 
+    invitations i
+WHERE 
+    i.token = $1;
+
+
+-- name: ListInvitations :many
+SELECT 
+    sqlc.embed(i)
+FROM 
+    invitations i
+ORDER BY i.created_at;
+
+
+-- name: ListInvitationsByInviterID :many
+SELECT 
+    sqlc.embed(i)
+FROM 
+    invitations i
+WHERE 
+    i.inviter_id = $1
+ORDER BY i.created_at;
+
+
+-- name: DeleteInvitation :execrows
+DELETE FROM 
+    invitations
+WHERE 
+    id = $1;
+
+
+-- name: DeleteInvitationsByInviterID :exec
+DELETE FROM 
+    invitations
+WHERE 
+    inviter_id = $1;
@@ -0,0 +1,39 @@
+-- mfa_recovery_code.sql
+
+
+-- name: UseMFARecoveryCode :execrows
+UPDATE 
+    mfa_recovery_codes
+SET 
+    used_at = $3
+WHERE 
+    user_id = $1
+    AND code_hash = $2
+    AND used_at IS NULL;
+
+
+-- name: CreateMFARecoveryCodes :copyfrom
+INSERT INTO mfa_recovery_codes (
+    user_id,
+    code_hash,
+    created_at
+) VALUES (
+    $1, $2, $3
+);
+
+
+-- name: CountUnusedMFARecoveryCodes :one
+SELECT 
+    COUNT(*)
+FROM 
+    mfa_recovery_codes rc
+WHERE 
+    rc.user_id = $1
+    AND rc.used_at IS NULL;
+
+
+-- name: DeleteMFARecoveryCodesByUserID :exec
+DELETE FROM 
+    mfa_recovery_codes
+WHERE 
+    user_id = $1;
@@ -10,6 +10,74 @@ import (
 	"github.com/google/uuid"
 )
 
+type AdminAuditLog struct {
+	ID           uuid.UUID
+	ActorID      uuid.UUID
+	Action       string
+	TargetUserID uuid.UUID
+	CreatedAt    time.Time
+}
+
+type DataExport struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Status      string
+	Data        []byte
+	RequestedAt time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+type EmailChange struct {
+	UserID    uuid.UUID
+	NewEmail  string
+	Token     string
+	ExpiredAt time.Time
+}
+
+type Invitation struct {
+	ID        uuid.UUID
+	InviterID uuid.UUID
+	Email     string
+	Role      *string
+	Token     string
+	ExpiredAt time.Time
+	CreatedAt time.Time
+}
+
+type MfaRecoveryCode struct {
+	UserID    uuid.UUID
+	CodeHash  string
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+type OauthClient struct {
+	ID            string
+	Name          string
+	SecretHash    *string
+	RedirectUris  []string
+	AllowedScopes []string
+	CreatedAt     time.Time
+	DeletedAt     *time.Time
+	GrantTypes    []string
+}
+
+type OauthConsent struct {
+	UserID    uuid.UUID
+	ClientID  string
+	Scopes    []string
+	GrantedAt time.Time
+}
+
+type OauthRefreshToken struct {
+	Token     string
+	ClientID  string
+	UserID    uuid.UUID
+	Scopes    []string
+	ExpiredAt time.Time
+}
+
 type Outbox struct {
 	ID        uuid.UUID
 	Key       uuid.UUID
@@ -20,6 +88,18 @@ type Outbox struct {
 	DeletedAt *time.Time
 }
 
+type Permission struct {
+	RoleID uuid.UUID
+	Name   string
+}
+
+type Referral struct {
+	RefereeID  uuid.UUID
+	ReferrerID uuid.UUID
+	Code       string
+	CreatedAt  time.Time
+}
+
 type RefreshToken struct {
 	ID        uuid.UUID
 	UserID    uuid.UUID
@@ -36,10 +116,56 @@ type RegisterToken struct {
 	DeletedAt *time.Time
 }
 
+type Role struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	CreatedAt   time.Time
+}
+
 type User struct {
-	ID           uuid.UUID
-	Email        string
-	Confirmed    bool
-	HashPassword string
-	DeletedAt    *time.Time
+	ID                  uuid.UUID
+	Email               string
+	Confirmed           bool
+	HashPassword        *string
+	DeletedAt           *time.Time
+	CreatedAt           time.Time
+	Status              string
+	StatusReason        *string
+	StatusUntil         *time.Time
+	DeletionScheduledAt *time.Time
+}
+
+type UserIdentity struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	Provider string
+	Subject  string
+	Email    string
+	LinkedAt time.Time
+}
+
+type UserRole struct {
+	UserID     uuid.UUID
+	RoleID     uuid.UUID
+	AssignedAt time.Time
+}
+
+type UserTotp struct {
+	UserID       uuid.UUID
+	Secret       []byte
+	LastUsedStep int64
+	CreatedAt    time.Time
+	ActivatedAt  *time.Time
+}
+
+type WebauthnCredential struct {
+	ID         []byte
+	UserID     uuid.UUID
+	PublicKey  []byte
+	SignCount  int64
+	Transports []string
+	Name       string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
 }
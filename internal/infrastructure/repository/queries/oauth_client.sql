@@ -0,0 +1,25 @@
+-- oauth_client.sql
+
+
+-- name: GetOAuthClientByID :one
+SELECT
+    sqlc.embed(c)
+FROM 
+    oauth_clients c
+WHERE
+    c.id = $1
+    AND c.deleted_at IS NULL;
+
+
+-- name: CreateOAuthClient :exec
+INSERT INTO oauth_clients (
+    id,
+    name,
+    secret_hash,
+    redirect_uris,
+    allowed_scopes,
+    grant_types,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
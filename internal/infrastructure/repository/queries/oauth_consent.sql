@@ -0,0 +1,42 @@
+-- oauth_consent.sql
+
+
+-- name: GetOAuthConsent :one
+SELECT
+    sqlc.embed(oc)
+FROM 
+    oauth_consents oc
+WHERE
+    oc.user_id = $1
+    AND oc.client_id = $2;
+
+
+-- name: SaveOAuthConsent :exec
+INSERT INTO oauth_consents (
+    user_id,
+    client_id,
+    scopes,
+    granted_at
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (user_id, client_id) DO UPDATE SET
+    scopes = EXCLUDED.scopes,
+    granted_at = EXCLUDED.granted_at;
+
+
+-- name: DeleteOAuthConsentsByUserID :exec
+DELETE FROM 
+    oauth_consents
+WHERE 
+    user_id = $1;
+
+
+-- name: ListOAuthConsentsByUserID :many
+SELECT
+    sqlc.embed(oc)
+FROM 
+    oauth_consents oc
+WHERE
+    oc.user_id = $1
+ORDER BY oc.granted_at;
@@ -0,0 +1,39 @@
+-- oauth_refresh_token.sql
+
+
+-- name: CreateOAuthRefreshToken :exec
+INSERT INTO oauth_refresh_tokens (
+    token,
+    client_id,
+    user_id,
+    scopes,
+    expired_at
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+
+-- name: PopOAuthRefreshToken :one
+DELETE FROM 
+    oauth_refresh_tokens
+WHERE 
+    token = $1
+RETURNING token, client_id, user_id, scopes, expired_at;
+
+
+-- name: DeleteOAuthRefreshTokensByUserID :exec
+DELETE FROM 
+    oauth_refresh_tokens
+WHERE 
+    user_id = $1;
+
+
+-- name: ListOAuthRefreshTokensByUserID :many
+SELECT 
+    sqlc.embed(ort)
+FROM 
+    oauth_refresh_tokens ort
+WHERE 
+    ort.user_id = $1
+    AND ort.expired_at > NOW()
+ORDER BY ort.expired_at;
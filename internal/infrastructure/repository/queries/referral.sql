@@ -0,0 +1,28 @@
+-- referral.sql
+
+
+-- name: CreateReferral :exec
+INSERT INTO referrals (
+    referee_id,
+    referrer_id,
+    code,
+    created_at
+) VALUES (
+    $1, $2, $3, $4
+);
+
+
+-- name: CountReferralsByReferrerID :one
+SELECT 
+    COUNT(*)
+FROM 
+    referrals
+WHERE 
+    referrer_id = $1;
+
+
+-- name: DeleteReferralsByUserID :exec
+DELETE FROM 
+    referrals
+WHERE 
+    referee_id = $1 OR referrer_id = $1;
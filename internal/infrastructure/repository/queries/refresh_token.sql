@@ -18,7 +18,7 @@ SELECT
 FROM 
     refresh_token ref_t
 WHERE
-    (sqlc.narg('user_ids')::UUID[] IS NULL OR u.id = ANY(sqlc.narg('user_ids')::UUID[]))
+    (sqlc.narg('user_ids')::UUID[] IS NULL OR ref_t.user_id = ANY(sqlc.narg('user_ids')::UUID[]))
     AND ref_t.deleted_at IS NULL
     AND ref_t.expired_at > NOW()
 LIMIT sqlc.narg('limit')
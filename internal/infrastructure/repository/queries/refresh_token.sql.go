@@ -92,7 +92,7 @@ SELECT
 FROM 
     refresh_token ref_t
 WHERE
-    ($1::UUID[] IS NULL OR u.id = ANY($1::UUID[]))
+    ($1::UUID[] IS NULL OR ref_t.user_id = ANY($1::UUID[]))
     AND ref_t.deleted_at IS NULL
     AND ref_t.expired_at > NOW()
 LIMIT $3
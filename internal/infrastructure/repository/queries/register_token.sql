@@ -18,7 +18,7 @@ SELECT
 FROM 
     register_token reg_t
 WHERE
-    (sqlc.narg('user_ids')::UUID[] IS NULL OR u.id = ANY(sqlc.narg('user_ids')::UUID[]))
+    (sqlc.narg('user_ids')::UUID[] IS NULL OR reg_t.user_id = ANY(sqlc.narg('user_ids')::UUID[]))
     AND reg_t.deleted_at IS NULL
     AND reg_t.expired_at > NOW()
 LIMIT sqlc.narg('limit')
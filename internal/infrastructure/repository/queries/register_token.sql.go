@@ -92,7 +92,7 @@ SELECT
 FROM 
     register_token reg_t
 WHERE
-    ($1::UUID[] IS NULL OR u.id = ANY($1::UUID[]))
+    ($1::UUID[] IS NULL OR reg_t.user_id = ANY($1::UUID[]))
     AND reg_t.deleted_at IS NULL
     AND reg_t.expired_at > NOW()
 LIMIT $3
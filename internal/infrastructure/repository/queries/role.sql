@@ -0,0 +1,86 @@
+-- role.sql
+
+
+-- name: GetRoleByName :one
+SELECT
+    sqlc.embed(r)
+FROM 
+    roles r
+WHERE
+    r.name = $1;
+
+
+-- name: ListRoles :many
+SELECT
+    sqlc.embed(r)
+FROM 
+    roles r
+ORDER BY r.name;
+
+
+-- name: ListRolesByUserID :many
+SELECT
+    sqlc.embed(r)
+FROM 
+    roles r
+JOIN 
+    user_roles ur ON r.id = ur.role_id
+WHERE
+    ur.user_id = $1
+ORDER BY r.name;
+
+
+-- name: ListPermissionsByRoleIDs :many
+SELECT
+    sqlc.embed(p)
+FROM 
+    permissions p
+WHERE
+    p.role_id = ANY(sqlc.arg('role_ids')::UUID[])
+ORDER BY p.name;
+
+
+-- name: CreateRole :exec
+INSERT INTO roles (
+    id,
+    name,
+    description,
+    created_at
+) VALUES (
+    $1, $2, $3, $4
+);
+
+
+-- name: CreatePermission :exec
+INSERT INTO permissions (
+    role_id,
+    name
+) VALUES (
+    $1, $2
+);
+
+
+-- name: AssignRole :exec
+INSERT INTO user_roles (
+    user_id,
+    role_id,
+    assigned_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (user_id, role_id) DO NOTHING;
+
+
+-- name: UnassignRole :execrows
+DELETE FROM 
+    user_roles
+WHERE 
+    user_id = $1
+    AND role_id = $2;
+
+
+-- name: DeleteUserRolesByUserID :exec
+DELETE FROM 
+    user_roles
+WHERE 
+    user_id = $1;
@@ -40,7 +40,12 @@ FROM
     users u
 WHERE 
     (sqlc.narg('user_ids')::UUID[] IS NULL OR u.id = ANY(sqlc.narg('user_ids')::UUID[]))
+    AND (sqlc.narg('email_prefix')::TEXT IS NULL OR u.email LIKE sqlc.narg('email_prefix')::TEXT || '%')
+    AND (sqlc.narg('confirmed')::BOOL IS NULL OR u.confirmed = sqlc.narg('confirmed')::BOOL)
+    AND (sqlc.narg('created_from')::TIMESTAMP IS NULL OR u.created_at >= sqlc.narg('created_from')::TIMESTAMP)
+    AND (sqlc.narg('created_to')::TIMESTAMP IS NULL OR u.created_at < sqlc.narg('created_to')::TIMESTAMP)
     AND u.deleted_at IS NULL
+ORDER BY u.created_at, u.id
 LIMIT sqlc.narg('limit')
 OFFSET sqlc.arg('offset');
 
@@ -49,9 +54,11 @@ OFFSET sqlc.arg('offset');
 INSERT INTO users (
     id,
     email,
-    hash_password
+    hash_password,
+    confirmed,
+    created_at
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 );
 
 
@@ -61,7 +68,11 @@ UPDATE
 SET  
     email = $2,
     hash_password = $3,
-    confirmed = $4
+    confirmed = $4,
+    status = $5,
+    status_reason = $6,
+    status_until = $7,
+    deletion_scheduled_at = $8
 WHERE 
     users.id = $1;
 
@@ -73,3 +84,28 @@ SET
     deleted_at = COALESCE(deleted_at, NOW())
 WHERE 
     id = $1;
+
+
+-- name: ListUserIDsDueForDeletion :many
+SELECT 
+    u.id
+FROM 
+    users u
+WHERE 
+    u.deletion_scheduled_at <= sqlc.arg('now')::TIMESTAMP
+    AND u.deleted_at IS NULL
+ORDER BY u.deletion_scheduled_at
+LIMIT sqlc.arg('limit');
+
+
+-- name: PurgeUser :exec
+UPDATE 
+    users
+SET 
+    email = 'deleted:' || id::TEXT,
+    hash_password = NULL,
+    status_reason = NULL,
+    deletion_scheduled_at = NULL,
+    deleted_at = COALESCE(deleted_at, NOW())
+WHERE 
+    id = $1;
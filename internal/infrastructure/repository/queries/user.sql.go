@@ -7,6 +7,7 @@ package db_queries
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -15,20 +16,30 @@ const createUser = `-- name: CreateUser :exec
 INSERT INTO users (
     id,
     email,
-    hash_password
+    hash_password,
+    confirmed,
+    created_at
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 )
 `
 
 type CreateUserParams struct {
 	ID           uuid.UUID
 	Email        string
-	HashPassword string
+	HashPassword *string
+	Confirmed    bool
+	CreatedAt    time.Time
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
-	_, err := q.db.Exec(ctx, createUser, arg.ID, arg.Email, arg.HashPassword)
+	_, err := q.db.Exec(ctx, createUser,
+		arg.ID,
+		arg.Email,
+		arg.HashPassword,
+		arg.Confirmed,
+		arg.CreatedAt,
+	)
 	return err
 }
 
@@ -48,7 +59,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 
 const getUserByEmail = `-- name: GetUserByEmail :one
 SELECT 
-    u.id, u.email, u.confirmed, u.hash_password, u.deleted_at
+    u.id, u.email, u.confirmed, u.hash_password, u.deleted_at, u.created_at, u.status, u.status_reason, u.status_until, u.deletion_scheduled_at
 FROM 
     users u
 WHERE 
@@ -69,6 +80,11 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEm
 		&i.User.Confirmed,
 		&i.User.HashPassword,
 		&i.User.DeletedAt,
+		&i.User.CreatedAt,
+		&i.User.Status,
+		&i.User.StatusReason,
+		&i.User.StatusUntil,
+		&i.User.DeletionScheduledAt,
 	)
 	return i, err
 }
@@ -77,7 +93,7 @@ const getUserByID = `-- name: GetUserByID :one
 
 
 SELECT 
-    u.id, u.email, u.confirmed, u.hash_password, u.deleted_at
+    u.id, u.email, u.confirmed, u.hash_password, u.deleted_at, u.created_at, u.status, u.status_reason, u.status_until, u.deletion_scheduled_at
 FROM 
     users u
 WHERE 
@@ -99,13 +115,18 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow
 		&i.User.Confirmed,
 		&i.User.HashPassword,
 		&i.User.DeletedAt,
+		&i.User.CreatedAt,
+		&i.User.Status,
+		&i.User.StatusReason,
+		&i.User.StatusUntil,
+		&i.User.DeletionScheduledAt,
 	)
 	return i, err
 }
 
 const getUserByRefreshToken = `-- name: GetUserByRefreshToken :one
 SELECT 
-    u.id, u.email, u.confirmed, u.hash_password, u.deleted_at
+    u.id, u.email, u.confirmed, u.hash_password, u.deleted_at, u.created_at, u.status, u.status_reason, u.status_until, u.deletion_scheduled_at
 FROM 
     users u
 JOIN 
@@ -128,26 +149,40 @@ func (q *Queries) GetUserByRefreshToken(ctx context.Context, token string) (GetU
 		&i.User.Confirmed,
 		&i.User.HashPassword,
 		&i.User.DeletedAt,
+		&i.User.CreatedAt,
+		&i.User.Status,
+		&i.User.StatusReason,
+		&i.User.StatusUntil,
+		&i.User.DeletionScheduledAt,
 	)
 	return i, err
 }
 
 const listUser = `-- name: ListUser :many
 SELECT 
-    u.id, u.email, u.confirmed, u.hash_password, u.deleted_at
+    u.id, u.email, u.confirmed, u.hash_password, u.deleted_at, u.created_at, u.status, u.status_reason, u.status_until, u.deletion_scheduled_at
 FROM 
     users u
 WHERE 
     ($1::UUID[] IS NULL OR u.id = ANY($1::UUID[]))
+    AND ($2::TEXT IS NULL OR u.email LIKE $2::TEXT || '%')
+    AND ($3::BOOL IS NULL OR u.confirmed = $3::BOOL)
+    AND ($4::TIMESTAMP IS NULL OR u.created_at >= $4::TIMESTAMP)
+    AND ($5::TIMESTAMP IS NULL OR u.created_at < $5::TIMESTAMP)
     AND u.deleted_at IS NULL
-LIMIT $3
-OFFSET $2
+ORDER BY u.created_at, u.id
+LIMIT $7
+OFFSET $6
 `
 
 type ListUserParams struct {
-	UserIds []uuid.UUID
-	Offset  int32
-	Limit   *int32
+	UserIds     []uuid.UUID
+	EmailPrefix *string
+	Confirmed   *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Offset      int32
+	Limit       *int32
 }
 
 type ListUserRow struct {
@@ -155,7 +190,15 @@ type ListUserRow struct {
 }
 
 func (q *Queries) ListUser(ctx context.Context, arg ListUserParams) ([]ListUserRow, error) {
-	rows, err := q.db.Query(ctx, listUser, arg.UserIds, arg.Offset, arg.Limit)
+	rows, err := q.db.Query(ctx, listUser,
+		arg.UserIds,
+		arg.EmailPrefix,
+		arg.Confirmed,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -169,6 +212,11 @@ func (q *Queries) ListUser(ctx context.Context, arg ListUserParams) ([]ListUserR
 			&i.User.Confirmed,
 			&i.User.HashPassword,
 			&i.User.DeletedAt,
+			&i.User.CreatedAt,
+			&i.User.Status,
+			&i.User.StatusReason,
+			&i.User.StatusUntil,
+			&i.User.DeletionScheduledAt,
 		); err != nil {
 			return nil, err
 		}
@@ -180,22 +228,85 @@ func (q *Queries) ListUser(ctx context.Context, arg ListUserParams) ([]ListUserR
 	return items, nil
 }
 
+const listUserIDsDueForDeletion = `-- name: ListUserIDsDueForDeletion :many
+SELECT 
+    u.id
+FROM 
+    users u
+WHERE 
+    u.deletion_scheduled_at <= $1::TIMESTAMP
+    AND u.deleted_at IS NULL
+ORDER BY u.deletion_scheduled_at
+LIMIT $2
+`
+
+type ListUserIDsDueForDeletionParams struct {
+	Now   time.Time
+	Limit int32
+}
+
+func (q *Queries) ListUserIDsDueForDeletion(ctx context.Context, arg ListUserIDsDueForDeletionParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listUserIDsDueForDeletion, arg.Now, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeUser = `-- name: PurgeUser :exec
+UPDATE 
+    users
+SET 
+    email = 'deleted:' || id::TEXT,
+    hash_password = NULL,
+    status_reason = NULL,
+    deletion_scheduled_at = NULL,
+    deleted_at = COALESCE(deleted_at, NOW())
+WHERE 
+    id = $1
+`
+
+func (q *Queries) PurgeUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, purgeUser, id)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :exec
 UPDATE 
     users
 SET  
     email = $2,
     hash_password = $3,
-    confirmed = $4
+    confirmed = $4,
+    status = $5,
+    status_reason = $6,
+    status_until = $7,
+    deletion_scheduled_at = $8
 WHERE 
     users.id = $1
 `
 
 type UpdateUserParams struct {
-	ID           uuid.UUID
-	Email        string
-	HashPassword string
-	Confirmed    bool
+	ID                  uuid.UUID
+	Email               string
+	HashPassword        *string
+	Confirmed           bool
+	Status              string
+	StatusReason        *string
+	StatusUntil         *time.Time
+	DeletionScheduledAt *time.Time
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
@@ -204,6 +315,10 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 		arg.Email,
 		arg.HashPassword,
 		arg.Confirmed,
+		arg.Status,
+		arg.StatusReason,
+		arg.StatusUntil,
+		arg.DeletionScheduledAt,
 	)
 	return err
 }
@@ -0,0 +1,48 @@
+-- user_identity.sql
+
+
+-- name: GetUserIdentity :one
+SELECT
+    sqlc.embed(ui)
+FROM 
+    user_identities ui
+WHERE
+    ui.provider = $1
+    AND ui.subject = $2;
+
+
+-- name: ListUserIdentitiesByUserID :many
+SELECT
+    sqlc.embed(ui)
+FROM 
+    user_identities ui
+WHERE
+    ui.user_id = $1
+ORDER BY ui.linked_at;
+
+
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (
+    user_id,
+    provider,
+    subject,
+    email,
+    linked_at
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+
+-- name: DeleteUserIdentity :execrows
+DELETE FROM 
+    user_identities
+WHERE 
+    user_id = $1
+    AND provider = $2;
+
+
+-- name: DeleteUserIdentitiesByUserID :exec
+DELETE FROM 
+    user_identities
+WHERE 
+    user_id = $1;
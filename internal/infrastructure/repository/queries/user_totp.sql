@@ -0,0 +1,34 @@
+-- user_totp.sql
+
+
+-- name: SaveUserTOTP :exec
+INSERT INTO user_totp (
+    user_id,
+    secret,
+    last_used_step,
+    created_at,
+    activated_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    secret = EXCLUDED.secret,
+    last_used_step = EXCLUDED.last_used_step,
+    created_at = EXCLUDED.created_at,
+    activated_at = EXCLUDED.activated_at;
+
+
+-- name: GetUserTOTPByUserID :one
+SELECT 
+    sqlc.embed(ut)
+FROM 
+    user_totp ut
+WHERE 
+    ut.user_id = $1;
+
+
+-- name: DeleteUserTOTPByUserID :exec
+DELETE FROM 
+    user_totp
+WHERE 
+    user_id = $1;
@@ -0,0 +1,59 @@
+-- webauthn_credential.sql
+
+
+-- name: CreateWebAuthnCredential :exec
+INSERT INTO webauthn_credentials (
+    id,
+    user_id,
+    public_key,
+    sign_count,
+    transports,
+    name,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+
+-- name: GetWebAuthnCredentialByID :one
+SELECT 
+    sqlc.embed(wc)
+FROM 
+    webauthn_credentials wc
+WHERE 
+    wc.id = $1;
+
+
+-- name: ListWebAuthnCredentialsByUserID :many
+SELECT 
+    sqlc.embed(wc)
+FROM 
+    webauthn_credentials wc
+WHERE 
+    wc.user_id = $1
+ORDER BY wc.created_at;
+
+
+-- name: UpdateWebAuthnCredentialUsage :exec
+UPDATE 
+    webauthn_credentials
+SET 
+    sign_count = $2,
+    last_used_at = $3
+WHERE 
+    id = $1;
+
+
+-- name: DeleteWebAuthnCredential :execrows
+DELETE FROM 
+    webauthn_credentials
+WHERE 
+    id = $1
+    AND user_id = $2;
+
+
+-- name: DeleteWebAuthnCredentialsByUserID :exec
+DELETE FROM 
+    webauthn_credentials
+WHERE 
+    user_id = $1;
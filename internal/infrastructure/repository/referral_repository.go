@@ -0,0 +1,69 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ReferralRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewReferralRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *ReferralRepository {
+	return &ReferralRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *ReferralRepository) Create(ctx context.Context, referral *entities.Referral) error {
+	ctx, span := s.tracer.Start(ctx, "ReferralRepository.Create")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.CreateReferralParams{
+		RefereeID:  referral.RefereeID(),
+		ReferrerID: referral.ReferrerID(),
+		Code:       referral.Code(),
+		CreatedAt:  referral.CreatedAt(),
+	}
+
+	if err := querier.CreateReferral(ctx, args); err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				return errors.Wrapf(repo.ErrDuplicate, "user with id = %s is already referred", referral.RefereeID())
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *ReferralRepository) CountByReferrerID(ctx context.Context, referrerID uuid.UUID) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "ReferralRepository.CountByReferrerID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	return querier.CountReferralsByReferrerID(ctx, referrerID)
+}
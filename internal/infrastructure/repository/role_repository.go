@@ -0,0 +1,214 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type RoleRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewRoleRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *RoleRepository {
+	return &RoleRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *RoleRepository) Create(ctx context.Context, role *entities.Role) error {
+	ctx, span := s.tracer.Start(ctx, "RoleRepository.Create")
+	defer span.End()
+
+	args := db_queries.CreateRoleParams{
+		ID:          role.ID(),
+		Name:        role.Name(),
+		Description: role.Description(),
+		CreatedAt:   role.CreatedAt(),
+	}
+
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		db := s.txManager.TxOrDB(ctx)
+		querier := db_queries.New(db)
+
+		if err := querier.CreateRole(ctx, args); err != nil {
+			var pgErr *pgconn.PgError
+
+			if errors.As(err, &pgErr) {
+				if pgErr.Code == pgerrcode.UniqueViolation {
+					return errors.Wrapf(repo.ErrDuplicate, "role with name = %s already exists", role.Name())
+				}
+			}
+
+			return err
+		}
+
+		for _, permission := range role.Permissions() {
+			permissionArgs := db_queries.CreatePermissionParams{
+				RoleID: role.ID(),
+				Name:   permission,
+			}
+
+			if err := querier.CreatePermission(ctx, permissionArgs); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *RoleRepository) GetByName(ctx context.Context, name string) (*entities.Role, error) {
+	ctx, span := s.tracer.Start(ctx, "RoleRepository.GetByName")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	row, err := querier.GetRoleByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repo.ErrObjectNotFound, "role with name = %s not exists", name)
+		}
+
+		return nil, err
+	}
+
+	roles, err := s.withPermissions(ctx, querier, []db_queries.Role{row.Role})
+	if err != nil {
+		return nil, err
+	}
+
+	return roles[0], nil
+}
+
+func (s *RoleRepository) List(ctx context.Context) ([]*entities.Role, error) {
+	ctx, span := s.tracer.Start(ctx, "RoleRepository.List")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]db_queries.Role, 0, len(rows))
+	for _, row := range rows {
+		roles = append(roles, row.Role)
+	}
+
+	return s.withPermissions(ctx, querier, roles)
+}
+
+func (s *RoleRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Role, error) {
+	ctx, span := s.tracer.Start(ctx, "RoleRepository.ListByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.ListRolesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]db_queries.Role, 0, len(rows))
+	for _, row := range rows {
+		roles = append(roles, row.Role)
+	}
+
+	return s.withPermissions(ctx, querier, roles)
+}
+
+func (s *RoleRepository) Assign(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "RoleRepository.Assign")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.AssignRoleParams{
+		UserID:     userID,
+		RoleID:     roleID,
+		AssignedAt: time.Now(),
+	}
+
+	if err := querier.AssignRole(ctx, args); err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.ForeignKeyViolation {
+				return errors.Wrapf(repo.ErrObjectNotFound, "user with id = %s not exists", userID.String())
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *RoleRepository) Unassign(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "RoleRepository.Unassign")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.UnassignRoleParams{
+		UserID: userID,
+		RoleID: roleID,
+	}
+
+	rows, err := querier.UnassignRole(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.Wrapf(repo.ErrObjectNotFound, "role with id = %s is not assigned to user with id = %s", roleID.String(), userID.String())
+	}
+
+	return nil
+}
+
+func (s *RoleRepository) withPermissions(ctx context.Context, querier *db_queries.Queries, roles []db_queries.Role) ([]*entities.Role, error) {
+	roleIDs := make([]uuid.UUID, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+	}
+
+	rows, err := querier.ListPermissionsByRoleIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[uuid.UUID][]string, len(roles))
+	for _, row := range rows {
+		permissions[row.Permission.RoleID] = append(permissions[row.Permission.RoleID], row.Permission.Name)
+	}
+
+	result := make([]*entities.Role, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, dtoToRole(role, permissions[role.ID]))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type UserIdentityRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewUserIdentityRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *UserIdentityRepository {
+	return &UserIdentityRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *UserIdentityRepository) Create(ctx context.Context, identity *entities.UserIdentity) error {
+	ctx, span := s.tracer.Start(ctx, "UserIdentityRepository.Create")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.CreateUserIdentityParams{
+		UserID:   identity.UserID(),
+		Provider: identity.Provider(),
+		Subject:  identity.Subject(),
+		Email:    identity.Email(),
+		LinkedAt: identity.LinkedAt(),
+	}
+
+	if err := querier.CreateUserIdentity(ctx, args); err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				return errors.Wrapf(repo.ErrDuplicate, "%s identity already linked", identity.Provider())
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *UserIdentityRepository) Delete(ctx context.Context, userID uuid.UUID, provider string) error {
+	ctx, span := s.tracer.Start(ctx, "UserIdentityRepository.Delete")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.DeleteUserIdentityParams{
+		UserID:   userID,
+		Provider: provider,
+	}
+
+	rows, err := querier.DeleteUserIdentity(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.Wrapf(repo.ErrObjectNotFound, "%s identity of user with id = %s not exists", provider, userID.String())
+	}
+
+	return nil
+}
+
+func (s *UserIdentityRepository) GetByProviderSubject(ctx context.Context, provider string, subject string) (*entities.UserIdentity, error) {
+	ctx, span := s.tracer.Start(ctx, "UserIdentityRepository.GetByProviderSubject")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.GetUserIdentityParams{
+		Provider: provider,
+		Subject:  subject,
+	}
+
+	row, err := querier.GetUserIdentity(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repo.ErrObjectNotFound, "%s identity with subject = %s not exists", provider, subject)
+		}
+
+		return nil, err
+	}
+
+	return dtoToUserIdentity(row.UserIdentity), nil
+}
+
+func (s *UserIdentityRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]entities.UserIdentity, error) {
+	ctx, span := s.tracer.Start(ctx, "UserIdentityRepository.ListByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.ListUserIdentitiesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	identities := make([]entities.UserIdentity, 0, len(rows))
+	for _, row := range rows {
+		identities = append(identities, *dtoToUserIdentity(row.UserIdentity))
+	}
+
+	return identities, nil
+}
@@ -22,13 +22,111 @@ func dtoToRegisterToken(rt *db_queries.RegisterToken) *vobjects.RegisterToken {
 	return vobjects.NewExistingRegisterToken(rt.Token, rt.ExpiredAt)
 }
 
+func dtoToPassword(hashPassword *string) *vobjects.Password {
+	if hashPassword == nil {
+		return nil
+	}
+
+	password := vobjects.NewExistingPassword(*hashPassword)
+
+	return &password
+}
+
+func passwordToDTO(password *vobjects.Password) *string {
+	if password == nil {
+		return nil
+	}
+
+	hash := password.Hash()
+
+	return &hash
+}
+
+func dtoToString(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func stringToDTO(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
 func dtoToUser(user db_queries.User, refreshToken *db_queries.RefreshToken, registerToken *db_queries.RegisterToken) *entities.User {
 	return entities.NewExistingUser(
 		user.ID,
 		user.Email,
-		vobjects.NewExistingPassword(user.HashPassword),
+		dtoToPassword(user.HashPassword),
 		user.Confirmed,
+		entities.UserStatus(user.Status),
+		dtoToString(user.StatusReason),
+		user.StatusUntil,
+		user.CreatedAt,
+		user.DeletionScheduledAt,
 		dtoToRefreshToken(refreshToken),
 		dtoToRegisterToken(registerToken),
 	)
 }
+
+func dtoToUserIdentity(identity db_queries.UserIdentity) *entities.UserIdentity {
+	return entities.NewExistingUserIdentity(
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.LinkedAt,
+	)
+}
+
+func dtoToRole(role db_queries.Role, permissions []string) *entities.Role {
+	return entities.NewExistingRole(
+		role.ID,
+		role.Name,
+		role.Description,
+		permissions,
+		role.CreatedAt,
+	)
+}
+
+func dtoToDataExport(export db_queries.DataExport) *entities.DataExport {
+	return entities.NewExistingDataExport(
+		export.ID,
+		export.UserID,
+		export.Status,
+		export.Data,
+		export.RequestedAt,
+		export.CompletedAt,
+		export.ExpiresAt,
+	)
+}
+
+func dtoToWebAuthnCredential(credential db_queries.WebauthnCredential) *entities.WebAuthnCredential {
+	return entities.NewExistingWebAuthnCredential(
+		credential.ID,
+		credential.UserID,
+		credential.PublicKey,
+		uint32(credential.SignCount),
+		credential.Transports,
+		credential.Name,
+		credential.CreatedAt,
+		credential.LastUsedAt,
+	)
+}
+
+func dtoToInvitation(invitation db_queries.Invitation) *entities.Invitation {
+	return entities.NewExistingInvitation(
+		invitation.ID,
+		invitation.InviterID,
+		invitation.Email,
+		dtoToString(invitation.Role),
+		invitation.Token,
+		invitation.ExpiredAt,
+		invitation.CreatedAt,
+	)
+}
@@ -3,6 +3,8 @@ package pgrepo
 import (
 	"context"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
@@ -16,6 +18,10 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// likePatternReplacer escapes LIKE wildcards so that user input matches
+// literally.
+var likePatternReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 type UserRepository struct {
 	txManager trm.TransactionManager
 	log       *slog.Logger
@@ -37,7 +43,9 @@ func (s *UserRepository) Create(ctx context.Context, user *entities.User) error
 	args := db_queries.CreateUserParams{
 		ID:           user.ID(),
 		Email:        user.Email(),
-		HashPassword: user.Password().Hash(),
+		HashPassword: passwordToDTO(user.Password()),
+		Confirmed:    user.Confirmed(),
+		CreatedAt:    user.CreatedAt(),
 	}
 
 	if err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
@@ -129,10 +137,14 @@ func (s *UserRepository) Update(ctx context.Context, user *entities.User) error
 		}
 
 		userArgs := db_queries.UpdateUserParams{
-			ID:           user.ID(),
-			Email:        user.Email(),
-			HashPassword: user.Password().Hash(),
-			Confirmed:    user.Confirmed(),
+			ID:                  user.ID(),
+			Email:               user.Email(),
+			HashPassword:        passwordToDTO(user.Password()),
+			Confirmed:           user.Confirmed(),
+			Status:              string(user.Status()),
+			StatusReason:        stringToDTO(user.StatusReason()),
+			StatusUntil:         user.StatusUntil(),
+			DeletionScheduledAt: user.DeletionScheduledAt(),
 		}
 
 		if err := querier.UpdateUser(ctx, userArgs); err != nil {
@@ -140,6 +152,14 @@ func (s *UserRepository) Update(ctx context.Context, user *entities.User) error
 				return errors.Wrapf(repo.ErrObjectNotFound, "user with email = %s not exists", user.Email())
 			}
 
+			var pgErr *pgconn.PgError
+
+			if errors.As(err, &pgErr) {
+				if pgErr.Code == pgerrcode.UniqueViolation {
+					return errors.Wrapf(repo.ErrDuplicate, "user with email = %s already exists", user.Email())
+				}
+			}
+
 			return err
 		}
 
@@ -356,6 +376,14 @@ func (s *UserRepository) List(ctx context.Context, filters *repo.UserFilters, pa
 
 		if filters != nil {
 			userArgs.UserIds = filters.UserIDs
+			userArgs.Confirmed = filters.Confirmed
+			userArgs.CreatedFrom = filters.CreatedFrom
+			userArgs.CreatedTo = filters.CreatedTo
+
+			if filters.EmailPrefix != nil {
+				emailPrefix := likePatternReplacer.Replace(*filters.EmailPrefix)
+				userArgs.EmailPrefix = &emailPrefix
+			}
 		}
 
 		if pagination != nil {
@@ -370,8 +398,13 @@ func (s *UserRepository) List(ctx context.Context, filters *repo.UserFilters, pa
 			return err
 		}
 
+		userIDs := make([]uuid.UUID, 0, len(userRows))
+		for _, userRow := range userRows {
+			userIDs = append(userIDs, userRow.User.ID)
+		}
+
 		refreshTokenArgs := db_queries.ListRefreshTokenParams{
-			UserIds: userArgs.UserIds,
+			UserIds: userIDs,
 		}
 
 		refreshTokenRows, err = querier.ListRefreshToken(ctx, refreshTokenArgs)
@@ -380,7 +413,7 @@ func (s *UserRepository) List(ctx context.Context, filters *repo.UserFilters, pa
 		}
 
 		registerTokenArgs := db_queries.ListRegisterTokenParams{
-			UserIds: userArgs.UserIds,
+			UserIds: userIDs,
 		}
 
 		registerTokenRows, err = querier.ListRegisterToken(ctx, registerTokenArgs)
@@ -393,47 +426,77 @@ func (s *UserRepository) List(ctx context.Context, filters *repo.UserFilters, pa
 		return nil, err
 	}
 
-	userMap := make(
-		map[uuid.UUID]struct {
-			user          db_queries.User
-			refreshToken  *db_queries.RefreshToken
-			registerToken *db_queries.RegisterToken
-		},
-		len(userRows),
-	)
+	refreshTokens := make(map[uuid.UUID]*db_queries.RefreshToken, len(refreshTokenRows))
+	for _, refreshTokenRow := range refreshTokenRows {
+		refreshTokens[refreshTokenRow.RefreshToken.UserID] = &refreshTokenRow.RefreshToken
+	}
 
+	registerTokens := make(map[uuid.UUID]*db_queries.RegisterToken, len(registerTokenRows))
+	for _, registerTokenRow := range registerTokenRows {
+		registerTokens[registerTokenRow.RegisterToken.UserID] = &registerTokenRow.RegisterToken
+	}
+
+	userList := make([]entities.User, 0, len(userRows))
 	for _, userRow := range userRows {
 		userID := userRow.User.ID
 
-		userDTO := userMap[userID]
-		userDTO.user = userRow.User
-
-		userMap[userID] = userDTO
+		user := dtoToUser(userRow.User, refreshTokens[userID], registerTokens[userID])
+		userList = append(userList, *user)
 	}
 
-	for _, refreshTokenRow := range refreshTokenRows {
-		userID := refreshTokenRow.RefreshToken.UserID
+	return userList, nil
+}
+
+// ListDueForDeletion returns ids of users whose deletion grace period is over.
+func (s *UserRepository) ListDueForDeletion(ctx context.Context, now time.Time, limit int32) ([]uuid.UUID, error) {
+	ctx, span := s.tracer.Start(ctx, "UserRepository.ListDueForDeletion")
+	defer span.End()
 
-		userDTO := userMap[userID]
-		userDTO.refreshToken = &refreshTokenRow.RefreshToken
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
 
-		userMap[userID] = userDTO
+	args := db_queries.ListUserIDsDueForDeletionParams{
+		Now:   now,
+		Limit: limit,
 	}
 
-	for _, registerTokenRow := range registerTokenRows {
-		userID := registerTokenRow.RegisterToken.UserID
+	return querier.ListUserIDsDueForDeletion(ctx, args)
+}
 
-		userDTO := userMap[userID]
-		userDTO.registerToken = &registerTokenRow.RegisterToken
+// Purge anonymizes and soft-deletes the user and deletes sessions, identities,
+// roles, consents, data exports, email changes, MFA factors, referrals and
+// invitations linked to it.
+func (s *UserRepository) Purge(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "UserRepository.Purge")
+	defer span.End()
 
-		userMap[userID] = userDTO
-	}
+	return s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		db := s.txManager.TxOrDB(ctx)
+		querier := db_queries.New(db)
 
-	userList := make([]entities.User, 0, len(userMap))
-	for _, userDTO := range userMap {
-		user := dtoToUser(userDTO.user, userDTO.refreshToken, userDTO.registerToken)
-		userList = append(userList, *user)
-	}
+		purges := []func(ctx context.Context, userID uuid.UUID) error{
+			querier.DeleteRefreshTokenByUserID,
+			querier.DeleteRegisterTokenByUserID,
+			querier.DeleteOAuthRefreshTokensByUserID,
+			querier.DeleteOAuthConsentsByUserID,
+			querier.DeleteDataExportsByUserID,
+			querier.DeleteEmailChange,
+			querier.DeleteUserTOTPByUserID,
+			querier.DeleteMFARecoveryCodesByUserID,
+			querier.DeleteWebAuthnCredentialsByUserID,
+			querier.DeleteReferralsByUserID,
+			querier.DeleteInvitationsByInviterID,
+			querier.DeleteUserIdentitiesByUserID,
+			querier.DeleteUserRolesByUserID,
+			querier.PurgeUser,
+		}
 
-	return userList, nil
+		for _, purge := range purges {
+			if err := purge(ctx, userID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
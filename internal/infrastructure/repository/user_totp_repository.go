@@ -0,0 +1,83 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type UserTOTPRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewUserTOTPRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *UserTOTPRepository {
+	return &UserTOTPRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+// Save replaces an authenticator of the user, if any.
+func (s *UserTOTPRepository) Save(ctx context.Context, totp *entities.UserTOTP) error {
+	ctx, span := s.tracer.Start(ctx, "UserTOTPRepository.Save")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.SaveUserTOTPParams{
+		UserID:       totp.UserID(),
+		Secret:       totp.EncryptedSecret(),
+		LastUsedStep: totp.LastUsedStep(),
+		CreatedAt:    totp.CreatedAt(),
+		ActivatedAt:  totp.ActivatedAt(),
+	}
+
+	return querier.SaveUserTOTP(ctx, args)
+}
+
+func (s *UserTOTPRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.UserTOTP, error) {
+	ctx, span := s.tracer.Start(ctx, "UserTOTPRepository.GetByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	row, err := querier.GetUserTOTPByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repo.ErrObjectNotFound, "totp of user with id = %s not exists", userID)
+		}
+
+		return nil, err
+	}
+
+	return entities.NewExistingUserTOTP(
+		row.UserTotp.UserID,
+		row.UserTotp.Secret,
+		row.UserTotp.LastUsedStep,
+		row.UserTotp.CreatedAt,
+		row.UserTotp.ActivatedAt,
+	), nil
+}
+
+func (s *UserTOTPRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "UserTOTPRepository.Delete")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	return querier.DeleteUserTOTPByUserID(ctx, userID)
+}
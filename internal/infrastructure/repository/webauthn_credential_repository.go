@@ -0,0 +1,146 @@
+package pgrepo
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	db_queries "github.com/rozhnof/auth-service/internal/infrastructure/repository/queries"
+	trm "github.com/rozhnof/auth-service/pkg/transaction_manager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type WebAuthnCredentialRepository struct {
+	txManager trm.TransactionManager
+	log       *slog.Logger
+	tracer    trace.Tracer
+}
+
+func NewWebAuthnCredentialRepository(txManager trm.TransactionManager, log *slog.Logger, tracer trace.Tracer) *WebAuthnCredentialRepository {
+	return &WebAuthnCredentialRepository{
+		txManager: txManager,
+		log:       log,
+		tracer:    tracer,
+	}
+}
+
+func (s *WebAuthnCredentialRepository) Create(ctx context.Context, credential *entities.WebAuthnCredential) error {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnCredentialRepository.Create")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.CreateWebAuthnCredentialParams{
+		ID:         credential.ID(),
+		UserID:     credential.UserID(),
+		PublicKey:  credential.PublicKey(),
+		SignCount:  int64(credential.SignCount()),
+		Transports: credential.Transports(),
+		Name:       credential.Name(),
+		CreatedAt:  credential.CreatedAt(),
+	}
+
+	if args.Transports == nil {
+		args.Transports = []string{}
+	}
+
+	if err := querier.CreateWebAuthnCredential(ctx, args); err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				return errors.Wrap(repo.ErrDuplicate, "webauthn credential already registered")
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *WebAuthnCredentialRepository) GetByID(ctx context.Context, id []byte) (*entities.WebAuthnCredential, error) {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnCredentialRepository.GetByID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	row, err := querier.GetWebAuthnCredentialByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrap(repo.ErrObjectNotFound, "webauthn credential not exists")
+		}
+
+		return nil, err
+	}
+
+	return dtoToWebAuthnCredential(row.WebauthnCredential), nil
+}
+
+func (s *WebAuthnCredentialRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.WebAuthnCredential, error) {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnCredentialRepository.ListByUserID")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	rows, err := querier.ListWebAuthnCredentialsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]*entities.WebAuthnCredential, 0, len(rows))
+	for _, row := range rows {
+		credentials = append(credentials, dtoToWebAuthnCredential(row.WebauthnCredential))
+	}
+
+	return credentials, nil
+}
+
+func (s *WebAuthnCredentialRepository) UpdateUsage(ctx context.Context, credential *entities.WebAuthnCredential) error {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnCredentialRepository.UpdateUsage")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.UpdateWebAuthnCredentialUsageParams{
+		ID:         credential.ID(),
+		SignCount:  int64(credential.SignCount()),
+		LastUsedAt: credential.LastUsedAt(),
+	}
+
+	return querier.UpdateWebAuthnCredentialUsage(ctx, args)
+}
+
+func (s *WebAuthnCredentialRepository) Delete(ctx context.Context, userID uuid.UUID, id []byte) error {
+	ctx, span := s.tracer.Start(ctx, "WebAuthnCredentialRepository.Delete")
+	defer span.End()
+
+	db := s.txManager.TxOrDB(ctx)
+	querier := db_queries.New(db)
+
+	args := db_queries.DeleteWebAuthnCredentialParams{
+		ID:     id,
+		UserID: userID,
+	}
+
+	rows, err := querier.DeleteWebAuthnCredential(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.Wrap(repo.ErrObjectNotFound, "webauthn credential not exists")
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+var errCiphertextTooShort = errors.New("ciphertext too short")
+
+// AESCipher encrypts secrets with AES-256-GCM. The nonce is prepended to the
+// ciphertext.
+type AESCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESCipher derives the AES key from the given secret with SHA-256, so a
+// secret of any length can be used.
+func NewAESCipher(key domain.Secret) (*AESCipher, error) {
+	if len(key.Get()) == 0 {
+		return nil, errors.New("encryption key is empty")
+	}
+
+	hash := sha256.Sum256(key.Get())
+
+	block, err := aes.NewCipher(hash[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AESCipher{aead: aead}, nil
+}
+
+func (c *AESCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *AESCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, errCiphertextTooShort
+	}
+
+	return c.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+}
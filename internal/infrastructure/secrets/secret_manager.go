@@ -2,14 +2,17 @@ package secrets
 
 import (
 	"os"
+	"strings"
 
 	"github.com/rozhnof/auth-service/internal/domain"
 )
 
 const (
-	envSecretKey          = "SECRET_KEY"
-	envGoogleClientID     = "GOOGLE_CLIENT_ID"
-	envGoogleClientSecret = "GOOGLE_CLIENT_SECRET"
+	envSecretKey     = "SECRET_KEY"
+	envEncryptionKey = "ENCRYPTION_KEY"
+
+	envOAuthClientIDSuffix     = "_CLIENT_ID"
+	envOAuthClientSecretSuffix = "_CLIENT_SECRET"
 )
 
 type EnvSecretManager struct{}
@@ -22,10 +25,26 @@ func (m EnvSecretManager) SecretKey() domain.Secret {
 	return domain.Secret(os.Getenv(envSecretKey))
 }
 
-func (m EnvSecretManager) GoogleClientID() domain.Secret {
-	return domain.Secret(os.Getenv(envGoogleClientID))
+// EncryptionKey reads ENCRYPTION_KEY, the key of secrets stored in the
+// database. It falls back to SECRET_KEY when not set.
+func (m EnvSecretManager) EncryptionKey() domain.Secret {
+	if key := os.Getenv(envEncryptionKey); key != "" {
+		return domain.Secret(key)
+	}
+
+	return m.SecretKey()
+}
+
+// OAuthClientID reads <PROVIDER>_CLIENT_ID, e.g. GOOGLE_CLIENT_ID.
+func (m EnvSecretManager) OAuthClientID(provider string) domain.Secret {
+	return domain.Secret(os.Getenv(oauthEnvPrefix(provider) + envOAuthClientIDSuffix))
+}
+
+// OAuthClientSecret reads <PROVIDER>_CLIENT_SECRET, e.g. GOOGLE_CLIENT_SECRET.
+func (m EnvSecretManager) OAuthClientSecret(provider string) domain.Secret {
+	return domain.Secret(os.Getenv(oauthEnvPrefix(provider) + envOAuthClientSecretSuffix))
 }
 
-func (m EnvSecretManager) GoogleClientSecret() domain.Secret {
-	return domain.Secret(os.Getenv(envGoogleClientSecret))
+func oauthEnvPrefix(provider string) string {
+	return strings.ToUpper(strings.ReplaceAll(provider, "-", "_"))
 }
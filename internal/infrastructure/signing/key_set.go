@@ -0,0 +1,134 @@
+package signing
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rozhnof/auth-service/internal/application/services"
+)
+
+const (
+	generatedKeyBits = 2048
+)
+
+type signingKey struct {
+	id  string
+	key *rsa.PrivateKey
+}
+
+// KeySet signs tokens with the first key and publishes all keys, so a new key
+// can be rolled out while tokens signed by the previous one are still valid.
+type KeySet struct {
+	keys []signingKey
+}
+
+// NewKeySet loads RSA private keys from PEM files.
+func NewKeySet(paths []string) (*KeySet, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no signing keys")
+	}
+
+	keys := make([]signingKey, 0, len(paths))
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed read signing key %s: %w", path, err)
+		}
+
+		key, err := parsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed parse signing key %s: %w", path, err)
+		}
+
+		keys = append(keys, newSigningKey(key))
+	}
+
+	return &KeySet{
+		keys: keys,
+	}, nil
+}
+
+// NewGeneratedKeySet generates a key, which is lost on restart, so it fits
+// only development setups.
+func NewGeneratedKeySet() (*KeySet, error) {
+	key, err := rsa.GenerateKey(rand.Reader, generatedKeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeySet{
+		keys: []signingKey{newSigningKey(key)},
+	}, nil
+}
+
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	current := s.keys[0]
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = current.id
+
+	return token.SignedString(current.key)
+}
+
+func (s *KeySet) PublicKeys() []services.PublicKey {
+	publicKeys := make([]services.PublicKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		publicKeys = append(publicKeys, services.PublicKey{
+			KeyID: key.id,
+			Key:   &key.key.PublicKey,
+		})
+	}
+
+	return publicKeys
+}
+
+func newSigningKey(key *rsa.PrivateKey) signingKey {
+	return signingKey{
+		id:  thumbprint(&key.PublicKey),
+		key: key,
+	}
+}
+
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no pem block")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an rsa key")
+	}
+
+	return rsaKey, nil
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint used as the key id.
+func thumbprint(key *rsa.PublicKey) string {
+	var (
+		n = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	)
+
+	hash := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
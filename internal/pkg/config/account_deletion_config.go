@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type AccountDeletion struct {
+	GracePeriod    time.Duration `yaml:"grace_period"     env-default:"720h"`
+	PurgeInterval  time.Duration `yaml:"purge_interval"   env-default:"1h"`
+	PurgeBatchSize int32         `yaml:"purge_batch_size" env-default:"100"`
+}
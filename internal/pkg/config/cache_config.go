@@ -0,0 +1,7 @@
+package config
+
+import "time"
+
+type Cache struct {
+	ReferralCodeTTL time.Duration `yaml:"referral_code_ttl" env-default:"24h"`
+}
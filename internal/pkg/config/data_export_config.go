@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type DataExport struct {
+	TTL               time.Duration `yaml:"ttl"                 env-default:"168h"`
+	GenerateInterval  time.Duration `yaml:"generate_interval"   env-default:"30s"`
+	GenerateBatchSize int32         `yaml:"generate_batch_size" env-default:"10"`
+}
@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type EmailCode struct {
+	Length      int           `yaml:"length"       env-default:"6"`
+	TTL         time.Duration `yaml:"ttl"          env-default:"10m"`
+	MaxAttempts int           `yaml:"max_attempts" env-default:"5"`
+}
@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type EmailPolicy struct {
+	AllowListPath  string        `yaml:"allow_list"`
+	DenyListPath   string        `yaml:"deny_list"`
+	AllowedOnly    bool          `yaml:"allowed_only"`
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
@@ -0,0 +1,8 @@
+package config
+
+import "time"
+
+type EmailThrottle struct {
+	Limit  int64         `yaml:"limit"  env-default:"5"`
+	Window time.Duration `yaml:"window" env-default:"1h"`
+}
@@ -0,0 +1,7 @@
+package config
+
+import "time"
+
+type MagicLink struct {
+	TTL time.Duration `yaml:"ttl" env-default:"15m"`
+}
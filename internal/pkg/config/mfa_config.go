@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type MFA struct {
+	Issuer            string        `yaml:"issuer"        env-default:"auth-service"`
+	ChallengeTTL      time.Duration `yaml:"challenge_ttl" env-default:"5m"`
+	MaxAttempts       int           `yaml:"max_attempts"        env-default:"5"`
+	RecoveryCodeCount int           `yaml:"recovery_code_count" env-default:"10"`
+}
@@ -1,10 +1,24 @@
 package config
 
-type Google struct {
-	RedirectURL string   `yaml:"redirect" env-required:"true"`
-	Scopes      []string `yaml:"scopes"   env-required:"true"`
+import "time"
+
+type OAuthProvider struct {
+	Name        string   `yaml:"name"      env-required:"true"`
+	Type        string   `yaml:"type"      env-required:"true"`
+	Issuer      string   `yaml:"issuer"`
+	RedirectURL string   `yaml:"redirect"  env-required:"true"`
+	Scopes      []string `yaml:"scopes"`
+	AuthURL     string   `yaml:"auth_url"`
+	TokenURL    string   `yaml:"token_url"`
+	APIURL      string   `yaml:"api_url"`
 }
 
 type OAuth struct {
-	Google Google `yaml:"google" env-required:"true"`
+	Providers             []OAuthProvider `yaml:"providers"`
+	StateTTL              time.Duration   `yaml:"state_ttl"                env-default:"10m"`
+	RedirectAllowlist     []string        `yaml:"redirect_allowlist"`
+	AutoLinkVerifiedEmail bool            `yaml:"auto_link_verified_email"`
+	CompletionMode        string          `yaml:"completion_mode"          env-default:"code"`
+	FrontendURL           string          `yaml:"frontend_url"`
+	LoginCodeTTL          time.Duration   `yaml:"login_code_ttl"           env-default:"1m"`
 }
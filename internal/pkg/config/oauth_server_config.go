@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+type OAuthServer struct {
+	SigningKeys             []string      `yaml:"signing_keys"`
+	LoginURL                string        `yaml:"login_url"`
+	ConsentURL              string        `yaml:"consent_url"`
+	AuthorizationRequestTTL time.Duration `yaml:"authorization_request_ttl" env-default:"10m"`
+	AuthorizationCodeTTL    time.Duration `yaml:"authorization_code_ttl"    env-default:"1m"`
+	AccessTokenTTL          time.Duration `yaml:"access_token_ttl"          env-default:"15m"`
+	RefreshTokenTTL         time.Duration `yaml:"refresh_token_ttl"         env-default:"720h"`
+	IDTokenTTL              time.Duration `yaml:"id_token_ttl"              env-default:"1h"`
+}
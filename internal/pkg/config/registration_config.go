@@ -0,0 +1,8 @@
+package config
+
+import "time"
+
+type Registration struct {
+	Mode          string        `yaml:"mode"           env-default:"open"`
+	InvitationTTL time.Duration `yaml:"invitation_ttl" env-default:"168h"`
+}
@@ -0,0 +1,6 @@
+package config
+
+type Session struct {
+	Mode     string `yaml:"mode"      env-default:"body"`
+	SameSite string `yaml:"same_site" env-default:"strict"`
+}
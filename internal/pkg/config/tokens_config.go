@@ -3,6 +3,15 @@ package config
 import "time"
 
 type Tokens struct {
+	Issuer          string        `yaml:"issuer"      env-default:"http://localhost:8080"`
 	AccessTokenTTL  time.Duration `yaml:"access_ttl"  env-required:"true"`
 	RefreshTokenTTL time.Duration `yaml:"refresh_ttl" env-required:"true"`
+	APIs            []API         `yaml:"apis"`
+}
+
+// API is a resource server access tokens can be requested for with its
+// audience and the scopes it accepts.
+type API struct {
+	Audience string   `yaml:"audience" env-required:"true"`
+	Scopes   []string `yaml:"scopes"`
 }
@@ -0,0 +1,11 @@
+package config
+
+import "time"
+
+type WebAuthn struct {
+	RPID       string        `yaml:"rp_id"       env-default:"localhost"`
+	RPName     string        `yaml:"rp_name"     env-default:"auth-service"`
+	Origins    []string      `yaml:"origins"`
+	Timeout    time.Duration `yaml:"timeout"     env-default:"1m"`
+	SessionTTL time.Duration `yaml:"session_ttl" env-default:"5m"`
+}
@@ -0,0 +1,210 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// softwareAuthenticator emulates a platform authenticator holding a single
+// credential, with ES256 or EdDSA keys.
+type softwareAuthenticator struct {
+	rpID         string
+	origin       string
+	signer       crypto.Signer
+	credentialID []byte
+	signCount    uint32
+	userVerified bool
+	// format is the attestation format, none or packed self attestation.
+	format string
+	// x5c is an attestation certificate chain added to the statement.
+	x5c [][]byte
+}
+
+func newSoftwareAuthenticator(t *testing.T, rpID string, origin string, algorithm int64) *softwareAuthenticator {
+	var (
+		signer crypto.Signer
+		err    error
+	)
+
+	switch algorithm {
+	case AlgorithmES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		t.Fatalf("unsupported algorithm %d", algorithm)
+	}
+
+	require.NoError(t, err)
+
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	return &softwareAuthenticator{
+		rpID:         rpID,
+		origin:       origin,
+		signer:       signer,
+		credentialID: credentialID,
+		userVerified: true,
+		format:       "none",
+	}
+}
+
+func (a *softwareAuthenticator) algorithm() int64 {
+	if _, ok := a.signer.(ed25519.PrivateKey); ok {
+		return AlgorithmEdDSA
+	}
+
+	return AlgorithmES256
+}
+
+func (a *softwareAuthenticator) cosePublicKey(t *testing.T) []byte {
+	var fields map[int64]any
+
+	switch key := a.signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		fields = map[int64]any{
+			coseLabelKeyType:   coseKeyTypeEC2,
+			coseLabelAlgorithm: AlgorithmES256,
+			coseLabelCurve:     coseCurveP256,
+			coseLabelX:         key.X.FillBytes(make([]byte, 32)),
+			coseLabelY:         key.Y.FillBytes(make([]byte, 32)),
+		}
+	case ed25519.PublicKey:
+		fields = map[int64]any{
+			coseLabelKeyType:   coseKeyTypeOKP,
+			coseLabelAlgorithm: AlgorithmEdDSA,
+			coseLabelCurve:     coseCurveEd25519,
+			coseLabelX:         []byte(key),
+		}
+	}
+
+	data, err := cbor.Marshal(fields)
+	require.NoError(t, err)
+
+	return data
+}
+
+func (a *softwareAuthenticator) authenticatorData(t *testing.T, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+
+	flags := byte(flagUserPresent)
+	if a.userVerified {
+		flags |= flagUserVerified
+	}
+
+	if attested {
+		flags |= flagAttestedCredentialData
+	}
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+
+	if attested {
+		data = append(data, make([]byte, aaguidLength)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.cosePublicKey(t)...)
+	}
+
+	return data
+}
+
+func (a *softwareAuthenticator) clientDataJSON(t *testing.T, ceremonyType string, challenge []byte) []byte {
+	data, err := json.Marshal(clientData{
+		Type:      ceremonyType,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    a.origin,
+	})
+	require.NoError(t, err)
+
+	return data
+}
+
+func (a *softwareAuthenticator) sign(t *testing.T, authData []byte, clientDataJSON []byte) []byte {
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signedData := append(append([]byte{}, authData...), clientDataHash[:]...)
+
+	var (
+		signature []byte
+		err       error
+	)
+
+	if key, ok := a.signer.(ed25519.PrivateKey); ok {
+		signature = ed25519.Sign(key, signedData)
+	} else {
+		hash := sha256.Sum256(signedData)
+		signature, err = a.signer.Sign(rand.Reader, hash[:], crypto.SHA256)
+	}
+
+	require.NoError(t, err)
+
+	return signature
+}
+
+// create runs navigator.credentials.create.
+func (a *softwareAuthenticator) create(t *testing.T, challenge []byte) AttestationResponse {
+	authData := a.authenticatorData(t, true)
+	clientDataJSON := a.clientDataJSON(t, clientDataTypeCreate, challenge)
+
+	statement := map[string]any{}
+	if a.format == "packed" {
+		statement["alg"] = a.algorithm()
+		statement["sig"] = a.sign(t, authData, clientDataJSON)
+	}
+
+	if a.x5c != nil {
+		statement["x5c"] = a.x5c
+	}
+
+	attestation, err := cbor.Marshal(map[string]any{
+		"fmt":      a.format,
+		"attStmt":  statement,
+		"authData": authData,
+	})
+	require.NoError(t, err)
+
+	return AttestationResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		RawID: a.credentialID,
+		Type:  credentialTypePublicKey,
+		Response: AuthenticatorAttestationResponse{
+			ClientDataJSON:    clientDataJSON,
+			AttestationObject: attestation,
+			Transports:        []string{"internal"},
+		},
+	}
+}
+
+// get runs navigator.credentials.get, every assertion increments the
+// signature counter.
+func (a *softwareAuthenticator) get(t *testing.T, challenge []byte) AssertionResponse {
+	a.signCount++
+
+	authData := a.authenticatorData(t, false)
+	clientDataJSON := a.clientDataJSON(t, clientDataTypeGet, challenge)
+
+	return AssertionResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		RawID: a.credentialID,
+		Type:  credentialTypePublicKey,
+		Response: AuthenticatorAssertionResponse{
+			ClientDataJSON:    clientDataJSON,
+			AuthenticatorData: authData,
+			Signature:         a.sign(t, authData, clientDataJSON),
+		},
+	}
+}
@@ -0,0 +1,188 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/pkg/errors"
+)
+
+// COSE algorithm identifiers, see the IANA COSE Algorithms registry.
+const (
+	AlgorithmES256 int64 = -7
+	AlgorithmEdDSA int64 = -8
+	AlgorithmRS256 int64 = -257
+)
+
+const (
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+
+	coseLabelKeyType   = 1
+	coseLabelAlgorithm = 3
+	coseLabelCurve     = -1
+	coseLabelX         = -2
+	coseLabelY         = -3
+	coseLabelN         = -1
+	coseLabelE         = -2
+)
+
+func supportedParameters() []CredentialParameter {
+	return []CredentialParameter{
+		{Type: credentialTypePublicKey, Algorithm: AlgorithmES256},
+		{Type: credentialTypePublicKey, Algorithm: AlgorithmEdDSA},
+		{Type: credentialTypePublicKey, Algorithm: AlgorithmRS256},
+	}
+}
+
+// publicKey is a credential public key decoded from the COSE_Key format.
+type publicKey struct {
+	algorithm int64
+	key       crypto.PublicKey
+}
+
+func parsePublicKey(data []byte) (publicKey, error) {
+	var fields map[int64]cbor.RawMessage
+	if err := cbor.Unmarshal(data, &fields); err != nil {
+		return publicKey{}, errors.Wrap(ErrInvalidResponse, "malformed credential public key")
+	}
+
+	var keyType, algorithm int64
+	if err := decodeField(fields, coseLabelKeyType, &keyType); err != nil {
+		return publicKey{}, err
+	}
+
+	if err := decodeField(fields, coseLabelAlgorithm, &algorithm); err != nil {
+		return publicKey{}, err
+	}
+
+	switch {
+	case keyType == coseKeyTypeEC2 && algorithm == AlgorithmES256:
+		return parseEC2PublicKey(fields)
+	case keyType == coseKeyTypeOKP && algorithm == AlgorithmEdDSA:
+		return parseOKPPublicKey(fields)
+	case keyType == coseKeyTypeRSA && algorithm == AlgorithmRS256:
+		return parseRSAPublicKey(fields)
+	}
+
+	return publicKey{}, errors.Wrapf(ErrUnsupportedAlgorithm, "key type %d with algorithm %d", keyType, algorithm)
+}
+
+func parseEC2PublicKey(fields map[int64]cbor.RawMessage) (publicKey, error) {
+	var (
+		curve int64
+		x, y  []byte
+	)
+
+	for label, v := range map[int64]any{coseLabelCurve: &curve, coseLabelX: &x, coseLabelY: &y} {
+		if err := decodeField(fields, label, v); err != nil {
+			return publicKey{}, err
+		}
+	}
+
+	if curve != coseCurveP256 {
+		return publicKey{}, errors.Wrapf(ErrUnsupportedAlgorithm, "ec2 curve %d", curve)
+	}
+
+	key := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+
+	if !key.Curve.IsOnCurve(key.X, key.Y) {
+		return publicKey{}, errors.Wrap(ErrInvalidResponse, "ec2 point is not on curve")
+	}
+
+	return publicKey{algorithm: AlgorithmES256, key: key}, nil
+}
+
+func parseOKPPublicKey(fields map[int64]cbor.RawMessage) (publicKey, error) {
+	var (
+		curve int64
+		x     []byte
+	)
+
+	if err := decodeField(fields, coseLabelCurve, &curve); err != nil {
+		return publicKey{}, err
+	}
+
+	if err := decodeField(fields, coseLabelX, &x); err != nil {
+		return publicKey{}, err
+	}
+
+	if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+		return publicKey{}, errors.Wrapf(ErrUnsupportedAlgorithm, "okp curve %d", curve)
+	}
+
+	return publicKey{algorithm: AlgorithmEdDSA, key: ed25519.PublicKey(x)}, nil
+}
+
+func parseRSAPublicKey(fields map[int64]cbor.RawMessage) (publicKey, error) {
+	var n, e []byte
+
+	if err := decodeField(fields, coseLabelN, &n); err != nil {
+		return publicKey{}, err
+	}
+
+	if err := decodeField(fields, coseLabelE, &e); err != nil {
+		return publicKey{}, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return publicKey{}, errors.Wrap(ErrInvalidResponse, "rsa exponent is too large")
+	}
+
+	key := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}
+
+	return publicKey{algorithm: AlgorithmRS256, key: key}, nil
+}
+
+// verify checks a signature over data made with the algorithm of the key.
+func (k publicKey) verify(data []byte, signature []byte) error {
+	var ok bool
+
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		hash := sha256.Sum256(data)
+		ok = ecdsa.VerifyASN1(key, hash[:], signature)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, data, signature)
+	case *rsa.PublicKey:
+		hash := sha256.Sum256(data)
+		ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature) == nil
+	}
+
+	if !ok {
+		return errors.Wrap(ErrInvalidResponse, "invalid signature")
+	}
+
+	return nil
+}
+
+func decodeField(fields map[int64]cbor.RawMessage, label int64, v any) error {
+	raw, ok := fields[label]
+	if !ok {
+		return errors.Wrapf(ErrInvalidResponse, "credential public key has no field %d", label)
+	}
+
+	if err := cbor.Unmarshal(raw, v); err != nil {
+		return errors.Wrapf(ErrInvalidResponse, "malformed credential public key field %d", label)
+	}
+
+	return nil
+}
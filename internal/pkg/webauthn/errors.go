@@ -0,0 +1,8 @@
+package webauthn
+
+import "errors"
+
+var (
+	ErrInvalidResponse      = errors.New("invalid webauthn response")
+	ErrUnsupportedAlgorithm = errors.New("unsupported webauthn algorithm")
+)
@@ -0,0 +1,211 @@
+// Package webauthn implements the relying party side of the WebAuthn
+// registration and authentication ceremonies for passkeys and security keys.
+// The relying party asks for no attestation and accepts only none and packed
+// self attestation, statements signed with attestation certificates are
+// rejected since they are not checked against a trust chain.
+package webauthn
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	challengeLength = 32
+
+	credentialTypePublicKey = "public-key"
+
+	UserVerificationRequired    = "required"
+	UserVerificationPreferred   = "preferred"
+	UserVerificationDiscouraged = "discouraged"
+)
+
+// URLEncodedBase64 is binary data encoded in JSON as unpadded base64url, as in
+// the JSON form of WebAuthn options and credentials.
+type URLEncodedBase64 []byte
+
+func (b URLEncodedBase64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON accepts padded and unpadded base64url.
+func (b *URLEncodedBase64) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+
+	return nil
+}
+
+func (b URLEncodedBase64) String() string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// NewChallenge returns a random challenge for a ceremony.
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, challengeLength)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntity struct {
+	ID          URLEncodedBase64 `json:"id"`
+	Name        string           `json:"name"`
+	DisplayName string           `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type      string `json:"type"`
+	Algorithm int64  `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type       string           `json:"type"`
+	ID         URLEncodedBase64 `json:"id"`
+	Transports []string         `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions are passed to navigator.credentials.create.
+type CreationOptions struct {
+	Challenge              URLEncodedBase64       `json:"challenge"`
+	RelyingParty           RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Parameters             []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are passed to navigator.credentials.get. Without allowed
+// credentials the authenticator offers discoverable credentials, i.e.
+// passkeys.
+type RequestOptions struct {
+	Challenge        URLEncodedBase64       `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RelyingPartyID   string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// AttestationResponse is the JSON form of a credential returned by
+// navigator.credentials.create.
+type AttestationResponse struct {
+	ID       string                           `json:"id"`
+	RawID    URLEncodedBase64                 `json:"rawId"`
+	Type     string                           `json:"type"`
+	Response AuthenticatorAttestationResponse `json:"response"`
+}
+
+type AuthenticatorAttestationResponse struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AttestationObject URLEncodedBase64 `json:"attestationObject"`
+	Transports        []string         `json:"transports,omitempty"`
+}
+
+// AssertionResponse is the JSON form of a credential returned by
+// navigator.credentials.get.
+type AssertionResponse struct {
+	ID       string                         `json:"id"`
+	RawID    URLEncodedBase64               `json:"rawId"`
+	Type     string                         `json:"type"`
+	Response AuthenticatorAssertionResponse `json:"response"`
+}
+
+type AuthenticatorAssertionResponse struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AuthenticatorData URLEncodedBase64 `json:"authenticatorData"`
+	Signature         URLEncodedBase64 `json:"signature"`
+	UserHandle        URLEncodedBase64 `json:"userHandle,omitempty"`
+}
+
+// Credential is a public key credential created by a registration ceremony.
+type Credential struct {
+	ID           []byte
+	PublicKey    []byte
+	SignCount    uint32
+	Transports   []string
+	UserVerified bool
+}
+
+type Config struct {
+	// RPID is the domain the credentials are scoped to, e.g. example.com.
+	RPID   string
+	RPName string
+	// Origins are the origins of pages allowed to run ceremonies, e.g.
+	// https://login.example.com.
+	Origins []string
+	Timeout time.Duration
+}
+
+type RelyingParty struct {
+	cfg Config
+}
+
+func NewRelyingParty(cfg Config) *RelyingParty {
+	return &RelyingParty{
+		cfg: cfg,
+	}
+}
+
+func (rp *RelyingParty) CreationOptions(challenge []byte, user UserEntity, exclude []CredentialDescriptor) CreationOptions {
+	return CreationOptions{
+		Challenge: challenge,
+		RelyingParty: RelyingPartyEntity{
+			ID:   rp.cfg.RPID,
+			Name: rp.cfg.RPName,
+		},
+		User:               user,
+		Parameters:         supportedParameters(),
+		Timeout:            rp.cfg.Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: UserVerificationPreferred,
+		},
+		Attestation: "none",
+	}
+}
+
+func (rp *RelyingParty) RequestOptions(challenge []byte, allow []CredentialDescriptor, userVerification string) RequestOptions {
+	return RequestOptions{
+		Challenge:        challenge,
+		Timeout:          rp.cfg.Timeout.Milliseconds(),
+		RelyingPartyID:   rp.cfg.RPID,
+		AllowCredentials: allow,
+		UserVerification: userVerification,
+	}
+}
+
+// NewCredentialDescriptor describes a registered credential in options.
+func NewCredentialDescriptor(id []byte, transports []string) CredentialDescriptor {
+	return CredentialDescriptor{
+		Type:       credentialTypePublicKey,
+		ID:         id,
+		Transports: transports,
+	}
+}
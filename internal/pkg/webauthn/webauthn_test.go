@@ -0,0 +1,176 @@
+package webauthn
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://login.example.com"
+)
+
+func newTestRelyingParty() *RelyingParty {
+	return NewRelyingParty(Config{
+		RPID:    testRPID,
+		RPName:  "Example",
+		Origins: []string{testOrigin},
+		Timeout: time.Minute,
+	})
+}
+
+func newTestChallenge(t *testing.T) []byte {
+	challenge, err := NewChallenge()
+	require.NoError(t, err)
+
+	return challenge
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	for _, algorithm := range []int64{AlgorithmES256, AlgorithmEdDSA} {
+		rp := newTestRelyingParty()
+		authenticator := newSoftwareAuthenticator(t, testRPID, testOrigin, algorithm)
+
+		challenge := newTestChallenge(t)
+
+		credential, err := rp.VerifyRegistration(authenticator.create(t, challenge), challenge, true)
+		require.NoError(t, err)
+		require.Equal(t, authenticator.credentialID, credential.ID)
+		require.Equal(t, []string{"internal"}, credential.Transports)
+		require.True(t, credential.UserVerified)
+
+		challenge = newTestChallenge(t)
+
+		signCount, err := rp.VerifyAssertion(authenticator.get(t, challenge), challenge, credential.PublicKey, true)
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), signCount)
+	}
+}
+
+func TestVerifyRegistrationPackedSelfAttestation(t *testing.T) {
+	rp := newTestRelyingParty()
+	authenticator := newSoftwareAuthenticator(t, testRPID, testOrigin, AlgorithmES256)
+	authenticator.format = "packed"
+
+	challenge := newTestChallenge(t)
+
+	_, err := rp.VerifyRegistration(authenticator.create(t, challenge), challenge, false)
+	require.NoError(t, err)
+}
+
+func TestVerifyRegistrationErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		prepare   func(a *softwareAuthenticator)
+		challenge func(challenge []byte) []byte
+	}{
+		{
+			name:      "other challenge",
+			challenge: func([]byte) []byte { return []byte("other challenge") },
+		},
+		{
+			name:    "origin not allowed",
+			prepare: func(a *softwareAuthenticator) { a.origin = "https://evil.example.org" },
+		},
+		{
+			name:    "other rp id",
+			prepare: func(a *softwareAuthenticator) { a.rpID = "evil.example.org" },
+		},
+		{
+			name:    "user not verified",
+			prepare: func(a *softwareAuthenticator) { a.userVerified = false },
+		},
+		{
+			name:    "unsupported attestation",
+			prepare: func(a *softwareAuthenticator) { a.format = "tpm" },
+		},
+		{
+			name: "packed attestation certificate",
+			prepare: func(a *softwareAuthenticator) {
+				a.format = "packed"
+				a.x5c = [][]byte{[]byte("certificate")}
+			},
+		},
+		{
+			name:    "none attestation with statement",
+			prepare: func(a *softwareAuthenticator) { a.x5c = [][]byte{[]byte("certificate")} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := newTestRelyingParty()
+			authenticator := newSoftwareAuthenticator(t, testRPID, testOrigin, AlgorithmES256)
+
+			if tt.prepare != nil {
+				tt.prepare(authenticator)
+			}
+
+			challenge := newTestChallenge(t)
+			response := authenticator.create(t, challenge)
+
+			if tt.challenge != nil {
+				challenge = tt.challenge(challenge)
+			}
+
+			_, err := rp.VerifyRegistration(response, challenge, true)
+			require.ErrorIs(t, err, ErrInvalidResponse)
+		})
+	}
+}
+
+func TestVerifyAssertionErrors(t *testing.T) {
+	rp := newTestRelyingParty()
+	authenticator := newSoftwareAuthenticator(t, testRPID, testOrigin, AlgorithmES256)
+
+	challenge := newTestChallenge(t)
+
+	credential, err := rp.VerifyRegistration(authenticator.create(t, challenge), challenge, true)
+	require.NoError(t, err)
+
+	t.Run("tampered signature", func(t *testing.T) {
+		challenge := newTestChallenge(t)
+		response := authenticator.get(t, challenge)
+		response.Response.Signature[len(response.Response.Signature)-1] ^= 0xff
+
+		_, err := rp.VerifyAssertion(response, challenge, credential.PublicKey, false)
+		require.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("other credential", func(t *testing.T) {
+		other := newSoftwareAuthenticator(t, testRPID, testOrigin, AlgorithmES256)
+		challenge := newTestChallenge(t)
+
+		_, err := rp.VerifyAssertion(other.get(t, challenge), challenge, credential.PublicKey, false)
+		require.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("registration response", func(t *testing.T) {
+		challenge := newTestChallenge(t)
+		response := authenticator.get(t, challenge)
+		response.Response.ClientDataJSON = authenticator.clientDataJSON(t, clientDataTypeCreate, challenge)
+
+		_, err := rp.VerifyAssertion(response, challenge, credential.PublicKey, false)
+		require.ErrorIs(t, err, ErrInvalidResponse)
+	})
+}
+
+func TestAssertionResponseJSON(t *testing.T) {
+	authenticator := newSoftwareAuthenticator(t, testRPID, testOrigin, AlgorithmES256)
+	challenge := newTestChallenge(t)
+	response := authenticator.get(t, challenge)
+
+	data, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	var decoded AssertionResponse
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, response, decoded)
+
+	signedChallenge, err := ClientDataChallenge(decoded.Response.ClientDataJSON)
+	require.NoError(t, err)
+	require.Equal(t, challenge, signedChallenge)
+}
@@ -0,0 +1,165 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	gitHubAuthURL  = "https://github.com/login/oauth/authorize"
+	gitHubTokenURL = "https://github.com/login/oauth/access_token"
+	gitHubAPIURL   = "https://api.github.com"
+
+	gitHubAPIVersion = "2022-11-28"
+)
+
+var (
+	ErrNoPrimaryEmail = errors.New("no primary verified email")
+	ErrInvalidUserID  = errors.New("invalid user id")
+)
+
+type GitHubProviderConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Endpoints default to github.com and may be overridden for GitHub
+	// Enterprise or tests.
+	AuthURL  string
+	TokenURL string
+	APIURL   string
+}
+
+type gitHubUser struct {
+	ID int64 `json:"id"`
+}
+
+type gitHubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// GitHubProvider authenticates users with GitHub, which implements plain OAuth2
+// without ID tokens, so identity is read from the REST API.
+type GitHubProvider struct {
+	name        string
+	apiURL      string
+	oauthConfig oauth2.Config
+	httpClient  *http.Client
+}
+
+func NewGitHubProvider(cfg GitHubProviderConfig, httpClient *http.Client) *GitHubProvider {
+	var (
+		authURL  = valueOrDefault(cfg.AuthURL, gitHubAuthURL)
+		tokenURL = valueOrDefault(cfg.TokenURL, gitHubTokenURL)
+		apiURL   = valueOrDefault(cfg.APIURL, gitHubAPIURL)
+	)
+
+	return &GitHubProvider{
+		name:   cfg.Name,
+		apiURL: apiURL,
+		oauthConfig: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       cfg.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:   authURL,
+				TokenURL:  tokenURL,
+				AuthStyle: oauth2.AuthStyleInParams,
+			},
+		},
+		httpClient: httpClient,
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return p.name
+}
+
+func (p *GitHubProvider) GetAuthURL(_ context.Context, state string, codeVerifier string, _ string) (string, error) {
+	return p.oauthConfig.AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+func (p *GitHubProvider) Authenticate(ctx context.Context, code string, codeVerifier string, _ string) (*OAuthUserInfo, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+
+	token, err := p.oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
+	}
+
+	var user gitHubUser
+	if err := p.get(ctx, token.AccessToken, "/user", &user); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.ID == 0 {
+		return nil, ErrInvalidUserID
+	}
+
+	var emails []gitHubEmail
+	if err := p.get(ctx, token.AccessToken, "/user/emails", &emails); err != nil {
+		return nil, fmt.Errorf("failed to get user emails: %w", err)
+	}
+
+	for _, email := range emails {
+		if !email.Primary || !email.Verified {
+			continue
+		}
+
+		userInfo := &OAuthUserInfo{
+			Subject:       strconv.FormatInt(user.ID, 10),
+			Email:         email.Email,
+			EmailVerified: true,
+		}
+
+		return userInfo, nil
+	}
+
+	return nil, ErrNoPrimaryEmail
+}
+
+func (p *GitHubProvider) get(ctx context.Context, accessToken string, endpoint string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", gitHubAPIVersion)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
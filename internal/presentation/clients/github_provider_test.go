@@ -0,0 +1,108 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeGitHub(t *testing.T, userID int64, emails []gitHubEmail) *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != "code" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "gh-access-token",
+			"token_type":   "bearer",
+		})
+	})
+
+	authorized := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer gh-access-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			next(w, r)
+		}
+	}
+
+	mux.HandleFunc("/api/user", authorized(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(gitHubUser{ID: userID})
+	}))
+
+	mux.HandleFunc("/api/user/emails", authorized(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(emails)
+	}))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestGitHubProvider(server *httptest.Server) *GitHubProvider {
+	cfg := GitHubProviderConfig{
+		Name:        "github",
+		ClientID:    "test-client-id",
+		RedirectURL: "http://localhost:8080/auth/github/callback",
+		Scopes:      []string{"read:user", "user:email"},
+		AuthURL:     server.URL + "/login/oauth/authorize",
+		TokenURL:    server.URL + "/login/oauth/access_token",
+		APIURL:      server.URL + "/api",
+	}
+
+	return NewGitHubProvider(cfg, server.Client())
+}
+
+func TestGitHubProviderAuthenticate(t *testing.T) {
+	t.Run("primary verified email", func(t *testing.T) {
+		server := newFakeGitHub(t, 42, []gitHubEmail{
+			{Email: "other@example.com", Primary: false, Verified: true},
+			{Email: "test.email@gmail.com", Primary: true, Verified: true},
+		})
+
+		userInfo, err := newTestGitHubProvider(server).Authenticate(context.Background(), "code", testCodeVerifier, "")
+		require.NoError(t, err)
+		require.Equal(t, "42", userInfo.Subject)
+		require.Equal(t, "test.email@gmail.com", userInfo.Email)
+		require.True(t, userInfo.EmailVerified)
+	})
+
+	t.Run("primary unverified email", func(t *testing.T) {
+		server := newFakeGitHub(t, 42, []gitHubEmail{
+			{Email: "test.email@gmail.com", Primary: true, Verified: false},
+		})
+
+		_, err := newTestGitHubProvider(server).Authenticate(context.Background(), "code", testCodeVerifier, "")
+		require.ErrorIs(t, err, ErrNoPrimaryEmail)
+	})
+
+	t.Run("no primary email", func(t *testing.T) {
+		server := newFakeGitHub(t, 42, []gitHubEmail{
+			{Email: "other@example.com", Primary: false, Verified: true},
+		})
+
+		_, err := newTestGitHubProvider(server).Authenticate(context.Background(), "code", testCodeVerifier, "")
+		require.ErrorIs(t, err, ErrNoPrimaryEmail)
+	})
+
+	t.Run("zero user id", func(t *testing.T) {
+		server := newFakeGitHub(t, 0, []gitHubEmail{
+			{Email: "test.email@gmail.com", Primary: true, Verified: true},
+		})
+
+		_, err := newTestGitHubProvider(server).Authenticate(context.Background(), "code", testCodeVerifier, "")
+		require.ErrorIs(t, err, ErrInvalidUserID)
+	})
+}
@@ -0,0 +1,22 @@
+package clients
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+type OAuthUserInfo struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+type OAuthProvider interface {
+	Name() string
+	GetAuthURL(ctx context.Context, state string, codeVerifier string, nonce string) (string, error)
+	Authenticate(ctx context.Context, code string, codeVerifier string, nonce string) (*OAuthUserInfo, error)
+}
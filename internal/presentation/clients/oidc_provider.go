@@ -0,0 +1,237 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+)
+
+var idTokenSigningMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string        `json:"nonce"`
+	Email         string        `json:"email"`
+	EmailVerified emailVerified `json:"email_verified"`
+}
+
+// emailVerified accepts both boolean and string values, some issuers send the
+// claim as "true".
+type emailVerified bool
+
+func (v *emailVerified) UnmarshalJSON(data []byte) error {
+	*v = emailVerified(strings.Trim(string(data), `"`) == "true")
+
+	return nil
+}
+
+// OIDCProvider authenticates users against any OpenID Connect issuer. Endpoints
+// and signing keys are taken from the issuer discovery document, which is
+// fetched on first use.
+type OIDCProvider struct {
+	cfg        OIDCProviderConfig
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	oauthConfig *oauth2.Config
+	keySet      *remoteKeySet
+}
+
+func NewOIDCProvider(cfg OIDCProviderConfig, httpClient *http.Client) *OIDCProvider {
+	return &OIDCProvider{
+		cfg:        cfg,
+		httpClient: httpClient,
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *OIDCProvider) GetAuthURL(ctx context.Context, state string, codeVerifier string, nonce string) (string, error) {
+	oauthConfig, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL := oauthConfig.AuthCodeURL(
+		state,
+		oauth2.S256ChallengeOption(codeVerifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	)
+
+	return authURL, nil
+}
+
+func (p *OIDCProvider) Authenticate(ctx context.Context, code string, codeVerifier string, nonce string) (*OAuthUserInfo, error) {
+	oauthConfig, keySet, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+
+	claims, err := p.verifyIDToken(ctx, keySet, rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo := &OAuthUserInfo{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+	}
+
+	return userInfo, nil
+}
+
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, keySet *remoteKeySet, rawIDToken string, nonce string) (*idTokenClaims, error) {
+	var claims idTokenClaims
+
+	keyFunc := func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return keySet.Key(ctx, kid)
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods(idTokenSigningMethods))
+
+	if _, err := parser.ParseWithClaims(rawIDToken, &claims, keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	// The parser checks exp and iat only when they are present, an ID token
+	// must have both.
+	now := time.Now()
+
+	if !claims.VerifyExpiresAt(now, true) {
+		return nil, fmt.Errorf("%w: exp claim is missing", ErrInvalidIDToken)
+	}
+
+	if !claims.VerifyIssuedAt(now, true) {
+		return nil, fmt.Errorf("%w: iat claim is missing", ErrInvalidIDToken)
+	}
+
+	if !claims.VerifyIssuer(p.cfg.Issuer, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer %s", ErrInvalidIDToken, claims.Issuer)
+	}
+
+	if !claims.VerifyAudience(p.cfg.ClientID, true) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidIDToken)
+	}
+
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	if claims.Subject == "" || claims.Email == "" {
+		return nil, fmt.Errorf("%w: subject or email claim is missing", ErrInvalidIDToken)
+	}
+
+	return &claims, nil
+}
+
+// discover returns the endpoints and signing keys of the issuer. The discovery
+// document is fetched without holding the lock, so a slow issuer doesn't
+// block other requests with their own deadlines. Concurrent first requests
+// may fetch it more than once, the first result is kept.
+func (p *OIDCProvider) discover(ctx context.Context) (*oauth2.Config, *remoteKeySet, error) {
+	p.mu.Lock()
+	oauthConfig, keySet := p.oauthConfig, p.keySet
+	p.mu.Unlock()
+
+	if oauthConfig != nil {
+		return oauthConfig, keySet, nil
+	}
+
+	discovery, err := p.fetchDiscovery(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauthConfig == nil {
+		p.oauthConfig = &oauth2.Config{
+			ClientID:     p.cfg.ClientID,
+			ClientSecret: p.cfg.ClientSecret,
+			RedirectURL:  p.cfg.RedirectURL,
+			Scopes:       p.cfg.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  discovery.AuthorizationEndpoint,
+				TokenURL: discovery.TokenEndpoint,
+			},
+		}
+		p.keySet = newRemoteKeySet(discovery.JWKSURI, p.httpClient)
+	}
+
+	return p.oauthConfig, p.keySet, nil
+}
+
+func (p *OIDCProvider) fetchDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	discoveryURL := strings.TrimSuffix(p.cfg.Issuer, "/") + discoveryPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected discovery status code: %d", resp.StatusCode)
+	}
+
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, fmt.Errorf("failed to decode discovery document: %w", err)
+	}
+
+	if discovery.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("discovery issuer %s does not match %s", discovery.Issuer, p.cfg.Issuer)
+	}
+
+	return &discovery, nil
+}
@@ -0,0 +1,300 @@
+package clients
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fakeClientID = "test-client-id"
+	fakeKeyID    = "test-key"
+)
+
+type fakeIssuer struct {
+	server     *httptest.Server
+	key        *rsa.PrivateKey
+	signingKey *rsa.PrivateKey
+
+	codeChallenge string
+	nonce         string
+	claims        func(issuer string, nonce string) jwt.MapClaims
+	// onDiscovery is called before the discovery document is served.
+	onDiscovery func()
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	issuer := &fakeIssuer{
+		key:        key,
+		signingKey: key,
+		claims: func(issuer string, nonce string) jwt.MapClaims {
+			return jwt.MapClaims{
+				"iss":            issuer,
+				"aud":            fakeClientID,
+				"sub":            "subject-1",
+				"email":          "test.email@gmail.com",
+				"email_verified": true,
+				"nonce":          nonce,
+				"iat":            time.Now().Unix(),
+				"exp":            time.Now().Add(time.Minute).Unix(),
+			}
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/jwks", issuer.jwks)
+	mux.HandleFunc("/token", issuer.token)
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+func (i *fakeIssuer) discovery(w http.ResponseWriter, _ *http.Request) {
+	if i.onDiscovery != nil {
+		i.onDiscovery()
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 i.server.URL,
+		"authorization_endpoint": i.server.URL + "/authorize",
+		"token_endpoint":         i.server.URL + "/token",
+		"jwks_uri":               i.server.URL + "/jwks",
+	})
+}
+
+func (i *fakeIssuer) jwks(w http.ResponseWriter, _ *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": fakeKeyID,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(i.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *fakeIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(verifierHash[:]) != i.codeChallenge {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, i.claims(i.server.URL, i.nonce))
+	token.Header["kid"] = fakeKeyID
+
+	idToken, err := token.SignedString(i.signingKey)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (i *fakeIssuer) authorize(t *testing.T, provider *OIDCProvider, verifier string, nonce string) {
+	authURL, err := provider.GetAuthURL(context.Background(), "state", verifier, nonce)
+	require.NoError(t, err)
+
+	parsedURL, err := url.Parse(authURL)
+	require.NoError(t, err)
+
+	i.codeChallenge = parsedURL.Query().Get("code_challenge")
+	i.nonce = parsedURL.Query().Get("nonce")
+}
+
+func newTestOIDCProvider(issuer *fakeIssuer) *OIDCProvider {
+	cfg := OIDCProviderConfig{
+		Name:        "fake",
+		Issuer:      issuer.server.URL,
+		ClientID:    fakeClientID,
+		RedirectURL: "http://localhost:8080/auth/fake/callback",
+		Scopes:      []string{"openid", "email"},
+	}
+
+	return NewOIDCProvider(cfg, issuer.server.Client())
+}
+
+const testCodeVerifier = "test-code-verifier-test-code-verifier-test-code-verifier"
+
+func TestOIDCProviderAuthenticate(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := newTestOIDCProvider(issuer)
+
+	issuer.authorize(t, provider, testCodeVerifier, "nonce-1")
+
+	userInfo, err := provider.Authenticate(context.Background(), "code", testCodeVerifier, "nonce-1")
+	require.NoError(t, err)
+	require.Equal(t, "subject-1", userInfo.Subject)
+	require.Equal(t, "test.email@gmail.com", userInfo.Email)
+	require.True(t, userInfo.EmailVerified)
+}
+
+func TestOIDCProviderAuthenticateStringEmailVerified(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := newTestOIDCProvider(issuer)
+
+	defaultClaims := issuer.claims
+	issuer.claims = func(iss string, nonce string) jwt.MapClaims {
+		claims := defaultClaims(iss, nonce)
+		claims["email_verified"] = "false"
+
+		return claims
+	}
+
+	issuer.authorize(t, provider, testCodeVerifier, "nonce-1")
+
+	userInfo, err := provider.Authenticate(context.Background(), "code", testCodeVerifier, "nonce-1")
+	require.NoError(t, err)
+	require.False(t, userInfo.EmailVerified)
+}
+
+func TestOIDCProviderAuthenticateRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(claims jwt.MapClaims)
+	}{
+		{
+			name:   "other audience",
+			modify: func(claims jwt.MapClaims) { claims["aud"] = "other-client-id" },
+		},
+		{
+			name:   "other issuer",
+			modify: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+		},
+		{
+			name:   "other nonce",
+			modify: func(claims jwt.MapClaims) { claims["nonce"] = "other-nonce" },
+		},
+		{
+			name:   "expired",
+			modify: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Minute).Unix() },
+		},
+		{
+			name:   "without exp",
+			modify: func(claims jwt.MapClaims) { delete(claims, "exp") },
+		},
+		{
+			name:   "without iat",
+			modify: func(claims jwt.MapClaims) { delete(claims, "iat") },
+		},
+		{
+			name:   "issued in the future",
+			modify: func(claims jwt.MapClaims) { claims["iat"] = time.Now().Add(time.Hour).Unix() },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newFakeIssuer(t)
+			provider := newTestOIDCProvider(issuer)
+
+			defaultClaims := issuer.claims
+			issuer.claims = func(iss string, nonce string) jwt.MapClaims {
+				claims := defaultClaims(iss, nonce)
+				tt.modify(claims)
+
+				return claims
+			}
+
+			issuer.authorize(t, provider, testCodeVerifier, "nonce-1")
+
+			_, err := provider.Authenticate(context.Background(), "code", testCodeVerifier, "nonce-1")
+			require.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+}
+
+func TestOIDCProviderAuthenticateRejectsForeignSignature(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := newTestOIDCProvider(issuer)
+
+	issuer.authorize(t, provider, testCodeVerifier, "nonce-1")
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	issuer.signingKey = otherKey
+
+	_, err = provider.Authenticate(context.Background(), "code", testCodeVerifier, "nonce-1")
+	require.ErrorIs(t, err, ErrInvalidIDToken)
+}
+
+func TestOIDCProviderDiscoveryDoesNotBlock(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := newTestOIDCProvider(issuer)
+
+	var (
+		calls       atomic.Int32
+		blocked     = make(chan struct{})
+		release     = make(chan struct{})
+		releaseOnce sync.Once
+	)
+
+	releaseFirst := func() { releaseOnce.Do(func() { close(release) }) }
+	t.Cleanup(releaseFirst)
+
+	// The first discovery request hangs until it is released.
+	issuer.onDiscovery = func() {
+		if calls.Add(1) == 1 {
+			close(blocked)
+			<-release
+		}
+	}
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := provider.GetAuthURL(context.Background(), "state", testCodeVerifier, "nonce-1")
+		first <- err
+	}()
+
+	<-blocked
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := provider.GetAuthURL(context.Background(), "state", testCodeVerifier, "nonce-2")
+		second <- err
+	}()
+
+	select {
+	case err := <-second:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("discovery waits for another request")
+	}
+
+	releaseFirst()
+	require.NoError(t, <-first)
+}
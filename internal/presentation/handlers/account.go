@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AccountHandler struct {
+	log                *slog.Logger
+	accountService     *services.AccountService
+	dataExportService  *services.DataExportService
+	emailChangeService *services.EmailChangeService
+	tracer             trace.Tracer
+}
+
+func NewAccountHandler(
+	accountService *services.AccountService,
+	dataExportService *services.DataExportService,
+	emailChangeService *services.EmailChangeService,
+	log *slog.Logger,
+	tracer trace.Tracer,
+) *AccountHandler {
+	return &AccountHandler{
+		log:                log,
+		accountService:     accountService,
+		dataExportService:  dataExportService,
+		emailChangeService: emailChangeService,
+		tracer:             tracer,
+	}
+}
+
+// DeleteAccount godoc
+// @Summary Delete account
+// @Description Schedules deletion of the current user account and revokes its refresh tokens. The user can still log in during the grace period and cancel the deletion, after that personal data is erased.
+// @Tags Account
+// @Produce json
+// @Security Bearer
+// @Success 202 {object} DeleteAccountResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/me [delete]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AccountHandler.DeleteAccount")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	deletionScheduledAt, err := h.accountService.ScheduleDeletion(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := DeleteAccountResponse{
+		DeletionScheduledAt: deletionScheduledAt,
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// CancelDeletion godoc
+// @Summary Cancel account deletion
+// @Description Cancels a scheduled deletion of the current user account.
+// @Tags Account
+// @Security Bearer
+// @Success 204
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "Deletion is not scheduled"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/me/deletion [delete]
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AccountHandler.CancelDeletion")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.accountService.CancelDeletion(ctx, claims.UserID); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrDeletionNotScheduled) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ExportData godoc
+// @Summary Export personal data
+// @Description Returns a JSON archive of the current user data: the user record, sessions, linked identities, consents and audit events. The archive is generated in the background, until it is ready the export status is returned and a message is sent when it is ready.
+// @Tags Account
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} services.UserDataArchive
+// @Success 202 {object} DataExportResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/me/export [get]
+func (h *AccountHandler) ExportData(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AccountHandler.ExportData")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	export, err := h.dataExportService.RequestExport(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if !export.Ready() {
+		c.JSON(http.StatusAccepted, dataExportToResponse(export))
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="user-data.json"`)
+	c.Data(http.StatusOK, "application/json", export.Data())
+}
+
+// ChangeEmail godoc
+// @Summary Change email
+// @Description Sends a confirmation link to the new email. The current email stays active until the link is followed. A user with a password has to enter it.
+// @Tags Account
+// @Accept json
+// @Security Bearer
+// @Param request body ChangeEmailRequest true "Change Email Request"
+// @Success 202
+// @Failure 400 {string} string "Missing required parameters or email is unchanged"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Invalid password or email domain is not allowed"
+// @Failure 409 {string} string "User with this email already exists"
+// @Failure 429 {string} string "Too many emails sent to the address"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/email/change [post]
+func (h *AccountHandler) ChangeEmail(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AccountHandler.ChangeEmail")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	var request ChangeEmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	if err := h.emailChangeService.RequestChange(ctx, claims.UserID, request.NewEmail, request.Password); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrEmailUnchanged) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrInvalidPassword) {
+			c.String(http.StatusForbidden, "invalid password")
+			return
+		}
+
+		if errors.Is(err, services.ErrEmailDomainNotAllowed) {
+			c.String(http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, repo.ErrDuplicate) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrTooManyEmails) {
+			c.String(http.StatusTooManyRequests, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm email change
+// @Description Switches the user to the new email using the token sent to it. All sessions of the user are revoked and the old email is notified.
+// @Tags Account
+// @Param token query string true "Email change token"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "Missing required parameters"
+// @Failure 401 {string} string "Invalid or expired token"
+// @Failure 409 {string} string "User with this email already exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/email/confirm [get]
+func (h *AccountHandler) ConfirmEmailChange(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AccountHandler.ConfirmEmailChange")
+	defer span.End()
+
+	var queryParams ConfirmEmailChangeQueryParams
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
+		c.String(http.StatusBadRequest, "missing required query parameters")
+		return
+	}
+
+	if err := h.emailChangeService.ConfirmChange(ctx, queryParams.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidEmailChangeToken) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, repo.ErrDuplicate) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
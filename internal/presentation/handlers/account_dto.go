@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type DeleteAccountResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Password string `json:"password"`
+}
+
+type ConfirmEmailChangeQueryParams struct {
+	Token string `form:"token" binding:"required"`
+}
+
+type DataExportResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Status      string    `json:"status"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+func dataExportToResponse(export *entities.DataExport) DataExportResponse {
+	return DataExportResponse{
+		ID:          export.ID(),
+		Status:      export.Status(),
+		RequestedAt: export.RequestedAt(),
+	}
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AdminUserHandler struct {
+	log          *slog.Logger
+	adminService *services.AdminService
+	tracer       trace.Tracer
+}
+
+func NewAdminUserHandler(adminService *services.AdminService, log *slog.Logger, tracer trace.Tracer) *AdminUserHandler {
+	return &AdminUserHandler{
+		log:          log,
+		adminService: adminService,
+		tracer:       tracer,
+	}
+}
+
+// ListUsers godoc
+// @Summary List users
+// @Description Lists users ordered by creation time. Requires the users:read permission.
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param email_prefix query string false "Email prefix"
+// @Param confirmed query bool false "Confirmed"
+// @Param created_from query string false "Created at or after, RFC 3339"
+// @Param created_to query string false "Created before, RFC 3339"
+// @Param limit query int false "Limit, 50 by default, 100 at most"
+// @Param offset query int false "Offset"
+// @Success 200 {object} ListUsersResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users [get]
+func (h *AdminUserHandler) ListUsers(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AdminUserHandler.ListUsers")
+	defer span.End()
+
+	var queryParams ListUsersQueryParams
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
+		c.String(http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	pagination := queryParams.pagination()
+
+	users, err := h.adminService.ListUsers(ctx, queryParams.filters(), pagination)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, usersToListResponse(users, pagination))
+}
+
+// GetUser godoc
+// @Summary Get user
+// @Description Returns the user. Requires the users:read permission.
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param user_id path string true "User id"
+// @Success 200 {object} AdminUserResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users/{user_id} [get]
+func (h *AdminUserHandler) GetUser(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AdminUserHandler.GetUser")
+	defer span.End()
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	user, err := h.adminService.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, userToAdminResponse(user))
+}
+
+// ConfirmUser godoc
+// @Summary Confirm user
+// @Description Confirms the user email without a register token. Requires the users:write permission.
+// @Tags Admin
+// @Security Bearer
+// @Param user_id path string true "User id"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users/{user_id}/confirm [post]
+func (h *AdminUserHandler) ConfirmUser(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AdminUserHandler.ConfirmUser")
+	defer span.End()
+
+	h.userAction(c, ctx, h.adminService.ConfirmUser)
+}
+
+// SuspendUser godoc
+// @Summary Suspend user
+// @Description Blocks logins of the user until the given time and revokes its refresh tokens. Requires the users:write permission.
+// @Tags Admin
+// @Accept json
+// @Security Bearer
+// @Param user_id path string true "User id"
+// @Param suspension body SuspendUserRequest true "Suspend User Request"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users/{user_id}/suspend [post]
+func (h *AdminUserHandler) SuspendUser(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AdminUserHandler.SuspendUser")
+	defer span.End()
+
+	var request SuspendUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	h.userAction(c, ctx, func(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) error {
+		return h.adminService.SuspendUser(ctx, actorID, userID, request.Reason, request.Until)
+	})
+}
+
+// DisableUser godoc
+// @Summary Disable user
+// @Description Blocks logins of the user until it is enabled and revokes its refresh tokens. Requires the users:write permission.
+// @Tags Admin
+// @Accept json
+// @Security Bearer
+// @Param user_id path string true "User id"
+// @Param disable body DisableUserRequest true "Disable User Request"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users/{user_id}/disable [post]
+func (h *AdminUserHandler) DisableUser(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AdminUserHandler.DisableUser")
+	defer span.End()
+
+	var request DisableUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	h.userAction(c, ctx, func(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) error {
+		return h.adminService.DisableUser(ctx, actorID, userID, request.Reason)
+	})
+}
+
+// EnableUser godoc
+// @Summary Enable user
+// @Description Lifts a suspension or a disable of the user. Requires the users:write permission.
+// @Tags Admin
+// @Security Bearer
+// @Param user_id path string true "User id"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users/{user_id}/enable [post]
+func (h *AdminUserHandler) EnableUser(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AdminUserHandler.EnableUser")
+	defer span.End()
+
+	h.userAction(c, ctx, h.adminService.EnableUser)
+}
+
+// LogoutUser godoc
+// @Summary Log out user
+// @Description Revokes refresh tokens of the user, access tokens stay valid until they expire. Requires the users:write permission.
+// @Tags Admin
+// @Security Bearer
+// @Param user_id path string true "User id"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users/{user_id}/logout [post]
+func (h *AdminUserHandler) LogoutUser(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AdminUserHandler.LogoutUser")
+	defer span.End()
+
+	h.userAction(c, ctx, h.adminService.LogoutUser)
+}
+
+// DeleteUser godoc
+// @Summary Delete user
+// @Description Soft-deletes the user. Requires the users:write permission.
+// @Tags Admin
+// @Security Bearer
+// @Param user_id path string true "User id"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users/{user_id} [delete]
+func (h *AdminUserHandler) DeleteUser(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AdminUserHandler.DeleteUser")
+	defer span.End()
+
+	h.userAction(c, ctx, h.adminService.DeleteUser)
+}
+
+// userAction runs an audited admin action on the user from the path.
+func (h *AdminUserHandler) userAction(
+	c *gin.Context,
+	ctx context.Context,
+	action func(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) error,
+) {
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	if err := action(ctx, claims.UserID, userID); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrAdminSelfAction) || errors.Is(err, domain.ErrInvalidSuspension) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
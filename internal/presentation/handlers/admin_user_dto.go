@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+const defaultListUsersLimit = 50
+
+type ListUsersQueryParams struct {
+	EmailPrefix *string    `form:"email_prefix"`
+	Confirmed   *bool      `form:"confirmed"`
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit       int32      `form:"limit" binding:"min=0,max=100"`
+	Offset      int32      `form:"offset" binding:"min=0"`
+}
+
+func (p ListUsersQueryParams) filters() repo.UserFilters {
+	return repo.UserFilters{
+		EmailPrefix: p.EmailPrefix,
+		Confirmed:   p.Confirmed,
+		CreatedFrom: p.CreatedFrom,
+		CreatedTo:   p.CreatedTo,
+	}
+}
+
+func (p ListUsersQueryParams) pagination() repo.Pagination {
+	limit := p.Limit
+	if limit == 0 {
+		limit = defaultListUsersLimit
+	}
+
+	return repo.Pagination{
+		Limit:  limit,
+		Offset: p.Offset,
+	}
+}
+
+type SuspendUserRequest struct {
+	Reason string    `json:"reason" binding:"required"`
+	Until  time.Time `json:"until" binding:"required"`
+}
+
+type DisableUserRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type AdminUserResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	Email        string     `json:"email"`
+	Confirmed    bool       `json:"confirmed"`
+	Status       string     `json:"status"`
+	StatusReason string     `json:"status_reason,omitempty"`
+	StatusUntil  *time.Time `json:"status_until,omitempty"`
+	HasPassword  bool       `json:"has_password"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type ListUsersResponse struct {
+	Users  []AdminUserResponse `json:"users"`
+	Limit  int32               `json:"limit"`
+	Offset int32               `json:"offset"`
+}
+
+func userToAdminResponse(user *entities.User) AdminUserResponse {
+	return AdminUserResponse{
+		ID:           user.ID(),
+		Email:        user.Email(),
+		Confirmed:    user.Confirmed(),
+		Status:       string(user.Status()),
+		StatusReason: user.StatusReason(),
+		StatusUntil:  user.StatusUntil(),
+		HasPassword:  user.HasPassword(),
+		CreatedAt:    user.CreatedAt(),
+	}
+}
+
+func usersToListResponse(users []entities.User, pagination repo.Pagination) ListUsersResponse {
+	response := ListUsersResponse{
+		Users:  make([]AdminUserResponse, 0, len(users)),
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset,
+	}
+
+	for _, user := range users {
+		response.Users = append(response.Users, userToAdminResponse(&user))
+	}
+
+	return response
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 
@@ -12,17 +14,37 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+type AuthHandlerConfig struct {
+	Session SessionConfig
+}
+
 type AuthHandler struct {
-	log         *slog.Logger
-	authService *services.AuthService
-	tracer      trace.Tracer
+	log                 *slog.Logger
+	authService         *services.AuthService
+	registrationService *services.RegistrationService
+	magicLinkService    *services.MagicLinkService
+	emailCodeService    *services.EmailCodeService
+	tracer              trace.Tracer
+	cfg                 AuthHandlerConfig
 }
 
-func NewAuthHandler(service *services.AuthService, log *slog.Logger, tracer trace.Tracer) *AuthHandler {
+func NewAuthHandler(
+	service *services.AuthService,
+	registrationService *services.RegistrationService,
+	magicLinkService *services.MagicLinkService,
+	emailCodeService *services.EmailCodeService,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg AuthHandlerConfig,
+) *AuthHandler {
 	return &AuthHandler{
-		authService: service,
-		log:         log,
-		tracer:      tracer,
+		authService:         service,
+		registrationService: registrationService,
+		magicLinkService:    magicLinkService,
+		emailCodeService:    emailCodeService,
+		log:                 log,
+		tracer:              tracer,
+		cfg:                 cfg,
 	}
 }
 
@@ -47,7 +69,7 @@ func (h *AuthHandler) Confirm(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.Confirm(ctx, queryParams.Email, queryParams.RegisterToken); err != nil {
+	if err := h.registrationService.Confirm(ctx, queryParams.Email, queryParams.RegisterToken); err != nil {
 		if errors.Is(err, repo.ErrObjectNotFound) {
 			c.String(http.StatusNotFound, err.Error())
 			return
@@ -65,16 +87,92 @@ func (h *AuthHandler) Confirm(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+// ConfirmWithCode godoc
+// @Summary Confirm user registration with a code
+// @Description Confirms user registration with the numeric code sent in the registration email, an alternative to the confirmation link for clients that can't open it. The code is dropped after too many invalid attempts, a new one is sent by /auth/confirm/code/send.
+// @Tags Auth
+// @Accept json
+// @Param request body ConfirmCodeRequest true "Confirm Code Request"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "Missing required parameters"
+// @Failure 401 {string} string "Invalid or expired code"
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/confirm/code [post]
+func (h *AuthHandler) ConfirmWithCode(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.ConfirmWithCode")
+	defer span.End()
+
+	var request ConfirmCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	if err := h.registrationService.ConfirmWithCode(ctx, request.Email, request.Code); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrInvalidEmailCode) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// SendConfirmCode godoc
+// @Summary Resend confirmation code
+// @Description Sends a new registration confirmation code, the code sent before stops working. Nothing is sent for an unknown or already confirmed email, the response is the same.
+// @Tags Auth
+// @Accept json
+// @Param request body SendCodeRequest true "Send Code Request"
+// @Success 202
+// @Failure 400 {string} string "Missing required parameters"
+// @Failure 429 {string} string "Too many emails sent to the address"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/confirm/code/send [post]
+func (h *AuthHandler) SendConfirmCode(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.SendConfirmCode")
+	defer span.End()
+
+	var request SendCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	if err := h.registrationService.SendConfirmCode(ctx, request.Email); err != nil {
+		if errors.Is(err, services.ErrTooManyEmails) {
+			c.String(http.StatusTooManyRequests, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
 // Register @Summary User registration
-// @Description Registers a new user with email and password
+// @Description Registers a new user with email and password. Available only when registration is open, an invited user signs up at /auth/invitations/accept.
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param register body RegisterRequest true "Register Request"
 // @Param refcode query string false "Referral Code"
 // @Success 200 {object} RegisterResponse
-// @Failure 400 {string} string "Missing required parameters"
+// @Failure 400 {string} string "Missing required parameters or invalid referral code"
+// @Failure 403 {string} string "Registration is closed, email domain is not allowed"
 // @Failure 409 {string} string "User with this email already exists"
+// @Failure 429 {string} string "Too many emails sent to the address"
 // @Failure 500 {string} string "Internal Server Error"
 // @Router /auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -87,13 +185,34 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	registeredUser, err := h.authService.Register(ctx, request.Email, request.Password)
+	var queryParams RegisterQueryParams
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
+		c.String(http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	registeredUser, err := h.registrationService.Register(ctx, request.Email, request.Password, queryParams.RefCode)
 	if err != nil {
 		if errors.Is(err, repo.ErrDuplicate) {
 			c.String(http.StatusConflict, err.Error())
 			return
 		}
 
+		if errors.Is(err, services.ErrInvalidReferralCode) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrEmailDomainNotAllowed) || errors.Is(err, services.ErrRegistrationClosed) {
+			c.String(http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrTooManyEmails) {
+			c.String(http.StatusTooManyRequests, err.Error())
+			return
+		}
+
 		c.Status(http.StatusInternalServerError)
 		return
 	}
@@ -107,13 +226,14 @@ func (h *AuthHandler) Register(c *gin.Context) {
 }
 
 // Login @Summary User login
-// @Description Login user with email and password
+// @Description Login user with email and password. An access token for a registered API is requested with audience and scope. In cookie session mode the refresh token is set in an HttpOnly cookie instead of the response body. A user with MFA enabled gets an mfa_token instead of tokens, the login is completed at /auth/mfa/challenge.
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param login body LoginRequest true "Login Request"
 // @Success 200 {object} LoginResponse
 // @Failure 400 {string} string "Bad Request"
+// @Failure 403 {string} string "User is suspended or disabled"
 // @Failure 500 {string} string "Internal Server Error"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -126,34 +246,442 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	at, rt, err := h.authService.Login(ctx, request.Email, request.Password)
+	tokenRequest := services.AccessTokenRequest{
+		Audience: request.Audience,
+		Scope:    request.Scope,
+	}
+
+	result, err := h.authService.Login(ctx, request.Email, request.Password, tokenRequest)
 	if err != nil {
 		if errors.Is(err, repo.ErrObjectNotFound) || errors.Is(err, services.ErrInvalidPassword) {
 			c.String(http.StatusOK, "invalid email or password")
 			return
 		}
 
+		if errors.Is(err, services.ErrInvalidAudience) || errors.Is(err, services.ErrInvalidScope) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserSuspended) {
+			c.String(http.StatusForbidden, "user is suspended")
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserDisabled) {
+			c.String(http.StatusForbidden, "user is disabled")
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	h.loginResultResponse(c, result)
+}
+
+// SendMagicLink godoc
+// @Summary Send magic link
+// @Description Emails a single-use login link to the address. Following the link logs the user in and confirms the email, an address without an account is signed up. The response doesn't reveal whether an account exists.
+// @Tags Auth
+// @Accept json
+// @Param request body MagicLinkRequest true "Magic Link Request"
+// @Success 202
+// @Failure 400 {string} string "Missing required parameters"
+// @Failure 403 {string} string "Email domain is not allowed"
+// @Failure 429 {string} string "Too many emails sent to the address"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/magic-link [post]
+func (h *AuthHandler) SendMagicLink(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.SendMagicLink")
+	defer span.End()
+
+	var request MagicLinkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	if err := h.magicLinkService.Send(ctx, request.Email); err != nil {
+		if errors.Is(err, services.ErrEmailDomainNotAllowed) {
+			c.String(http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrTooManyEmails) {
+			c.String(http.StatusTooManyRequests, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// SendLoginCode godoc
+// @Summary Send login code
+// @Description Emails a numeric one-time login code, an alternative to the magic link for clients that can't open links. An address without an account is signed up at login, the response doesn't reveal whether an account exists.
+// @Tags Auth
+// @Accept json
+// @Param request body SendCodeRequest true "Send Code Request"
+// @Success 202
+// @Failure 400 {string} string "Missing required parameters"
+// @Failure 403 {string} string "Email domain is not allowed"
+// @Failure 429 {string} string "Too many emails sent to the address"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/login/code [post]
+func (h *AuthHandler) SendLoginCode(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.SendLoginCode")
+	defer span.End()
+
+	var request SendCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	if err := h.emailCodeService.SendLoginCode(ctx, request.Email); err != nil {
+		if errors.Is(err, services.ErrEmailDomainNotAllowed) {
+			c.String(http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrTooManyEmails) {
+			c.String(http.StatusTooManyRequests, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// CodeLogin godoc
+// @Summary Log in with login code
+// @Description Exchanges the code sent by /auth/login/code for tokens and confirms the email. The code is dropped after too many invalid attempts. A user with MFA enabled gets an mfa_token instead of tokens, the login is completed at /auth/mfa/challenge.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body CodeLoginRequest true "Code Login Request"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Invalid or expired code"
+// @Failure 403 {string} string "User is suspended or disabled, email domain is not allowed, registration is closed"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/login/code/verify [post]
+func (h *AuthHandler) CodeLogin(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.CodeLogin")
+	defer span.End()
+
+	var request CodeLoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	tokenRequest := services.AccessTokenRequest{
+		Audience: request.Audience,
+		Scope:    request.Scope,
+	}
+
+	// The audience and scope are checked first, so the code isn't spent on a
+	// request that fails anyway.
+	if err := h.authService.CheckAccessTokenRequest(tokenRequest); err != nil {
+		h.passwordlessLoginError(c, err)
+		return
+	}
+
+	if err := h.emailCodeService.VerifyLoginCode(ctx, request.Email, request.Code); err != nil {
+		h.passwordlessLoginError(c, err)
+		return
+	}
+
+	result, err := h.passwordlessLogin(ctx, request.Email, tokenRequest)
+	if err != nil {
+		h.passwordlessLoginError(c, err)
+		return
+	}
+
+	h.loginResultResponse(c, result)
+}
+
+// VerifyMagicLink godoc
+// @Summary Log in with magic link
+// @Description Exchanges the token of a magic link for tokens and confirms the email. The token is taken from the query of a followed link or from the body, audience and scope are optional. A user with MFA enabled gets an mfa_token instead of tokens, the login is completed at /auth/mfa/challenge.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param token query string false "Magic link token"
+// @Param request body MagicLinkVerifyRequest false "Magic Link Verify Request"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Invalid, expired or already used link"
+// @Failure 403 {string} string "User is suspended or disabled, email domain is not allowed, registration is closed"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/magic-link/verify [get]
+// @Router /auth/magic-link/verify [post]
+func (h *AuthHandler) VerifyMagicLink(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.VerifyMagicLink")
+	defer span.End()
+
+	var request MagicLinkVerifyRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	tokenRequest := services.AccessTokenRequest{
+		Audience: request.Audience,
+		Scope:    request.Scope,
+	}
+
+	// The audience and scope are checked first, so the link isn't spent on a
+	// request that fails anyway.
+	if err := h.authService.CheckAccessTokenRequest(tokenRequest); err != nil {
+		h.passwordlessLoginError(c, err)
+		return
+	}
+
+	magicLink, err := h.magicLinkService.Consume(ctx, request.Token)
+	if err != nil {
+		h.passwordlessLoginError(c, err)
+		return
+	}
+
+	result, err := h.passwordlessLogin(ctx, magicLink.Email(), tokenRequest)
+	if err != nil {
+		h.passwordlessLoginError(c, err)
+		return
+	}
+
+	h.loginResultResponse(c, result)
+}
+
+// passwordlessLogin logs in the owner of an email verified by a magic link or
+// a login code, an email without an account is signed up first.
+func (h *AuthHandler) passwordlessLogin(ctx context.Context, email string, tokenRequest services.AccessTokenRequest) (*services.LoginResult, error) {
+	result, err := h.authService.PasswordlessLogin(ctx, email, tokenRequest)
+	if !errors.Is(err, services.ErrUserNotSignedUp) {
+		return result, err
+	}
+
+	if _, err := h.registrationService.SignUpPasswordless(ctx, email); err != nil {
+		return nil, err
+	}
+
+	return h.authService.PasswordlessLogin(ctx, email, tokenRequest)
+}
+
+// passwordlessLoginError responds with the error of a login with a magic
+// link or a login code.
+func (h *AuthHandler) passwordlessLoginError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidMagicLink) || errors.Is(err, services.ErrInvalidEmailCode) {
+		c.String(http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if errors.Is(err, services.ErrInvalidAudience) || errors.Is(err, services.ErrInvalidScope) {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if errors.Is(err, services.ErrEmailDomainNotAllowed) || errors.Is(err, services.ErrRegistrationClosed) {
+		c.String(http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrUserSuspended) {
+		c.String(http.StatusForbidden, "user is suspended")
+		return
+	}
+
+	if errors.Is(err, domain.ErrUserDisabled) {
+		c.String(http.StatusForbidden, "user is disabled")
+		return
+	}
+
+	c.Status(http.StatusInternalServerError)
+}
+
+// CompleteMFAChallenge godoc
+// @Summary Complete login with MFA
+// @Description Completes a login of a user with MFA enabled with the mfa_token returned by /auth/login and one of a code from the authenticator app, a recovery code or a WebAuthn assertion started at /auth/mfa/webauthn. The challenge is dropped after too many invalid codes.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param challenge body MFAChallengeRequest true "MFA Challenge Request"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Invalid or expired challenge, invalid code"
+// @Failure 403 {string} string "User is suspended or disabled"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/mfa/challenge [post]
+func (h *AuthHandler) CompleteMFAChallenge(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.CompleteMFAChallenge")
+	defer span.End()
+
+	var request MFAChallengeRequest
+	if err := c.ShouldBindJSON(&request); err != nil || countMFAProofs(request) != 1 {
+		c.String(http.StatusBadRequest, "mfa_token and one of code, recovery_code or webauthn are required")
+		return
+	}
+
+	proof := services.MFAProof{
+		Code:         request.Code,
+		RecoveryCode: request.RecoveryCode,
+		WebAuthn:     request.WebAuthn,
+	}
+
+	at, rt, err := h.authService.CompleteMFAChallenge(ctx, request.MFAToken, proof)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFAChallenge) || errors.Is(err, domain.ErrInvalidMFACode) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrInvalidAudience) || errors.Is(err, services.ErrInvalidScope) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserSuspended) {
+			c.String(http.StatusForbidden, "user is suspended")
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserDisabled) {
+			c.String(http.StatusForbidden, "user is disabled")
+			return
+		}
+
 		c.Status(http.StatusInternalServerError)
 		return
 	}
 
+	h.loginResponse(c, at, rt)
+}
+
+// FinishWebAuthnLogin godoc
+// @Summary Log in with a passkey
+// @Description Completes a passwordless login with the assertion of a passkey for the options returned by /auth/webauthn/login/begin. The passkey verifies the user, so no MFA challenge follows. In cookie session mode the refresh token is set in an HttpOnly cookie.
+// @Tags WebAuthn
+// @Accept json
+// @Produce json
+// @Param login body WebAuthnLoginRequest true "WebAuthn Login Request"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Invalid or expired ceremony, unknown credential or invalid assertion"
+// @Failure 403 {string} string "User is suspended or disabled"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/webauthn/login/finish [post]
+func (h *AuthHandler) FinishWebAuthnLogin(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.FinishWebAuthnLogin")
+	defer span.End()
+
+	var request WebAuthnLoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	tokenRequest := services.AccessTokenRequest{
+		Audience: request.Audience,
+		Scope:    request.Scope,
+	}
+
+	at, rt, err := h.authService.WebAuthnLogin(ctx, *request.Credential, tokenRequest)
+	if err != nil {
+		if isInvalidWebAuthnAssertion(err) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrInvalidAudience) || errors.Is(err, services.ErrInvalidScope) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserSuspended) {
+			c.String(http.StatusForbidden, "user is suspended")
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserDisabled) {
+			c.String(http.StatusForbidden, "user is disabled")
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	h.loginResponse(c, at, rt)
+}
+
+func countMFAProofs(request MFAChallengeRequest) int {
+	count := 0
+
+	for _, set := range []bool{request.Code != "", request.RecoveryCode != "", request.WebAuthn != nil} {
+		if set {
+			count++
+		}
+	}
+
+	return count
+}
+
+// loginResultResponse responds with the MFA challenge token when the user
+// has to pass a second factor, otherwise with the tokens.
+func (h *AuthHandler) loginResultResponse(c *gin.Context, result *services.LoginResult) {
+	if result.MFARequired() {
+		response := LoginResponse{
+			MFARequired: true,
+			MFAToken:    result.MFAToken,
+		}
+
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	h.loginResponse(c, result.AccessToken, result.RefreshToken)
+}
+
+// loginResponse responds with the tokens of a completed login, in cookie
+// session mode the refresh token is set in a cookie.
+func (h *AuthHandler) loginResponse(c *gin.Context, at string, rt string) {
 	response := LoginResponse{
 		AccessToken:  at,
 		RefreshToken: rt,
 	}
 
+	if h.cfg.Session.CookieMode {
+		setSessionCookies(c, rt, h.cfg.Session)
+		response.RefreshToken = ""
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
 // Refresh @Summary Refresh access token
-// @Description Refreshes the access token using the refresh token
+// @Description Refreshes the access token using the refresh token, audience and scope are requested again on every refresh. In cookie session mode the refresh token is read from the cookie when it is missing in the body, and the X-CSRF-Token header must match the csrf_token cookie.
 // @Tags Auth
 // @Accept json
 // @Produce json
-// @Param refresh body RefreshRequest true "Refresh Request"
+// @Param refresh body RefreshRequest false "Refresh Request"
+// @Param X-CSRF-Token header string false "CSRF token, required when the refresh token is sent in a cookie"
 // @Success 200 {object} RefreshResponse
 // @Failure 400 {string} string "Bad Request"
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Invalid CSRF token, user is suspended or disabled"
 // @Failure 500 {string} string "Internal Server Error"
 // @Router /auth/refresh [post]
 func (h *AuthHandler) Refresh(c *gin.Context) {
@@ -161,23 +689,61 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 	defer span.End()
 
 	var request RefreshRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.String(http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	refreshToken := request.RefreshToken
+
+	if refreshToken == "" && h.cfg.Session.CookieMode {
+		if cookieToken, err := c.Cookie(refreshTokenCookie); err == nil {
+			if !ValidCSRFToken(c) {
+				c.String(http.StatusForbidden, "invalid csrf token")
+				return
+			}
+
+			refreshToken = cookieToken
+		}
+	}
+
+	if refreshToken == "" {
 		c.String(http.StatusBadRequest, "missing required parameters")
 		return
 	}
 
-	at, rt, err := h.authService.Refresh(ctx, request.RefreshToken)
+	tokenRequest := services.AccessTokenRequest{
+		Audience: request.Audience,
+		Scope:    request.Scope,
+	}
+
+	at, rt, err := h.authService.Refresh(ctx, refreshToken, tokenRequest)
 	if err != nil {
 		if errors.Is(err, repo.ErrObjectNotFound) {
 			c.String(http.StatusUnauthorized, err.Error())
 			return
 		}
 
+		if errors.Is(err, services.ErrInvalidAudience) || errors.Is(err, services.ErrInvalidScope) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
 		if errors.Is(err, services.ErrUnauthorizedRefresh) {
 			c.String(http.StatusUnauthorized, err.Error())
 			return
 		}
 
+		if errors.Is(err, domain.ErrUserSuspended) {
+			c.String(http.StatusForbidden, "user is suspended")
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserDisabled) {
+			c.String(http.StatusForbidden, "user is disabled")
+			return
+		}
+
 		c.Status(http.StatusInternalServerError)
 		return
 	}
@@ -187,5 +753,56 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		RefreshToken: rt,
 	}
 
+	if h.cfg.Session.CookieMode {
+		setSessionCookies(c, rt, h.cfg.Session)
+		response.RefreshToken = ""
+	}
+
 	c.JSON(http.StatusOK, response)
 }
+
+// SetPassword @Summary Set password
+// @Description Sets a password for a user that was registered with an OAuth provider, so the user can also log in with email and password
+// @Tags Auth
+// @Accept json
+// @Security Bearer
+// @Param password body SetPasswordRequest true "Set Password Request"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "User already has a password"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/password [post]
+func (h *AuthHandler) SetPassword(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "AuthHandler.SetPassword")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	var request SetPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	if err := h.authService.SetPassword(ctx, claims.UserID, request.Password); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrPasswordAlreadySet) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/pkg/webauthn"
 )
 
 type RegisterRequest struct {
@@ -17,23 +18,76 @@ type RegisterResponse struct {
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Audience string `json:"audience"`
+	Scope    string `json:"scope"`
 }
 
 type LoginResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
+}
+
+type MFAChallengeRequest struct {
+	MFAToken     string                      `json:"mfa_token" binding:"required"`
+	Code         string                      `json:"code"`
+	RecoveryCode string                      `json:"recovery_code"`
+	WebAuthn     *webauthn.AssertionResponse `json:"webauthn"`
+}
+
+type WebAuthnLoginRequest struct {
+	Credential *webauthn.AssertionResponse `json:"credential" binding:"required"`
+	Audience   string                      `json:"audience"`
+	Scope      string                      `json:"scope"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type MagicLinkVerifyRequest struct {
+	Token    string `form:"token"    json:"token"    binding:"required"`
+	Audience string `form:"audience" json:"audience"`
+	Scope    string `form:"scope"    json:"scope"`
 }
 
 type RefreshRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+	RefreshToken string `json:"refresh_token"`
+	Audience     string `json:"audience"`
+	Scope        string `json:"scope"`
 }
 
 type RefreshResponse struct {
 	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type RegisterQueryParams struct {
+	RefCode string `form:"refcode"`
 }
 
 type ConfirmQueryParams struct {
 	Email         string `form:"email" binding:"required"`
 	RegisterToken string `form:"register_token" binding:"required"`
 }
+
+type ConfirmCodeRequest struct {
+	Email string `json:"email" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
+
+type SendCodeRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type CodeLoginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+	Audience string `json:"audience"`
+	Scope    string `json:"scope"`
+}
+
+type SetPasswordRequest struct {
+	Password string `json:"password" binding:"required"`
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+)
+
+const (
+	accessTokenClaimsKey = "access_token_claims"
+)
+
+// SetAccessTokenClaims stores claims of the verified request access token for
+// handlers behind the auth middleware.
+func SetAccessTokenClaims(c *gin.Context, claims vobjects.AccessTokenClaims) {
+	c.Set(accessTokenClaimsKey, claims)
+}
+
+func GetAccessTokenClaims(c *gin.Context) (vobjects.AccessTokenClaims, bool) {
+	value, ok := c.Get(accessTokenClaimsKey)
+	if !ok {
+		return vobjects.AccessTokenClaims{}, false
+	}
+
+	claims, ok := value.(vobjects.AccessTokenClaims)
+
+	return claims, ok
+}
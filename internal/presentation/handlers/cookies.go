@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rozhnof/auth-service/internal/domain"
+)
+
+const (
+	AccessTokenCookie      = "access_token"
+	accessTokenCookiePath  = "/"
+	refreshTokenCookie     = "refresh_token"
+	refreshTokenCookiePath = "/auth/refresh"
+	csrfTokenCookie        = "csrf_token"
+	csrfTokenCookiePath    = "/"
+	CSRFTokenHeader        = "X-CSRF-Token"
+
+	csrfTokenLength = 32
+)
+
+type SessionConfig struct {
+	// CookieMode keeps the refresh token in an HttpOnly cookie instead of
+	// response and request bodies.
+	CookieMode      bool
+	SameSite        http.SameSite
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// setSessionCookies stores the refresh token in an HttpOnly cookie sent only to
+// the refresh endpoint, together with a CSRF token readable by the frontend,
+// which has to echo it in the X-CSRF-Token header.
+func setSessionCookies(c *gin.Context, refreshToken string, cfg SessionConfig) {
+	maxAge := int(cfg.RefreshTokenTTL.Seconds())
+
+	c.SetSameSite(cfg.SameSite)
+	c.SetCookie(refreshTokenCookie, refreshToken, maxAge, refreshTokenCookiePath, "", true, true)
+	c.SetCookie(csrfTokenCookie, domain.GenerateRandomString(csrfTokenLength), maxAge, csrfTokenCookiePath, "", true, false)
+}
+
+func setAccessTokenCookie(c *gin.Context, accessToken string, cfg SessionConfig) {
+	c.SetSameSite(cfg.SameSite)
+	c.SetCookie(AccessTokenCookie, accessToken, int(cfg.AccessTokenTTL.Seconds()), accessTokenCookiePath, "", true, true)
+}
+
+// ValidCSRFToken implements double-submit protection for requests
+// authenticated by cookies.
+func ValidCSRFToken(c *gin.Context) bool {
+	cookieToken, err := c.Cookie(csrfTokenCookie)
+	if err != nil || cookieToken == "" {
+		return false
+	}
+
+	headerToken := c.GetHeader(CSRFTokenHeader)
+
+	return subtle.ConstantTimeCompare([]byte(cookieToken), []byte(headerToken)) == 1
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type InvitationHandler struct {
+	log               *slog.Logger
+	invitationService *services.InvitationService
+	tracer            trace.Tracer
+}
+
+func NewInvitationHandler(invitationService *services.InvitationService, log *slog.Logger, tracer trace.Tracer) *InvitationHandler {
+	return &InvitationHandler{
+		log:               log,
+		invitationService: invitationService,
+		tracer:            tracer,
+	}
+}
+
+// Invite godoc
+// @Summary Invite user
+// @Description Sends an invitation to the email. The invitation token is accepted at /auth/invitations/accept, the invited user can also sign up with a magic link, a login code or an OAuth provider with the verified email. An expired invitation to the same email is replaced.
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body InviteRequest true "Invite Request"
+// @Success 201 {object} InvitationResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Registration is closed, email domain is not allowed"
+// @Failure 409 {string} string "User or invitation with this email already exists"
+// @Failure 429 {string} string "Too many emails sent to the address"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/invitations [post]
+func (h *InvitationHandler) Invite(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "InvitationHandler.Invite")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	var request InviteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	invitation, err := h.invitationService.Invite(ctx, claims.UserID, request.Email, "")
+	if err != nil {
+		h.inviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitationToResponse(invitation))
+}
+
+// ListInvitations godoc
+// @Summary List invitations
+// @Description Lists the invitations sent by the current user which are not accepted or revoked yet.
+// @Tags Invitations
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} InvitationResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/invitations [get]
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "InvitationHandler.ListInvitations")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	invitations, err := h.invitationService.ListByInviter(ctx, claims.UserID)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitationsToResponse(invitations))
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke invitation
+// @Description Revokes an invitation sent by the current user.
+// @Tags Invitations
+// @Security Bearer
+// @Param invitation_id path string true "Invitation ID"
+// @Success 204
+// @Failure 400 {string} string "Invalid invitation id"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Invitation not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/invitations/{invitation_id} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "InvitationHandler.RevokeInvitation")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	invitationID, err := uuid.Parse(c.Param("invitation_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid invitation id")
+		return
+	}
+
+	if err := h.invitationService.RevokeOwn(ctx, claims.UserID, invitationID); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept invitation
+// @Description Signs up the invited user with the invitation token and a password. The email is confirmed by the invitation and the user gets the invited role.
+// @Tags Invitations
+// @Accept json
+// @Produce json
+// @Param request body AcceptInvitationRequest true "Accept Invitation Request"
+// @Success 200 {object} RegisterResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Invalid or expired invitation"
+// @Failure 403 {string} string "Registration is closed"
+// @Failure 409 {string} string "User with this email already exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "InvitationHandler.AcceptInvitation")
+	defer span.End()
+
+	var request AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	user, err := h.invitationService.SignUp(ctx, request.Token, request.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInvitation) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrRegistrationClosed) {
+			c.String(http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, repo.ErrDuplicate) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := RegisterResponse{
+		UserID: user.ID(),
+		Email:  user.Email(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminInvite godoc
+// @Summary Invite user with role
+// @Description Sends an invitation to the email, the invited user gets the role once signed up. Requires the users:write permission, an invitation with a role also requires the roles:write permission.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body AdminInviteRequest true "Admin Invite Request"
+// @Success 201 {object} InvitationResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden, registration is closed, email domain is not allowed"
+// @Failure 404 {string} string "Role not found"
+// @Failure 409 {string} string "User or invitation with this email already exists"
+// @Failure 429 {string} string "Too many emails sent to the address"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/invitations [post]
+func (h *InvitationHandler) AdminInvite(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "InvitationHandler.AdminInvite")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	var request AdminInviteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	// Inviting with a role grants the role, as assigning it does.
+	if request.Role != "" && !claims.HasPermission(entities.PermissionRolesWrite) {
+		c.String(http.StatusForbidden, "permission denied")
+		return
+	}
+
+	invitation, err := h.invitationService.Invite(ctx, claims.UserID, request.Email, request.Role)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		h.inviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitationToResponse(invitation))
+}
+
+// AdminListInvitations godoc
+// @Summary List all invitations
+// @Description Lists the invitations which are not accepted or revoked yet. Requires the users:read permission.
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} InvitationResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/invitations [get]
+func (h *InvitationHandler) AdminListInvitations(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "InvitationHandler.AdminListInvitations")
+	defer span.End()
+
+	invitations, err := h.invitationService.List(ctx)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitationsToResponse(invitations))
+}
+
+// AdminRevokeInvitation godoc
+// @Summary Revoke any invitation
+// @Description Revokes an invitation sent by any user. Requires the users:write permission.
+// @Tags Admin
+// @Security Bearer
+// @Param invitation_id path string true "Invitation ID"
+// @Success 204
+// @Failure 400 {string} string "Invalid invitation id"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Invitation not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/invitations/{invitation_id} [delete]
+func (h *InvitationHandler) AdminRevokeInvitation(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "InvitationHandler.AdminRevokeInvitation")
+	defer span.End()
+
+	invitationID, err := uuid.Parse(c.Param("invitation_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid invitation id")
+		return
+	}
+
+	if err := h.invitationService.Revoke(ctx, invitationID); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// inviteError responds with the error of sending an invitation.
+func (h *InvitationHandler) inviteError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrRegistrationClosed) || errors.Is(err, services.ErrEmailDomainNotAllowed) {
+		c.String(http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, repo.ErrDuplicate) {
+		c.String(http.StatusConflict, err.Error())
+		return
+	}
+
+	if errors.Is(err, services.ErrTooManyEmails) {
+		c.String(http.StatusTooManyRequests, err.Error())
+		return
+	}
+
+	c.Status(http.StatusInternalServerError)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type InviteRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type AdminInviteRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type InvitationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	InviterID uuid.UUID `json:"inviter_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func invitationToResponse(invitation *entities.Invitation) InvitationResponse {
+	return InvitationResponse{
+		ID:        invitation.ID(),
+		InviterID: invitation.InviterID(),
+		Email:     invitation.Email(),
+		Role:      invitation.Role(),
+		ExpiresAt: invitation.ExpiredAt(),
+		CreatedAt: invitation.CreatedAt(),
+	}
+}
+
+func invitationsToResponse(invitations []*entities.Invitation) []InvitationResponse {
+	response := make([]InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		response = append(response, invitationToResponse(invitation))
+	}
+
+	return response
+}
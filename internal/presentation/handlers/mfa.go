@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type MFAHandler struct {
+	log        *slog.Logger
+	mfaService *services.MFAService
+	tracer     trace.Tracer
+}
+
+func NewMFAHandler(mfaService *services.MFAService, log *slog.Logger, tracer trace.Tracer) *MFAHandler {
+	return &MFAHandler{
+		log:        log,
+		mfaService: mfaService,
+		tracer:     tracer,
+	}
+}
+
+// SetupTOTP godoc
+// @Summary Set up TOTP
+// @Description Generates a TOTP secret for the current user. The otpauth URI is shown as a QR code to add the secret to an authenticator app, MFA is enabled once a code is sent to /auth/mfa/totp/verify.
+// @Tags MFA
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} TOTPSetupResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "TOTP is already enabled"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/mfa/totp/setup [post]
+func (h *MFAHandler) SetupTOTP(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "MFAHandler.SetupTOTP")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	setup, err := h.mfaService.SetupTOTP(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := TOTPSetupResponse{
+		Secret:     setup.Secret,
+		OTPAuthURI: setup.URI,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// VerifyTOTP godoc
+// @Summary Verify TOTP
+// @Description Enables MFA for the current user with a code from the authenticator app, from then on the code is required at login. Single-use recovery codes are returned, they are shown only once.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body VerifyTOTPRequest true "Verify TOTP Request"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {string} string "Missing required parameters, invalid code or TOTP is not set up"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "TOTP is already enabled"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/mfa/totp/verify [post]
+func (h *MFAHandler) VerifyTOTP(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "MFAHandler.VerifyTOTP")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	var request VerifyTOTPRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.VerifyTOTP(ctx, claims.UserID, request.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrMFANotSetUp) || errors.Is(err, domain.ErrInvalidMFACode) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replaces the MFA recovery codes of the current user with a new set, the old codes stop working.
+// @Tags MFA
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {string} string "MFA is not enabled"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "MFAHandler.RegenerateRecoveryCodes")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.RegenerateRecoveryCodes(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrMFANotSetUp) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// BeginWebAuthnChallenge godoc
+// @Summary Start WebAuthn MFA
+// @Description Starts a WebAuthn assertion with the security keys and passkeys of the user challenged at /auth/login. The options are passed to navigator.credentials.get and the assertion is sent to /auth/mfa/challenge.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param request body MFAWebAuthnRequest true "MFA WebAuthn Request"
+// @Success 200 {object} webauthn.RequestOptions
+// @Failure 400 {string} string "Missing required parameters or no WebAuthn credentials"
+// @Failure 401 {string} string "Invalid or expired challenge"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/mfa/webauthn [post]
+func (h *MFAHandler) BeginWebAuthnChallenge(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "MFAHandler.BeginWebAuthnChallenge")
+	defer span.End()
+
+	var request MFAWebAuthnRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	options, err := h.mfaService.BeginWebAuthnChallenge(ctx, request.MFAToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFAChallenge) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrMFANotSetUp) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, options)
+}
@@ -0,0 +1,18 @@
+package handlers
+
+type TOTPSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type VerifyTOTPRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAWebAuthnRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
@@ -0,0 +1,521 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/presentation/clients"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	oauthStateCookie     = "oauth_state"
+	oauthStateCookiePath = "/auth"
+)
+
+// OAuthCompletionMode defines how a successful OAuth login hands tokens over
+// to the frontend.
+type OAuthCompletionMode string
+
+const (
+	// OAuthCompletionCode redirects with a one-time code, which the frontend
+	// exchanges for tokens.
+	OAuthCompletionCode OAuthCompletionMode = "code"
+	// OAuthCompletionCookie sets tokens in HttpOnly cookies and redirects.
+	OAuthCompletionCookie OAuthCompletionMode = "cookie"
+)
+
+type OAuthHandlerConfig struct {
+	StateTTL       time.Duration
+	CompletionMode OAuthCompletionMode
+	FrontendURL    string
+	Session        SessionConfig
+}
+
+type OAuthHandler struct {
+	providers           map[string]clients.OAuthProvider
+	log                 *slog.Logger
+	authService         *services.AuthService
+	registrationService *services.RegistrationService
+	stateService        *services.OAuthStateService
+	loginCodeService    *services.LoginCodeService
+	tracer              trace.Tracer
+	cfg                 OAuthHandlerConfig
+}
+
+func NewOAuthHandler(
+	providers []clients.OAuthProvider,
+	service *services.AuthService,
+	registrationService *services.RegistrationService,
+	stateService *services.OAuthStateService,
+	loginCodeService *services.LoginCodeService,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg OAuthHandlerConfig,
+) *OAuthHandler {
+	providerMap := make(map[string]clients.OAuthProvider, len(providers))
+	for _, provider := range providers {
+		providerMap[provider.Name()] = provider
+	}
+
+	return &OAuthHandler{
+		providers:           providerMap,
+		authService:         service,
+		registrationService: registrationService,
+		stateService:        stateService,
+		loginCodeService:    loginCodeService,
+		log:                 log,
+		tracer:              tracer,
+		cfg:                 cfg,
+	}
+}
+
+type oauthLoginQueryParams struct {
+	RedirectURI string `form:"redirect_uri"`
+	RefCode     string `form:"refcode"`
+}
+
+// Login godoc
+// @Summary OAuth Login
+// @Description Redirects to the login page of the configured OAuth provider.
+// @Tags OAuth
+// @Param provider path string true "Provider name, e.g. google"
+// @Param redirect_uri query string false "Where to send the browser after login, must be in the allowlist"
+// @Param refcode query string false "Referral code, used when the login signs the user up"
+// @Success 303 {object} string "Redirecting to provider"
+// @Failure 400 {string} string "Redirect uri is not allowed"
+// @Failure 404 {string} string "Unknown provider"
+// @Router /auth/{provider}/login [get]
+func (h *OAuthHandler) Login(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthHandler.Login")
+	defer span.End()
+
+	provider, ok := h.providers[c.Param("provider")]
+	if !ok {
+		c.String(http.StatusNotFound, "unknown oauth provider")
+		return
+	}
+
+	var queryParams oauthLoginQueryParams
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	state, err := h.stateService.Begin(ctx, provider.Name(), queryParams.RedirectURI, queryParams.RefCode)
+	if err != nil {
+		if errors.Is(err, services.ErrRedirectURINotAllowed) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	authURL, err := provider.GetAuthURL(ctx, state.State(), state.CodeVerifier(), state.Nonce())
+	if err != nil {
+		h.log.Warn("failed build oauth url", slog.String("provider", provider.Name()), slog.String("error", err.Error()))
+
+		c.Status(http.StatusBadGateway)
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state.State(), int(h.cfg.StateTTL.Seconds()), oauthStateCookiePath, "", true, true)
+
+	c.Redirect(http.StatusSeeOther, authURL)
+}
+
+type oauthCallbackQueryParams struct {
+	State string `form:"state" binding:"required"`
+	Code  string `form:"code" binding:"required"`
+}
+
+// Callback godoc
+// @Summary OAuth Callback
+// @Description Completes login with the configured OAuth provider. The browser is redirected to redirect_uri or the configured frontend url with a one-time code, which is exchanged for tokens at /auth/token, or with tokens set in HttpOnly cookies, depending on the completion mode. Without a redirect target tokens are returned in the response body. A user with MFA enabled gets an MFA challenge token instead of tokens, by the one-time code in both completion modes, to complete the login at /auth/mfa/challenge.
+// @Tags OAuth
+// @Produce json
+// @Param provider path string true "Provider name, e.g. google"
+// @Param state query string true "OAuth state"
+// @Param code query string true "Authorization code"
+// @Success 200 {object} OAuthLoginResponse
+// @Success 303 {string} string "Redirecting to frontend"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Provider authentication failed"
+// @Failure 403 {string} string "Email domain is not allowed or not verified by the provider, registration is closed, user is suspended or disabled"
+// @Failure 404 {string} string "Unknown provider"
+// @Failure 409 {string} string "Account with this email exists and the identity is not linked"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/{provider}/callback [get]
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthHandler.Callback")
+	defer span.End()
+
+	provider, ok := h.providers[c.Param("provider")]
+	if !ok {
+		c.String(http.StatusNotFound, "unknown oauth provider")
+		return
+	}
+
+	var queryParams oauthCallbackQueryParams
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cookieState, err := c.Cookie(oauthStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookieState), []byte(queryParams.State)) != 1 {
+		c.String(http.StatusBadRequest, "states don't match")
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, "", -1, oauthStateCookiePath, "", true, true)
+
+	state, err := h.stateService.Complete(ctx, provider.Name(), queryParams.State)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidOAuthState) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	userInfo, err := provider.Authenticate(ctx, queryParams.Code, state.CodeVerifier(), state.Nonce())
+	if err != nil {
+		h.log.Warn("failed oauth authentication", slog.String("provider", provider.Name()), slog.String("error", err.Error()))
+
+		c.String(http.StatusUnauthorized, "failed to authenticate with provider")
+		return
+	}
+
+	oauthUser := services.OAuthUser{
+		Provider:      provider.Name(),
+		Subject:       userInfo.Subject,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.EmailVerified,
+		ReferralCode:  state.ReferralCode(),
+	}
+
+	if state.IsLink() {
+		h.completeLink(c, state.LinkUserID(), state.RedirectURI(), oauthUser)
+		return
+	}
+
+	// A user without an account is signed up and then logged in.
+	user, result, err := h.authService.OAuthLogin(ctx, oauthUser)
+	if errors.Is(err, services.ErrUserNotSignedUp) {
+		if _, err = h.registrationService.SignUpOAuth(ctx, oauthUser); err == nil {
+			user, result, err = h.authService.OAuthLogin(ctx, oauthUser)
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, services.ErrEmailDomainNotAllowed) || errors.Is(err, services.ErrRegistrationClosed) {
+			c.String(http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrEmailNotVerified) {
+			c.String(http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrIdentityNotLinked) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrInvalidReferralCode) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserSuspended) {
+			c.String(http.StatusForbidden, "user is suspended")
+			return
+		}
+
+		if errors.Is(err, domain.ErrUserDisabled) {
+			c.String(http.StatusForbidden, "user is disabled")
+			return
+		}
+
+		c.String(http.StatusInternalServerError, "failed to authenticate user")
+		return
+	}
+
+	redirectURI := state.RedirectURI()
+	if redirectURI == "" {
+		redirectURI = h.cfg.FrontendURL
+	}
+
+	if redirectURI == "" {
+		response := OAuthLoginResponse{
+			UserID:       user.ID(),
+			Email:        user.Email(),
+			AccessToken:  result.AccessToken,
+			RefreshToken: result.RefreshToken,
+			MFARequired:  result.MFARequired(),
+			MFAToken:     result.MFAToken,
+		}
+
+		if h.cfg.Session.CookieMode && !result.MFARequired() {
+			setSessionCookies(c, result.RefreshToken, h.cfg.Session)
+			response.RefreshToken = ""
+		}
+
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// With MFA required there are no tokens to set in cookies yet, the
+	// frontend gets the MFA challenge token by a login code instead.
+	if h.cfg.CompletionMode == OAuthCompletionCookie && !result.MFARequired() {
+		setAccessTokenCookie(c, result.AccessToken, h.cfg.Session)
+		setSessionCookies(c, result.RefreshToken, h.cfg.Session)
+
+		c.Redirect(http.StatusSeeOther, redirectURI)
+		return
+	}
+
+	loginCode, err := h.loginCodeService.Issue(ctx, *result)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	redirectWithParams(c, redirectURI, url.Values{"code": {loginCode.Code()}})
+}
+
+// Token godoc
+// @Summary Exchange login code for tokens
+// @Description Exchanges the one-time code, which an OAuth login passed to the frontend, for access and refresh tokens, or for an MFA challenge token when the user has MFA enabled. In cookie session mode the refresh token is set in a cookie instead.
+// @Tags OAuth
+// @Accept json
+// @Produce json
+// @Param token body TokenRequest true "Token Request"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Code is expired or already used"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthHandler.Token")
+	defer span.End()
+
+	var request TokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	loginCode, err := h.loginCodeService.Exchange(ctx, request.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidLoginCode) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if loginCode.MFARequired() {
+		response := LoginResponse{
+			MFARequired: true,
+			MFAToken:    loginCode.MFAToken(),
+		}
+
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	response := LoginResponse{
+		AccessToken:  loginCode.AccessToken(),
+		RefreshToken: loginCode.RefreshToken(),
+	}
+
+	if h.cfg.Session.CookieMode {
+		setSessionCookies(c, loginCode.RefreshToken(), h.cfg.Session)
+		response.RefreshToken = ""
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *OAuthHandler) completeLink(c *gin.Context, userID uuid.UUID, redirectURI string, oauthUser services.OAuthUser) {
+	identity, err := h.authService.LinkIdentity(c.Request.Context(), userID, oauthUser)
+	if err != nil {
+		if errors.Is(err, services.ErrIdentityLinkedToOtherUser) || errors.Is(err, repo.ErrDuplicate) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if redirectURI != "" {
+		c.Redirect(http.StatusSeeOther, redirectURI)
+		return
+	}
+
+	c.JSON(http.StatusOK, identityToResponse(identity))
+}
+
+type linkIdentityQueryParams struct {
+	RedirectURI string `form:"redirect_uri"`
+}
+
+// LinkIdentity godoc
+// @Summary Start linking an OAuth identity
+// @Description Starts the provider login for linking its account to the authenticated user. The browser must be sent to the returned url.
+// @Tags OAuth
+// @Produce json
+// @Security Bearer
+// @Param provider path string true "Provider name, e.g. google"
+// @Param redirect_uri query string false "Where to send the browser after linking, must be in the allowlist"
+// @Success 200 {object} LinkIdentityResponse
+// @Failure 400 {string} string "Redirect uri is not allowed"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Unknown provider"
+// @Router /auth/identities/{provider} [post]
+func (h *OAuthHandler) LinkIdentity(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthHandler.LinkIdentity")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	provider, ok := h.providers[c.Param("provider")]
+	if !ok {
+		c.String(http.StatusNotFound, "unknown oauth provider")
+		return
+	}
+
+	var queryParams linkIdentityQueryParams
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	state, err := h.stateService.BeginLink(ctx, provider.Name(), claims.UserID, queryParams.RedirectURI)
+	if err != nil {
+		if errors.Is(err, services.ErrRedirectURINotAllowed) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	authURL, err := provider.GetAuthURL(ctx, state.State(), state.CodeVerifier(), state.Nonce())
+	if err != nil {
+		h.log.Warn("failed build oauth url", slog.String("provider", provider.Name()), slog.String("error", err.Error()))
+
+		c.Status(http.StatusBadGateway)
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state.State(), int(h.cfg.StateTTL.Seconds()), oauthStateCookiePath, "", true, true)
+
+	c.JSON(http.StatusOK, LinkIdentityResponse{AuthURL: authURL})
+}
+
+// UnlinkIdentity godoc
+// @Summary Unlink an OAuth identity
+// @Description Removes the provider identity from the authenticated user.
+// @Tags OAuth
+// @Security Bearer
+// @Param provider path string true "Provider name, e.g. google"
+// @Success 204
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Identity is not linked"
+// @Failure 409 {string} string "Identity is the only login method"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/identities/{provider} [delete]
+func (h *OAuthHandler) UnlinkIdentity(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthHandler.UnlinkIdentity")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authService.UnlinkIdentity(ctx, claims.UserID, c.Param("provider")); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		if errors.Is(err, services.ErrLastLoginMethod) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListIdentities godoc
+// @Summary List linked OAuth identities
+// @Description Lists provider identities linked to the authenticated user.
+// @Tags OAuth
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} IdentityResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/identities [get]
+func (h *OAuthHandler) ListIdentities(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthHandler.ListIdentities")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	identities, err := h.authService.ListIdentities(ctx, claims.UserID)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]IdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		response = append(response, identityToResponse(&identity))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type OAuthLoginResponse struct {
+	UserID       uuid.UUID `json:"user_id"`
+	Email        string    `json:"email"`
+	AccessToken  string    `json:"access_token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	MFARequired  bool      `json:"mfa_required,omitempty"`
+	MFAToken     string    `json:"mfa_token,omitempty"`
+}
+
+type LinkIdentityResponse struct {
+	AuthURL string `json:"auth_url"`
+}
+
+type IdentityResponse struct {
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"`
+	Email    string    `json:"email"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+func identityToResponse(identity *entities.UserIdentity) IdentityResponse {
+	return IdentityResponse{
+		Provider: identity.Provider(),
+		Subject:  identity.Subject(),
+		Email:    identity.Email(),
+		LinkedAt: identity.LinkedAt(),
+	}
+}
+
+type TokenRequest struct {
+	Code string `json:"code" binding:"required"`
+}
@@ -0,0 +1,533 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	vobjects "github.com/rozhnof/auth-service/internal/domain/value_objects"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tokenTypeBearer = "Bearer"
+
+	authorizationPath = "/oauth/authorize"
+	tokenPath         = "/oauth/token"
+	userInfoPath      = services.UserInfoPath
+	jwksPath          = "/oauth/jwks"
+)
+
+type OAuthServerHandlerConfig struct {
+	// Issuer is the public base url of the service, endpoints in the
+	// discovery document are relative to it.
+	Issuer string
+
+	// ConsentURL is a frontend page that shows the consent request and
+	// submits the user decision. Without it the request is returned as JSON.
+	ConsentURL string
+}
+
+type OAuthServerHandler struct {
+	log           *slog.Logger
+	serverService *services.OAuthServerService
+	tracer        trace.Tracer
+	cfg           OAuthServerHandlerConfig
+}
+
+func NewOAuthServerHandler(
+	serverService *services.OAuthServerService,
+	log *slog.Logger,
+	tracer trace.Tracer,
+	cfg OAuthServerHandlerConfig,
+) *OAuthServerHandler {
+	return &OAuthServerHandler{
+		log:           log,
+		serverService: serverService,
+		tracer:        tracer,
+		cfg:           cfg,
+	}
+}
+
+type authorizeQueryParams struct {
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	ResponseType        string `form:"response_type"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+	Nonce               string `form:"nonce"`
+}
+
+// Authorize godoc
+// @Summary OAuth authorization endpoint
+// @Description Starts the authorization code flow for a registered client. A user that is not logged in is sent to the login page. When the user already granted the requested scopes, the browser is redirected to the client with a code, otherwise to the consent page.
+// @Tags OAuth Server
+// @Produce json
+// @Param client_id query string true "Client id"
+// @Param redirect_uri query string true "Registered redirect uri"
+// @Param response_type query string true "Must be code"
+// @Param scope query string true "Space-delimited scopes"
+// @Param state query string false "Opaque client state"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Param nonce query string false "ID token nonce"
+// @Success 200 {object} AuthorizationRequestResponse "Consent required and no consent page is configured"
+// @Success 303 {string} string "Redirecting to client or consent page"
+// @Failure 400 {string} string "Unknown client or redirect uri"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /oauth/authorize [get]
+func (h *OAuthServerHandler) Authorize(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthServerHandler.Authorize")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	var queryParams authorizeQueryParams
+	if err := c.ShouldBindQuery(&queryParams); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := services.AuthorizeParams{
+		ClientID:            queryParams.ClientID,
+		RedirectURI:         queryParams.RedirectURI,
+		ResponseType:        queryParams.ResponseType,
+		Scope:               queryParams.Scope,
+		State:               queryParams.State,
+		CodeChallenge:       queryParams.CodeChallenge,
+		CodeChallengeMethod: queryParams.CodeChallengeMethod,
+		Nonce:               queryParams.Nonce,
+	}
+
+	result, err := h.serverService.Authorize(ctx, claims.UserID, params)
+	if err != nil {
+		var oauthErr *services.OAuthError
+		if errors.As(err, &oauthErr) {
+			h.redirectWithError(c, queryParams.RedirectURI, queryParams.State, oauthErr)
+			return
+		}
+
+		if errors.Is(err, services.ErrInvalidOAuthClient) || errors.Is(err, services.ErrRedirectURINotAllowed) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if result.Code != nil {
+		h.redirectWithCode(c, result)
+		return
+	}
+
+	if h.cfg.ConsentURL == "" {
+		c.JSON(http.StatusOK, AuthorizationRequestResponse{
+			RequestID: result.Request.ID(),
+			ClientID:  result.Request.ClientID(),
+			Scopes:    result.Request.Scopes(),
+		})
+		return
+	}
+
+	redirectWithParams(c, h.cfg.ConsentURL, url.Values{"request_id": {result.Request.ID()}})
+}
+
+// GetAuthorizationRequest godoc
+// @Summary Get authorization request
+// @Description Returns an authorization request waiting for consent of the authenticated user.
+// @Tags OAuth Server
+// @Produce json
+// @Security Bearer
+// @Param request_id path string true "Authorization request id"
+// @Success 200 {object} AuthorizationRequestResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Request is expired or not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /oauth/consent/{request_id} [get]
+func (h *OAuthServerHandler) GetAuthorizationRequest(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthServerHandler.GetAuthorizationRequest")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	request, client, err := h.serverService.GetAuthorizationRequest(ctx, claims.UserID, c.Param("request_id"))
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := AuthorizationRequestResponse{
+		RequestID:  request.ID(),
+		ClientID:   client.ID(),
+		ClientName: client.Name(),
+		Scopes:     request.Scopes(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Consent godoc
+// @Summary Consent to authorization request
+// @Description Approves or denies an authorization request of the authenticated user and returns the client url the browser must be sent to.
+// @Tags OAuth Server
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request_id path string true "Authorization request id"
+// @Param consent body ConsentRequest true "Consent Request"
+// @Success 200 {object} ConsentResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Request is expired or not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /oauth/consent/{request_id} [post]
+func (h *OAuthServerHandler) Consent(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthServerHandler.Consent")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	var request ConsentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	result, err := h.serverService.Consent(ctx, claims.UserID, c.Param("request_id"), request.Approve)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	params := url.Values{}
+	if result.Code != nil {
+		params.Set("code", result.Code.Code())
+	} else {
+		params.Set("error", services.OAuthErrorAccessDenied)
+	}
+
+	if result.Request.State() != "" {
+		params.Set("state", result.Request.State())
+	}
+
+	redirectTo, err := withParams(result.Request.RedirectURI(), params)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, ConsentResponse{RedirectTo: redirectTo})
+}
+
+type tokenFormParams struct {
+	GrantType    string `form:"grant_type"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	Audience     string `form:"audience"`
+}
+
+// Token godoc
+// @Summary OAuth token endpoint
+// @Description Issues tokens to a client for an authorization code or a refresh token, or to a machine client itself with client credentials. Confidential clients authenticate with HTTP Basic or client_secret in the form.
+// @Tags OAuth Server
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code, refresh_token or client_credentials"
+// @Param client_id formData string false "Client id, when HTTP Basic is not used"
+// @Param client_secret formData string false "Client secret, when HTTP Basic is not used"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect uri of the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param scope formData string false "Narrowed scope on refresh, requested scope for client credentials"
+// @Param audience formData string false "Registered API a client credentials token is requested for"
+// @Success 200 {object} OAuthTokenResponse
+// @Failure 400 {object} OAuthErrorResponse
+// @Failure 401 {object} OAuthErrorResponse
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /oauth/token [post]
+func (h *OAuthServerHandler) Token(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthServerHandler.Token")
+	defer span.End()
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var form tokenFormParams
+	if err := c.ShouldBind(&form); err != nil {
+		c.JSON(http.StatusBadRequest, OAuthErrorResponse{Error: services.OAuthErrorInvalidRequest})
+		return
+	}
+
+	clientID, clientSecret, basicAuth := clientCredentials(c)
+	if !basicAuth {
+		clientID, clientSecret = form.ClientID, form.ClientSecret
+	}
+
+	params := services.TokenParams{
+		GrantType:    form.GrantType,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Code:         form.Code,
+		RedirectURI:  form.RedirectURI,
+		CodeVerifier: form.CodeVerifier,
+		RefreshToken: form.RefreshToken,
+		Scope:        form.Scope,
+		Audience:     form.Audience,
+	}
+
+	result, err := h.serverService.Token(ctx, params)
+	if err != nil {
+		var oauthErr *services.OAuthError
+		if errors.As(err, &oauthErr) {
+			status := http.StatusBadRequest
+			if oauthErr.Code == services.OAuthErrorInvalidClient {
+				status = http.StatusUnauthorized
+				if basicAuth {
+					c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+				}
+			}
+
+			c.JSON(status, OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := OAuthTokenResponse{
+		AccessToken:  result.AccessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int(result.ExpiresIn.Seconds()),
+		RefreshToken: result.RefreshToken,
+		IDToken:      result.IDToken,
+		Scope:        result.Scopes.String(),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// JWKS godoc
+// @Summary OAuth signing keys
+// @Description Returns public keys that verify ID tokens issued by the service.
+// @Tags OAuth Server
+// @Produce json
+// @Success 200 {object} JSONWebKeySetResponse
+// @Router /oauth/jwks [get]
+func (h *OAuthServerHandler) JWKS(c *gin.Context) {
+	_, span := h.tracer.Start(c.Request.Context(), "OAuthServerHandler.JWKS")
+	defer span.End()
+
+	publicKeys := h.serverService.PublicKeys()
+
+	response := JSONWebKeySetResponse{
+		Keys: make([]JSONWebKey, 0, len(publicKeys)),
+	}
+
+	for _, publicKey := range publicKeys {
+		response.Keys = append(response.Keys, JSONWebKey{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: publicKey.KeyID,
+			N:   base64.RawURLEncoding.EncodeToString(publicKey.Key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.Key.E)).Bytes()),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Discovery godoc
+// @Summary OpenID Connect discovery document
+// @Description Describes the issuer, endpoints and supported features of the OpenID Connect provider.
+// @Tags OAuth Server
+// @Produce json
+// @Success 200 {object} DiscoveryResponse
+// @Router /.well-known/openid-configuration [get]
+func (h *OAuthServerHandler) Discovery(c *gin.Context) {
+	_, span := h.tracer.Start(c.Request.Context(), "OAuthServerHandler.Discovery")
+	defer span.End()
+
+	issuer := strings.TrimSuffix(h.cfg.Issuer, "/")
+
+	response := DiscoveryResponse{
+		Issuer:                            h.cfg.Issuer,
+		AuthorizationEndpoint:             issuer + authorizationPath,
+		TokenEndpoint:                     issuer + tokenPath,
+		UserInfoEndpoint:                  issuer + userInfoPath,
+		JWKSURI:                           issuer + jwksPath,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{services.GrantTypeAuthorizationCode, services.GrantTypeRefreshToken, services.GrantTypeClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		ScopesSupported:                   []string{vobjects.ScopeOpenID, vobjects.ScopeEmail},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"iss", "sub", "aud", "exp", "iat", "nonce", "email", "email_verified"},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UserInfo godoc
+// @Summary OpenID Connect userinfo
+// @Description Returns claims about the user an access token with the openid scope is issued for. Email claims require the email scope.
+// @Tags OAuth Server
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} UserInfoResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Insufficient scope"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /oauth/userinfo [get]
+// @Router /oauth/userinfo [post]
+func (h *OAuthServerHandler) UserInfo(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "OAuthServerHandler.UserInfo")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	scopes := claims.Scopes()
+	if !scopes.Has(vobjects.ScopeOpenID) {
+		c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+		c.String(http.StatusForbidden, "access token has no openid scope")
+		return
+	}
+
+	user, err := h.serverService.UserInfo(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := UserInfoResponse{
+		Subject: user.ID().String(),
+	}
+
+	if scopes.Has(vobjects.ScopeEmail) {
+		emailVerified := user.Confirmed()
+
+		response.Email = user.Email()
+		response.EmailVerified = &emailVerified
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *OAuthServerHandler) redirectWithCode(c *gin.Context, result *services.AuthorizeResult) {
+	params := url.Values{"code": {result.Code.Code()}}
+	if result.Request.State() != "" {
+		params.Set("state", result.Request.State())
+	}
+
+	redirectWithParams(c, result.Request.RedirectURI(), params)
+}
+
+func (h *OAuthServerHandler) redirectWithError(c *gin.Context, redirectURI string, state string, oauthErr *services.OAuthError) {
+	params := url.Values{
+		"error":             {oauthErr.Code},
+		"error_description": {oauthErr.Description},
+	}
+
+	if state != "" {
+		params.Set("state", state)
+	}
+
+	redirectWithParams(c, redirectURI, params)
+}
+
+// clientCredentials reads client credentials of HTTP Basic authentication,
+// which are form-encoded as required by RFC 6749.
+func clientCredentials(c *gin.Context) (clientID string, clientSecret string, ok bool) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return "", "", false
+	}
+
+	clientID, err := url.QueryUnescape(username)
+	if err != nil {
+		return "", "", false
+	}
+
+	clientSecret, err = url.QueryUnescape(password)
+	if err != nil {
+		return "", "", false
+	}
+
+	return clientID, clientSecret, true
+}
+
+func redirectWithParams(c *gin.Context, target string, params url.Values) {
+	location, err := withParams(target, params)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, location)
+}
+
+func withParams(target string, params url.Values) (string, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+
+	query := targetURL.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+
+	targetURL.RawQuery = query.Encode()
+
+	return targetURL.String(), nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ReferralHandler struct {
+	log             *slog.Logger
+	referralService *services.ReferralService
+	tracer          trace.Tracer
+}
+
+func NewReferralHandler(referralService *services.ReferralService, log *slog.Logger, tracer trace.Tracer) *ReferralHandler {
+	return &ReferralHandler{
+		log:             log,
+		referralService: referralService,
+		tracer:          tracer,
+	}
+}
+
+// GetReferralCode godoc
+// @Summary Get referral code
+// @Description Returns the referral code of the current user and the number of users signed up with the user codes. The code is passed as the refcode query parameter on register or OAuth login, a new code is issued once it expires.
+// @Tags Auth
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} ReferralCodeResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/me/referral-code [get]
+func (h *ReferralHandler) GetReferralCode(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "ReferralHandler.GetReferralCode")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	code, err := h.referralService.GetCode(ctx, claims.UserID)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	referralsCount, err := h.referralService.CountReferrals(ctx, claims.UserID)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := ReferralCodeResponse{
+		Code:           code.Code(),
+		ExpiresAt:      code.ExpiredAt(),
+		ReferralsCount: referralsCount,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
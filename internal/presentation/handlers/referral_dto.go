@@ -0,0 +1,9 @@
+package handlers
+
+import "time"
+
+type ReferralCodeResponse struct {
+	Code           string    `json:"code"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	ReferralsCount int64     `json:"referrals_count"`
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type RoleHandler struct {
+	log         *slog.Logger
+	roleService *services.RoleService
+	tracer      trace.Tracer
+}
+
+func NewRoleHandler(roleService *services.RoleService, log *slog.Logger, tracer trace.Tracer) *RoleHandler {
+	return &RoleHandler{
+		log:         log,
+		roleService: roleService,
+		tracer:      tracer,
+	}
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description Lists roles with their permissions. Requires the roles:read permission.
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} RoleResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "RoleHandler.ListRoles")
+	defer span.End()
+
+	roles, err := h.roleService.ListRoles(ctx)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, rolesToResponse(roles))
+}
+
+// CreateRole godoc
+// @Summary Create role
+// @Description Creates a role with a set of permissions. Requires the roles:write permission.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param role body CreateRoleRequest true "Create Role Request"
+// @Success 201 {object} RoleResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {string} string "Role already exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "RoleHandler.CreateRole")
+	defer span.End()
+
+	var request CreateRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	role, err := h.roleService.CreateRole(ctx, request.Name, request.Description, request.Permissions)
+	if err != nil {
+		if errors.Is(err, repo.ErrDuplicate) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, roleToResponse(role))
+}
+
+// ListUserRoles godoc
+// @Summary List user roles
+// @Description Lists roles assigned to the user. Requires the roles:read permission.
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param user_id path string true "User id"
+// @Success 200 {array} RoleResponse
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users/{user_id}/roles [get]
+func (h *RoleHandler) ListUserRoles(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "RoleHandler.ListUserRoles")
+	defer span.End()
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	roles, err := h.roleService.ListUserRoles(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, rolesToResponse(roles))
+}
+
+// AssignRole godoc
+// @Summary Assign role
+// @Description Assigns the role to the user, it gets into access tokens issued after that. Requires the roles:write permission.
+// @Tags Admin
+// @Security Bearer
+// @Param user_id path string true "User id"
+// @Param role path string true "Role name"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User or role not exists"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users/{user_id}/roles/{role} [put]
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "RoleHandler.AssignRole")
+	defer span.End()
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	if err := h.roleService.AssignRole(ctx, userID, c.Param("role")); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UnassignRole godoc
+// @Summary Unassign role
+// @Description Removes the role from the user. Requires the roles:write permission.
+// @Tags Admin
+// @Security Bearer
+// @Param user_id path string true "User id"
+// @Param role path string true "Role name"
+// @Success 204
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Role is not assigned"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /admin/users/{user_id}/roles/{role} [delete]
+func (h *RoleHandler) UnassignRole(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "RoleHandler.UnassignRole")
+	defer span.End()
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	if err := h.roleService.UnassignRole(ctx, userID, c.Param("role")); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+)
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type RoleResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func roleToResponse(role *entities.Role) RoleResponse {
+	permissions := role.Permissions()
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return RoleResponse{
+		ID:          role.ID(),
+		Name:        role.Name(),
+		Description: role.Description(),
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt(),
+	}
+}
+
+func rolesToResponse(roles []*entities.Role) []RoleResponse {
+	response := make([]RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		response = append(response, roleToResponse(role))
+	}
+
+	return response
+}
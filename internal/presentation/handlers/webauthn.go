@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	repo "github.com/rozhnof/auth-service/internal/application/repository"
+	"github.com/rozhnof/auth-service/internal/application/services"
+	"github.com/rozhnof/auth-service/internal/domain"
+	"github.com/rozhnof/auth-service/internal/domain/entities"
+	"github.com/rozhnof/auth-service/internal/pkg/webauthn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type WebAuthnHandler struct {
+	log             *slog.Logger
+	webauthnService *services.WebAuthnService
+	tracer          trace.Tracer
+}
+
+func NewWebAuthnHandler(webauthnService *services.WebAuthnService, log *slog.Logger, tracer trace.Tracer) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		log:             log,
+		webauthnService: webauthnService,
+		tracer:          tracer,
+	}
+}
+
+// BeginRegistration godoc
+// @Summary Start passkey registration
+// @Description Starts the registration of a passkey or a security key for the current user. The options are passed to navigator.credentials.create and the new credential is sent to /auth/webauthn/register/finish.
+// @Tags WebAuthn
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} webauthn.CreationOptions
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/webauthn/register/begin [post]
+func (h *WebAuthnHandler) BeginRegistration(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "WebAuthnHandler.BeginRegistration")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	options, err := h.webauthnService.BeginRegistration(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, options)
+}
+
+// FinishRegistration godoc
+// @Summary Finish passkey registration
+// @Description Saves the credential created by the authenticator for the current user. A user with a registered credential can log in with it and is asked for it as a second factor after the password.
+// @Tags WebAuthn
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body FinishWebAuthnRegistrationRequest true "Finish WebAuthn Registration Request"
+// @Success 200 {object} WebAuthnCredentialResponse
+// @Failure 400 {string} string "Missing required parameters, invalid or expired ceremony, invalid credential"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "Credential is already registered"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/webauthn/register/finish [post]
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "WebAuthnHandler.FinishRegistration")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	var request FinishWebAuthnRegistrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.String(http.StatusBadRequest, "missing required parameters")
+		return
+	}
+
+	credential, err := h.webauthnService.FinishRegistration(ctx, claims.UserID, request.Name, *request.Credential)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidWebAuthnSession) ||
+			errors.Is(err, webauthn.ErrInvalidResponse) ||
+			errors.Is(err, webauthn.ErrUnsupportedAlgorithm) {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, repo.ErrDuplicate) {
+			c.String(http.StatusConflict, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, webauthnCredentialResponse(credential))
+}
+
+// ListCredentials godoc
+// @Summary List passkeys
+// @Description Lists the passkeys and security keys registered by the current user.
+// @Tags WebAuthn
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} WebAuthnCredentialsResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/webauthn/credentials [get]
+func (h *WebAuthnHandler) ListCredentials(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "WebAuthnHandler.ListCredentials")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	credentials, err := h.webauthnService.ListCredentials(ctx, claims.UserID)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	response := WebAuthnCredentialsResponse{
+		Credentials: make([]WebAuthnCredentialResponse, 0, len(credentials)),
+	}
+
+	for _, credential := range credentials {
+		response.Credentials = append(response.Credentials, webauthnCredentialResponse(credential))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteCredential godoc
+// @Summary Delete passkey
+// @Description Deletes a passkey or a security key of the current user.
+// @Tags WebAuthn
+// @Security Bearer
+// @Param id path string true "Credential ID, base64url"
+// @Success 204
+// @Failure 400 {string} string "Invalid credential id"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Credential not found"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/webauthn/credentials/{id} [delete]
+func (h *WebAuthnHandler) DeleteCredential(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "WebAuthnHandler.DeleteCredential")
+	defer span.End()
+
+	claims, ok := GetAccessTokenClaims(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	id, err := base64.RawURLEncoding.DecodeString(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid credential id")
+		return
+	}
+
+	if err := h.webauthnService.DeleteCredential(ctx, claims.UserID, id); err != nil {
+		if errors.Is(err, repo.ErrObjectNotFound) {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// BeginLogin godoc
+// @Summary Start passkey login
+// @Description Starts a passwordless login. The options are passed to navigator.credentials.get, the authenticator offers the passkeys it has for the service, and the assertion is sent to /auth/webauthn/login/finish.
+// @Tags WebAuthn
+// @Produce json
+// @Success 200 {object} webauthn.RequestOptions
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /auth/webauthn/login/begin [post]
+func (h *WebAuthnHandler) BeginLogin(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "WebAuthnHandler.BeginLogin")
+	defer span.End()
+
+	options, err := h.webauthnService.BeginLogin(ctx)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, options)
+}
+
+func webauthnCredentialResponse(credential *entities.WebAuthnCredential) WebAuthnCredentialResponse {
+	return WebAuthnCredentialResponse{
+		ID:         base64.RawURLEncoding.EncodeToString(credential.ID()),
+		Name:       credential.Name(),
+		Transports: credential.Transports(),
+		CreatedAt:  credential.CreatedAt(),
+		LastUsedAt: credential.LastUsedAt(),
+	}
+}
+
+// isInvalidWebAuthnAssertion reports whether the assertion was rejected, as
+// opposed to failing to check it.
+func isInvalidWebAuthnAssertion(err error) bool {
+	return errors.Is(err, services.ErrInvalidWebAuthnSession) ||
+		errors.Is(err, services.ErrInvalidWebAuthnCredential) ||
+		errors.Is(err, webauthn.ErrInvalidResponse) ||
+		errors.Is(err, webauthn.ErrUnsupportedAlgorithm) ||
+		errors.Is(err, domain.ErrInvalidSignCount)
+}
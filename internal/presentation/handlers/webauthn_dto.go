@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/rozhnof/auth-service/internal/pkg/webauthn"
+)
+
+type FinishWebAuthnRegistrationRequest struct {
+	Name       string                        `json:"name"`
+	Credential *webauthn.AttestationResponse `json:"credential" binding:"required"`
+}
+
+type WebAuthnCredentialResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+type WebAuthnCredentialsResponse struct {
+	Credentials []WebAuthnCredentialResponse `json:"credentials"`
+}
@@ -0,0 +1,10 @@
+CREATE TABLE user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    user_id UUID REFERENCES users (id) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(50) NOT NULL,
+    linked_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
@@ -0,0 +1,6 @@
+DELETE FROM user_identities WHERE user_id IN (SELECT id FROM users WHERE hash_password IS NULL);
+DELETE FROM refresh_token WHERE user_id IN (SELECT id FROM users WHERE hash_password IS NULL);
+DELETE FROM register_token WHERE user_id IN (SELECT id FROM users WHERE hash_password IS NULL);
+DELETE FROM users WHERE hash_password IS NULL;
+
+ALTER TABLE users ALTER COLUMN hash_password SET NOT NULL;
@@ -0,0 +1,25 @@
+CREATE TABLE oauth_clients (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    secret_hash CHAR(60) NULL,
+    redirect_uris TEXT[] NOT NULL,
+    allowed_scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE TABLE oauth_consents (
+    user_id UUID REFERENCES users (id) NOT NULL,
+    client_id VARCHAR(64) REFERENCES oauth_clients (id) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    granted_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, client_id)
+);
+
+CREATE TABLE oauth_refresh_tokens (
+    token VARCHAR PRIMARY KEY,
+    client_id VARCHAR(64) REFERENCES oauth_clients (id) NOT NULL,
+    user_id UUID REFERENCES users (id) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    expired_at TIMESTAMP NOT NULL
+);
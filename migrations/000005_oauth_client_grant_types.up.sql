@@ -0,0 +1 @@
+ALTER TABLE oauth_clients ADD COLUMN grant_types TEXT[] NOT NULL DEFAULT '{authorization_code,refresh_token}';
@@ -0,0 +1,5 @@
+DROP TABLE user_roles;
+
+DROP TABLE permissions;
+
+DROP TABLE roles;
@@ -0,0 +1,25 @@
+CREATE TABLE roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    name VARCHAR(64) UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE permissions (
+    role_id UUID REFERENCES roles (id) ON DELETE CASCADE NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    PRIMARY KEY (role_id, name)
+);
+
+CREATE TABLE user_roles (
+    user_id UUID REFERENCES users (id) NOT NULL,
+    role_id UUID REFERENCES roles (id) ON DELETE CASCADE NOT NULL,
+    assigned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role_id)
+);
+
+WITH admin AS (
+    INSERT INTO roles (name, description) VALUES ('admin', 'Manages users and roles') RETURNING id
+)
+INSERT INTO permissions (role_id, name)
+SELECT admin.id, p.name FROM admin, (VALUES ('users:read'), ('users:write'), ('roles:read'), ('roles:write')) AS p (name);
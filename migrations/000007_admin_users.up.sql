@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW();
+ALTER TABLE users ADD COLUMN disabled BOOL NOT NULL DEFAULT FALSE;
+
+CREATE INDEX users_created_at_idx ON users (created_at, id);
+
+CREATE TABLE admin_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    actor_id UUID REFERENCES users (id) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    target_user_id UUID REFERENCES users (id) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
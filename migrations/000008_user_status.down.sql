@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN disabled BOOL NOT NULL DEFAULT FALSE;
+
+UPDATE users SET disabled = TRUE WHERE status <> 'active';
+
+ALTER TABLE users DROP COLUMN status_until;
+ALTER TABLE users DROP COLUMN status_reason;
+ALTER TABLE users DROP COLUMN status;
@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD COLUMN status_reason TEXT;
+ALTER TABLE users ADD COLUMN status_until TIMESTAMP;
+
+UPDATE users SET status = 'disabled' WHERE disabled;
+
+ALTER TABLE users DROP COLUMN disabled;
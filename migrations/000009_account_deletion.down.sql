@@ -0,0 +1,7 @@
+DROP INDEX users_email_key;
+
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+DROP INDEX users_deletion_scheduled_at_idx;
+
+ALTER TABLE users DROP COLUMN deletion_scheduled_at;
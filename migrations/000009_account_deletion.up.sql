@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP;
+
+CREATE INDEX users_deletion_scheduled_at_idx ON users (deletion_scheduled_at) WHERE deleted_at IS NULL;
+
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+
+CREATE UNIQUE INDEX users_email_key ON users (email) WHERE deleted_at IS NULL;
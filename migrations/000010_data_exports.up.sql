@@ -0,0 +1,12 @@
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users (id) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    data JSONB,
+    requested_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX data_exports_user_id_idx ON data_exports (user_id, requested_at);
+CREATE INDEX data_exports_pending_idx ON data_exports (requested_at) WHERE status = 'pending';
@@ -0,0 +1,6 @@
+CREATE TABLE email_changes (
+    user_id UUID PRIMARY KEY REFERENCES users (id),
+    new_email VARCHAR(50) NOT NULL,
+    token VARCHAR NOT NULL UNIQUE,
+    expired_at TIMESTAMP NOT NULL
+);
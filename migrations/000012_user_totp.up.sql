@@ -0,0 +1,7 @@
+CREATE TABLE user_totp (
+    user_id UUID PRIMARY KEY REFERENCES users (id),
+    secret BYTEA NOT NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    activated_at TIMESTAMP
+);
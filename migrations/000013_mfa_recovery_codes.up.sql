@@ -0,0 +1,7 @@
+CREATE TABLE mfa_recovery_codes (
+    user_id UUID NOT NULL REFERENCES users (id),
+    code_hash VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    PRIMARY KEY (user_id, code_hash)
+);
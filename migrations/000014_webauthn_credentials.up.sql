@@ -0,0 +1,12 @@
+CREATE TABLE webauthn_credentials (
+    id BYTEA PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id),
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    transports TEXT[] NOT NULL DEFAULT '{}',
+    name VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX webauthn_credentials_user_id_idx ON webauthn_credentials (user_id);
@@ -0,0 +1,8 @@
+CREATE TABLE referrals (
+    referee_id UUID PRIMARY KEY REFERENCES users (id),
+    referrer_id UUID NOT NULL REFERENCES users (id),
+    code VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX referrals_referrer_id_idx ON referrals (referrer_id);
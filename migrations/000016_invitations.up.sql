@@ -0,0 +1,11 @@
+CREATE TABLE invitations (
+    id UUID PRIMARY KEY,
+    inviter_id UUID NOT NULL REFERENCES users (id),
+    email VARCHAR(50) UNIQUE NOT NULL,
+    role VARCHAR(64) REFERENCES roles (name) ON DELETE CASCADE,
+    token VARCHAR NOT NULL UNIQUE,
+    expired_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX invitations_inviter_id_idx ON invitations (inviter_id);
@@ -53,7 +53,7 @@ func (m TransactionManager) WithTransaction(ctx context.Context, f func(context.
 		return f(ctx)
 	}
 
-	if err := retry.Do(func() error {
+	if err := withRetry(func() error {
 		tx, err := m.db.BeginTx(ctx, txOptions)
 		if err != nil {
 			return err
@@ -73,13 +73,20 @@ func (m TransactionManager) WithTransaction(ctx context.Context, f func(context.
 		}
 
 		return nil
-	}, retry.Attempts(retries), retry.RetryIf(isSerializationError)); err != nil {
+	}); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// withRetry retries f on serialization failures. It returns the error of the
+// last attempt as is, not a list of the errors of all attempts, so that the
+// caller can match it with errors.Is.
+func withRetry(f func() error) error {
+	return retry.Do(f, retry.Attempts(retries), retry.RetryIf(isSerializationError), retry.LastErrorOnly(true))
+}
+
 func (m TransactionManager) TxOrDB(ctx context.Context) Transaction {
 	tx, ok := ctx.Value(txKeyValue).(Transaction)
 	if !ok {
@@ -0,0 +1,74 @@
+package trm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetry(t *testing.T) {
+	errNotFound := errors.New("not found")
+	errSerialization := &pgconn.PgError{Code: pgerrcode.SerializationFailure}
+
+	tests := []struct {
+		name     string
+		errs     []error
+		attempts int
+		want     error
+	}{
+		{
+			name:     "success",
+			errs:     []error{nil},
+			attempts: 1,
+		},
+		{
+			name:     "error is not retried",
+			errs:     []error{fmt.Errorf("get user: %w", errNotFound)},
+			attempts: 1,
+			want:     errNotFound,
+		},
+		{
+			name:     "serialization failure is retried",
+			errs:     []error{errSerialization, nil},
+			attempts: 2,
+		},
+		{
+			name:     "error after serialization failure",
+			errs:     []error{errSerialization, fmt.Errorf("get user: %w", errNotFound)},
+			attempts: 2,
+			want:     errNotFound,
+		},
+		{
+			name:     "serialization failures",
+			errs:     []error{errSerialization, errSerialization, errSerialization},
+			attempts: retries,
+			want:     errSerialization,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int
+
+			err := withRetry(func() error {
+				err := tt.errs[attempts]
+				attempts++
+
+				return err
+			})
+
+			require.Equal(t, tt.attempts, attempts)
+
+			if tt.want == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
@@ -204,3 +204,33 @@ func TestResfreshWithInvalidToken(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, statusCode)
 	})
 }
+
+func TestRegisterWithDeniedEmailDomain(t *testing.T) {
+	if err := SetUp(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("register with disposable domain should return status forbidden", func(t *testing.T) {
+		request := RegisterRequest{
+			Email:    "test.email@mailinator.com",
+			Password: "test-password",
+		}
+
+		response, statusCode := authServiceClient.Register(request)
+
+		require.Nil(t, response)
+		assert.Equal(t, http.StatusForbidden, statusCode)
+	})
+
+	t.Run("register with subdomain of disposable domain should return status forbidden", func(t *testing.T) {
+		request := RegisterRequest{
+			Email:    "test.email@inbox.mailinator.com",
+			Password: "test-password",
+		}
+
+		response, statusCode := authServiceClient.Register(request)
+
+		require.Nil(t, response)
+		assert.Equal(t, http.StatusForbidden, statusCode)
+	})
+}